@@ -78,6 +78,13 @@ type IO struct {
 	buf.WriteString(schema.Generate(proc.IOSchema))
 	buf.WriteString("}\n\n")
 
+	// '/proc/meminfo'
+	buf.WriteString(`// Meminfo is '/proc/meminfo' in Linux.
+type Meminfo struct {
+`)
+	buf.WriteString(schema.Generate(proc.MeminfoSchema))
+	buf.WriteString("}\n\n")
+
 	// '/proc/$PID/stat'
 	buf.WriteString(`// Stat is '/proc/$PID/stat' in Linux.
 type Stat struct {
@@ -36,6 +36,11 @@ func main() {
 type Row struct {
 `)
 	buf.WriteString(schema.Generate(top.RowSchema))
+	buf.WriteString(`
+	// OwnerPID is the process ID (thread group ID) of the thread,
+	// only set in thread mode ('Config.ThreadMode') where PID is the thread ID.
+	OwnerPID int64 ` + "`column:\"owner_pid\"`" + `
+`)
 	buf.WriteString("}\n\n")
 
 	txt := buf.String()
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gyuho/linux-inspect/inspect"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+type fdFlags struct {
+	limit int
+
+	program string
+	pid     int64
+}
+
+var (
+	fdCommand = &cobra.Command{
+		Use:   "fd",
+		Short: "Inspects '/proc/$PID/fd', '/proc/$PID/fdinfo' like 'lsof'",
+		RunE:  fdCommandFunc,
+	}
+	fdCmdFlag fdFlags
+)
+
+func init() {
+	fdCommand.PersistentFlags().IntVarP(&fdCmdFlag.limit, "limit", "l", -1, "Limit the number results to return.")
+
+	fdCommand.PersistentFlags().StringVarP(&fdCmdFlag.program, "program", "s", "", "Specify the program name.")
+	fdCommand.PersistentFlags().Int64VarP(&fdCmdFlag.pid, "pid", "p", -1, "Specify the PID.")
+}
+
+func fdCommandFunc(cmd *cobra.Command, args []string) error {
+	color.Set(color.FgMagenta)
+	fmt.Fprintf(os.Stdout, "\n'fd' to inspect '/proc/$PID/fd', '/proc/$PID/fdinfo'\n\n")
+	color.Unset()
+
+	fds, err := inspect.GetFDs(
+		inspect.WithProgram(fdCmdFlag.program),
+		inspect.WithPID(fdCmdFlag.pid),
+		inspect.WithTopLimit(fdCmdFlag.limit),
+	)
+	if perr, ok := err.(*inspect.PartialError); ok {
+		fmt.Fprintf(os.Stderr, "some processes could not be read: %v\n", perr)
+	} else if err != nil {
+		return err
+	}
+	hd, rows := inspect.ConvertFDs(fds...)
+	txt := inspect.StringFDs(hd, rows, -1, colorOption())
+	fmt.Print(txt)
+
+	color.Set(color.FgGreen)
+	fmt.Fprintf(os.Stdout, "\nDONE!\n")
+	color.Unset()
+
+	return nil
+}
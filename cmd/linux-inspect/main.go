@@ -15,6 +15,8 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/gyuho/linux-inspect/inspect"
+
 	"github.com/spf13/cobra"
 )
 
@@ -24,10 +26,14 @@ var (
 		Short:      "linux-inspect inspects Linux processes, sockets (ps, ss, netstat).",
 		SuggestFor: []string{"linux-inspects", "linuxinspect", "linux-inspec"},
 	}
+	colorFlag string
 )
 
 func init() {
+	command.PersistentFlags().StringVar(&colorFlag, "color", "auto", "Color the tables ('auto', 'always' or 'never').")
+
 	command.AddCommand(dsCommand)
+	command.AddCommand(fdCommand)
 	command.AddCommand(nsCommand)
 	command.AddCommand(psCommand)
 	command.AddCommand(ssCommand)
@@ -37,6 +43,18 @@ func init() {
 	cobra.EnablePrefixMatching = true
 }
 
+// colorOption returns the table color option of the '--color' flag.
+func colorOption() inspect.ColumnOption {
+	switch colorFlag {
+	case "always":
+		return inspect.WithColor(true)
+	case "never":
+		return inspect.WithColor(false)
+	default:
+		return inspect.WithColorAuto(os.Stdout)
+	}
+}
+
 func main() {
 	if err := command.Execute(); err != nil {
 		fmt.Fprintln(os.Stdout, err)
@@ -17,6 +17,7 @@ type psFlags struct {
 
 	program string
 	pid     int64
+	user    string
 }
 
 var (
@@ -34,6 +35,7 @@ func init() {
 
 	psCommand.PersistentFlags().StringVarP(&psCmdFlag.program, "program", "s", "", "Specify the program name.")
 	psCommand.PersistentFlags().Int64VarP(&psCmdFlag.pid, "pid", "p", -1, "Specify the PID.")
+	psCommand.PersistentFlags().StringVarP(&psCmdFlag.user, "user", "u", "", "Specify the effective user name.")
 }
 
 func psCommandFunc(cmd *cobra.Command, args []string) error {
@@ -44,17 +46,23 @@ func psCommandFunc(cmd *cobra.Command, args []string) error {
 	if psCmdFlag.topExecPath == "" {
 		psCmdFlag.topExecPath = top.DefaultExecPath
 	}
-	pss, err := inspect.GetPS(
+	opts := []inspect.OpFunc{
 		inspect.WithProgram(psCmdFlag.program),
 		inspect.WithPID(psCmdFlag.pid),
 		inspect.WithTopExecPath(psCmdFlag.topExecPath),
 		inspect.WithTopLimit(psCmdFlag.limit),
-	)
-	if err != nil {
+	}
+	if psCmdFlag.user != "" {
+		opts = append(opts, inspect.WithUsername(psCmdFlag.user))
+	}
+	pss, err := inspect.GetPS(opts...)
+	if perr, ok := err.(*inspect.PartialError); ok {
+		fmt.Fprintf(os.Stderr, "some processes could not be read: %v\n", perr)
+	} else if err != nil {
 		return err
 	}
 	hd, rows := inspect.ConvertPS(pss...)
-	txt := inspect.StringPS(hd, rows, -1)
+	txt := inspect.StringPS(hd, rows, -1, colorOption())
 	fmt.Print(txt)
 
 	color.Set(color.FgGreen)
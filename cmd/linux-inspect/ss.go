@@ -56,11 +56,13 @@ func ssCommandFunc(cmd *cobra.Command, args []string) error {
 		inspect.WithProgram(ssCmdFlag.program),
 		inspect.WithLocalPort(ssCmdFlag.localPort),
 	)
-	if err != nil {
+	if perr, ok := err.(*inspect.PartialError); ok {
+		fmt.Fprintf(os.Stderr, "some processes could not be read: %v\n", perr)
+	} else if err != nil {
 		return err
 	}
 	hd, rows := inspect.ConvertSS(sss...)
-	txt := inspect.StringSS(hd, rows, -1)
+	txt := inspect.StringSS(hd, rows, -1, colorOption())
 	fmt.Print(txt)
 
 	color.Set(color.FgGreen)
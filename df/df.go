@@ -16,23 +16,27 @@ import (
 )
 
 // Get returns entries in 'df' command.
-// Pass '' target to list all information.
-func Get(dfPath string, target string) ([]Row, error) {
+// Pass '' target to list all information, where 'PseudoFileSystemTypes'
+// are excluded by default (see 'WithExcludeFileSystemTypes').
+func Get(dfPath string, target string, opts ...OpFunc) ([]Row, error) {
 	o, err := Read(dfPath, target)
 	if err != nil {
 		return nil, err
 	}
-	return Parse(o)
-}
-
-// GetDefault returns entries in 'df' command.
-// Pass '' target to list all information.
-func GetDefault(target string) ([]Row, error) {
-	o, err := Read(dfPath, target)
+	rows, err := Parse(o)
 	if err != nil {
 		return nil, err
 	}
-	return Parse(o)
+	op := &Op{}
+	op.applyOpts(target, opts)
+	return op.filter(rows), nil
+}
+
+// GetDefault returns entries in 'df' command.
+// Pass '' target to list all information, where 'PseudoFileSystemTypes'
+// are excluded by default (see 'WithExcludeFileSystemTypes').
+func GetDefault(target string, opts ...OpFunc) ([]Row, error) {
+	return Get(dfPath, target, opts...)
 }
 
 // dfPath is the default 'df' command path.
@@ -60,10 +64,11 @@ func read(dfPath string, target string, w io.Writer) error {
 	if !fileutil.Exist(dfPath) {
 		return fmt.Errorf("%q does not exist", dfPath)
 	}
+	flags := append([]string{}, dfFlags...)
 	if target != "" {
-		dfFlags = append(dfFlags, strings.TrimSpace(target))
+		flags = append(flags, strings.TrimSpace(target))
 	}
-	cmd := exec.Command(dfPath, dfFlags...)
+	cmd := exec.Command(dfPath, flags...)
 	cmd.Stdout = w
 	cmd.Stderr = w
 	return cmd.Run()
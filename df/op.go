@@ -0,0 +1,92 @@
+package df
+
+// PseudoFileSystemTypes is the default set of virtual file system types
+// excluded when listing all mounts, since they do not represent
+// real storage.
+var PseudoFileSystemTypes = []string{
+	"autofs",
+	"binfmt_misc",
+	"bpf",
+	"cgroup",
+	"cgroup2",
+	"configfs",
+	"debugfs",
+	"devpts",
+	"devtmpfs",
+	"fusectl",
+	"hugetlbfs",
+	"mqueue",
+	"nsfs",
+	"overlay",
+	"proc",
+	"pstore",
+	"securityfs",
+	"squashfs",
+	"sysfs",
+	"tmpfs",
+	"tracefs",
+}
+
+// Op represents an option for 'df' rows.
+type Op struct {
+	includeTypes map[string]struct{}
+
+	excludeTypes   map[string]struct{}
+	excludeTypeSet bool
+}
+
+// OpFunc applies each option.
+type OpFunc func(*Op)
+
+// WithFileSystemTypes only returns rows of the given file system types
+// (e.g. "ext4", "xfs"). The default exclusion is not applied.
+func WithFileSystemTypes(types ...string) OpFunc {
+	return func(op *Op) { op.includeTypes = toTypeSet(types) }
+}
+
+// WithExcludeFileSystemTypes excludes rows of the given file system types,
+// replacing 'PseudoFileSystemTypes'. Pass no type to list all rows.
+func WithExcludeFileSystemTypes(types ...string) OpFunc {
+	return func(op *Op) {
+		op.excludeTypes = toTypeSet(types)
+		op.excludeTypeSet = true
+	}
+}
+
+// applyOpts excludes 'PseudoFileSystemTypes' by default,
+// only when listing all mounts (empty target).
+func (op *Op) applyOpts(target string, opts []OpFunc) {
+	for _, of := range opts {
+		of(op)
+	}
+	if !op.excludeTypeSet && op.includeTypes == nil && target == "" {
+		op.excludeTypes = toTypeSet(PseudoFileSystemTypes)
+	}
+}
+
+func (op *Op) filter(rows []Row) []Row {
+	if op.includeTypes == nil && len(op.excludeTypes) == 0 {
+		return rows
+	}
+	rs := make([]Row, 0, len(rows))
+	for _, row := range rows {
+		if op.includeTypes != nil {
+			if _, ok := op.includeTypes[row.FileSystemType]; !ok {
+				continue
+			}
+		}
+		if _, ok := op.excludeTypes[row.FileSystemType]; ok {
+			continue
+		}
+		rs = append(rs, row)
+	}
+	return rs
+}
+
+func toTypeSet(types []string) map[string]struct{} {
+	m := make(map[string]struct{}, len(types))
+	for _, tp := range types {
+		m[tp] = struct{}{}
+	}
+	return m
+}
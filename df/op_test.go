@@ -0,0 +1,38 @@
+package df
+
+import (
+	"testing"
+)
+
+func TestOpFilter(t *testing.T) {
+	rows := []Row{
+		{MountedOn: "/", FileSystemType: "ext4"},
+		{MountedOn: "/data", FileSystemType: "xfs"},
+		{MountedOn: "/dev/shm", FileSystemType: "tmpfs"},
+		{MountedOn: "/proc", FileSystemType: "proc"},
+	}
+	tests := []struct {
+		target string
+		opts   []OpFunc
+		mounts []string
+	}{
+		{"", nil, []string{"/", "/data"}},
+		{"/dev/shm", nil, []string{"/", "/data", "/dev/shm", "/proc"}},
+		{"", []OpFunc{WithExcludeFileSystemTypes()}, []string{"/", "/data", "/dev/shm", "/proc"}},
+		{"", []OpFunc{WithExcludeFileSystemTypes("xfs")}, []string{"/", "/dev/shm", "/proc"}},
+		{"", []OpFunc{WithFileSystemTypes("ext4", "tmpfs")}, []string{"/", "/dev/shm"}},
+	}
+	for i, tt := range tests {
+		op := &Op{}
+		op.applyOpts(tt.target, tt.opts)
+		rs := op.filter(rows)
+		if len(rs) != len(tt.mounts) {
+			t.Fatalf("#%d: expected %v, got %+v", i, tt.mounts, rs)
+		}
+		for j := range rs {
+			if rs[j].MountedOn != tt.mounts[j] {
+				t.Fatalf("#%d: expected %v, got %+v", i, tt.mounts, rs)
+			}
+		}
+	}
+}
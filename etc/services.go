@@ -0,0 +1,69 @@
+package etc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gyuho/linux-inspect/pkg/fileutil"
+)
+
+const servicesPath = "/etc/services"
+
+// Service is an entry in '/etc/services'.
+type Service struct {
+	Name string
+	Port int64
+	// Protocol is the transport protocol (e.g. "tcp", "udp", "sctp").
+	Protocol string
+	Aliases  []string
+}
+
+// GetServices returns '/etc/services' information.
+func GetServices() ([]Service, error) {
+	if !fileutil.Exist(servicesPath) {
+		return nil, fmt.Errorf("%q does not exist", servicesPath)
+	}
+	f, err := fileutil.OpenToRead(servicesPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseServices(f)
+}
+
+func parseServices(r io.Reader) ([]Service, error) {
+	var ss []Service
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		txt := scanner.Text()
+		if i := strings.Index(txt, "#"); i >= 0 {
+			txt = txt[:i]
+		}
+		fs := strings.Fields(txt)
+		if len(fs) == 0 {
+			continue
+		}
+		if len(fs) < 2 {
+			return nil, fmt.Errorf("not enough columns at %v", fs)
+		}
+		pp := strings.SplitN(fs[1], "/", 2)
+		if len(pp) != 2 {
+			return nil, fmt.Errorf("cannot parse port/protocol %q", fs[1])
+		}
+		port, err := strconv.ParseInt(pp[0], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ss = append(ss, Service{
+			Name:     fs[0],
+			Port:     port,
+			Protocol: pp[1],
+			Aliases:  fs[2:],
+		})
+	}
+	return ss, scanner.Err()
+}
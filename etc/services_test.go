@@ -0,0 +1,40 @@
+package etc
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseServices(t *testing.T) {
+	ss, err := parseServices(strings.NewReader(`# Network services, Internet style
+tcpmux		1/tcp				# TCP port service multiplexer
+
+ssh		22/tcp				# SSH Remote Login Protocol
+http		80/tcp		www		# WorldWideWeb HTTP
+https		443/udp				# HTTP/3
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss) != 4 {
+		t.Fatalf("expected 4 services, got %d", len(ss))
+	}
+	if ss[1].Name != "ssh" || ss[1].Port != 22 || ss[1].Protocol != "tcp" || len(ss[1].Aliases) != 0 {
+		t.Fatalf("unexpected %+v", ss[1])
+	}
+	if ss[2].Name != "http" || len(ss[2].Aliases) != 1 || ss[2].Aliases[0] != "www" {
+		t.Fatalf("unexpected %+v", ss[2])
+	}
+	if ss[3].Protocol != "udp" {
+		t.Fatalf("unexpected %+v", ss[3])
+	}
+}
+
+func TestGetServices(t *testing.T) {
+	ss, err := GetServices()
+	if err != nil {
+		t.Skip(err)
+	}
+	fmt.Println("services:", len(ss))
+}
@@ -0,0 +1,64 @@
+package inspect
+
+import (
+	"github.com/gyuho/linux-inspect/proc"
+)
+
+// CgroupReport is the resource and socket usage of all processes
+// in a cgroup, so that a systemd service or a container can be
+// monitored as a unit.
+type CgroupReport struct {
+	CgroupPath string
+	PIDs       []int64
+
+	// Stats is '/proc/$PID/stat' of the member PIDs,
+	// excluding processes that exited during the scan.
+	Stats   map[int64]proc.Stat
+	Sockets []SSEntry
+
+	// UtimeTicks and StimeTicks are the sum of user and system
+	// CPU time in clock ticks across all member processes.
+	UtimeTicks uint64
+	StimeTicks uint64
+	// RssPages is the sum of resident set size in pages.
+	RssPages int64
+}
+
+// GetCgroupReport reports the processes and sockets of the cgroup.
+// See 'proc.GetCgroupPIDs' for the cgroup path format. The report is
+// returned with '*PartialError' if some sockets could not be read.
+func GetCgroupReport(cgroupPath string) (CgroupReport, error) {
+	pids, err := proc.GetCgroupPIDs(cgroupPath)
+	if err != nil {
+		return CgroupReport{}, err
+	}
+	rp := CgroupReport{CgroupPath: cgroupPath}
+	if len(pids) == 0 {
+		return rp, nil
+	}
+
+	rp.Stats, err = proc.GetStats(pids...)
+	if err != nil {
+		return CgroupReport{}, err
+	}
+	for _, pid := range pids {
+		st, ok := rp.Stats[pid]
+		if !ok {
+			// left the cgroup, or exited
+			continue
+		}
+		rp.PIDs = append(rp.PIDs, pid)
+		rp.UtimeTicks += st.Utime
+		rp.StimeTicks += st.Stime
+		rp.RssPages += st.Rss
+	}
+	if len(rp.PIDs) == 0 {
+		return rp, nil
+	}
+
+	rp.Sockets, err = GetSS(WithPIDs(rp.PIDs...))
+	if err != nil && !isPartialError(err) {
+		return CgroupReport{}, err
+	}
+	return rp, err
+}
@@ -0,0 +1,18 @@
+package inspect
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGetCgroupReport(t *testing.T) {
+	rp, err := GetCgroupReport("/")
+	if err != nil {
+		t.Skip(err)
+	}
+	if len(rp.Stats) < len(rp.PIDs) {
+		t.Fatalf("expected stats for all %d PIDs, got %d", len(rp.PIDs), len(rp.Stats))
+	}
+	fmt.Printf("cgroup %q: %d PIDs, %d sockets, utime %d, stime %d, rss %d pages\n",
+		rp.CgroupPath, len(rp.PIDs), len(rp.Sockets), rp.UtimeTicks, rp.StimeTicks, rp.RssPages)
+}
@@ -0,0 +1,79 @@
+package inspect
+
+import (
+	"time"
+
+	"github.com/gyuho/linux-inspect/proc"
+)
+
+// CPUUtil is the CPU utilization of a core over a sampling interval,
+// in percentage of the core's total time.
+type CPUUtil struct {
+	// CPU is the core name (e.g. "cpu0").
+	CPU string
+
+	// User includes nice time.
+	User float64
+	// System includes irq and softirq time.
+	System float64
+	Idle   float64
+	Iowait float64
+}
+
+// PerCPUUtilization samples '/proc/stat' twice, 'interval' apart,
+// and returns the utilization of each core. This shows imbalance
+// (e.g. one core pegged by a single-threaded bottleneck or IRQ)
+// that the aggregate usage hides. The interval is waited on 'WithClock'.
+func PerCPUUtilization(interval time.Duration, opts ...OpFunc) ([]CPUUtil, error) {
+	op := &EntryOp{}
+	op.applyOpts(opts)
+
+	cs1, err := proc.GetCPUStats()
+	if err != nil {
+		return nil, err
+	}
+	<-op.Clock.After(interval)
+	cs2, err := proc.GetCPUStats()
+	if err != nil {
+		return nil, err
+	}
+	return cpuUtilDelta(cs1, cs2), nil
+}
+
+// cpuUtilDelta computes per-core utilization between two samples,
+// skipping the aggregate "cpu" line and cores not in both samples
+// (e.g. hotplugged).
+func cpuUtilDelta(cs1, cs2 []proc.CPUStat) []CPUUtil {
+	prev := make(map[string]proc.CPUStat, len(cs1))
+	for _, c := range cs1 {
+		prev[c.CPU] = c
+	}
+
+	us := make([]CPUUtil, 0, len(cs2))
+	for _, c2 := range cs2 {
+		if c2.CPU == "cpu" {
+			continue
+		}
+		c1, ok := prev[c2.CPU]
+		if !ok {
+			continue
+		}
+		u := CPUUtil{CPU: c2.CPU}
+		if c2.Total() > c1.Total() {
+			total := float64(c2.Total() - c1.Total())
+			u.User = tickPercent(c1.User+c1.Nice, c2.User+c2.Nice, total)
+			u.System = tickPercent(c1.System+c1.Irq+c1.Softirq, c2.System+c2.Irq+c2.Softirq, total)
+			u.Idle = tickPercent(c1.Idle, c2.Idle, total)
+			u.Iowait = tickPercent(c1.Iowait, c2.Iowait, total)
+		}
+		us = append(us, u)
+	}
+	return us
+}
+
+func tickPercent(v1, v2 uint64, total float64) float64 {
+	if v2 < v1 {
+		return 0
+	}
+	return float64(v2-v1) / total * 100
+}
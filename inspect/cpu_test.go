@@ -0,0 +1,64 @@
+package inspect
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gyuho/linux-inspect/pkg/timeutil"
+	"github.com/gyuho/linux-inspect/proc"
+)
+
+func TestCPUUtilDelta(t *testing.T) {
+	cs1 := []proc.CPUStat{
+		{CPU: "cpu", User: 100, Idle: 100},
+		{CPU: "cpu0", User: 100, Idle: 0},
+		{CPU: "cpu1", User: 0, Idle: 100},
+	}
+	cs2 := []proc.CPUStat{
+		{CPU: "cpu", User: 200, Idle: 200},
+		{CPU: "cpu0", User: 190, System: 10, Idle: 0},
+		{CPU: "cpu1", User: 0, Idle: 180, Iowait: 20},
+	}
+	us := cpuUtilDelta(cs1, cs2)
+	if len(us) != 2 {
+		t.Fatalf("expected 2 cores, got %+v", us)
+	}
+	if us[0].CPU != "cpu0" || us[0].User != 90 || us[0].System != 10 {
+		t.Fatalf("unexpected cpu0 %+v", us[0])
+	}
+	if us[1].CPU != "cpu1" || us[1].Idle != 80 || us[1].Iowait != 20 {
+		t.Fatalf("unexpected cpu1 %+v", us[1])
+	}
+}
+
+func TestPerCPUUtilization(t *testing.T) {
+	us, err := PerCPUUtilization(100 * time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range us {
+		fmt.Printf("%+v\n", u)
+	}
+}
+
+func TestPerCPUUtilizationClock(t *testing.T) {
+	clock := timeutil.NewFakeClock(time.Unix(0, 0))
+	donec := make(chan error)
+	go func() {
+		_, err := PerCPUUtilization(time.Hour, WithClock(clock))
+		donec <- err
+	}()
+	for clock.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(time.Hour)
+	select {
+	case err := <-donec:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("PerCPUUtilization did not return after the clock advanced")
+	}
+}
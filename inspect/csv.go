@@ -0,0 +1,82 @@
+package inspect
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/gyuho/linux-inspect/top"
+)
+
+// WriteCSV writes the header and rows (e.g. from 'ConvertSS') as CSV,
+// with all columns, including the ones hidden in the tables.
+func WriteCSV(w io.Writer, header []string, rows [][]string) error {
+	wr := csv.NewWriter(w)
+	if err := wr.Write(header); err != nil {
+		return err
+	}
+	if err := wr.WriteAll(rows); err != nil {
+		return err
+	}
+	wr.Flush()
+	return wr.Error()
+}
+
+// SSEntriesToCSV writes the socket entries as CSV.
+func SSEntriesToCSV(w io.Writer, sss ...SSEntry) error {
+	header, rows := ConvertSS(sss...)
+	return WriteCSV(w, header, rows)
+}
+
+// PSEntriesToCSV writes the process entries as CSV.
+func PSEntriesToCSV(w io.Writer, pss ...PSEntry) error {
+	header, rows := ConvertPS(pss...)
+	return WriteCSV(w, header, rows)
+}
+
+var columnsTopRow = []string{
+	"PID",
+	"OWNER-PID",
+	"USER",
+	"PR",
+	"NI",
+	"VIRT",
+	"RES",
+	"SHR",
+	"S",
+	"CPU-PERCENT",
+	"MEM-PERCENT",
+	"TIME",
+	"COMMAND",
+
+	"VIRT-BYTES",
+	"RES-BYTES",
+	"SHR-BYTES",
+}
+
+// TopRowsToCSV writes the 'top' rows as CSV, in the given order.
+func TopRowsToCSV(w io.Writer, trs ...top.Row) error {
+	rows := make([][]string, len(trs))
+	for i, elem := range trs {
+		rows[i] = []string{
+			fmt.Sprintf("%d", elem.PID),
+			fmt.Sprintf("%d", elem.OwnerPID),
+			sanitizeUTF8(elem.USER),
+			elem.PR,
+			elem.NI,
+			elem.VIRT,
+			elem.RES,
+			elem.SHR,
+			elem.S,
+			fmt.Sprintf("%3.2f", elem.CPUPercent),
+			fmt.Sprintf("%3.2f", elem.MEMPercent),
+			elem.TIME,
+			sanitizeUTF8(elem.COMMAND),
+
+			fmt.Sprintf("%d", elem.VIRTBytesN),
+			fmt.Sprintf("%d", elem.RESBytesN),
+			fmt.Sprintf("%d", elem.SHRBytesN),
+		}
+	}
+	return WriteCSV(w, columnsTopRow, rows)
+}
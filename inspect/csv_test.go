@@ -0,0 +1,63 @@
+package inspect
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/gyuho/linux-inspect/top"
+)
+
+func TestSSEntriesToCSV(t *testing.T) {
+	buf := new(bytes.Buffer)
+	err := SSEntriesToCSV(buf, SSEntry{
+		Protocol:   "tcp",
+		Program:    "nginx, worker",
+		State:      "LISTEN",
+		PID:        123,
+		LocalIP:    "0.0.0.0",
+		LocalPort:  80,
+		RemoteIP:   "0.0.0.0",
+		RemotePort: 0,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected header and 1 row, got %d", len(rows))
+	}
+	if len(rows[0]) != len(columnsSSEntry) || rows[0][0] != "PROTOCOL" {
+		t.Fatalf("unexpected header %v", rows[0])
+	}
+	if rows[1][1] != "nginx, worker" || rows[1][3] != "123" || rows[1][5] != "80" {
+		t.Fatalf("unexpected row %v", rows[1])
+	}
+}
+
+func TestTopRowsToCSV(t *testing.T) {
+	buf := new(bytes.Buffer)
+	err := TopRowsToCSV(buf,
+		top.Row{PID: 1, USER: "root", S: "S", CPUPercent: 1.5, RESBytesN: 4096, COMMAND: "init"},
+		top.Row{PID: 2, USER: "root", S: "R", CPUPercent: 50, COMMAND: "kthreadd"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected header and 2 rows, got %d", len(rows))
+	}
+	if rows[1][0] != "1" || rows[1][9] != "1.50" || rows[1][12] != "init" || rows[1][14] != "4096" {
+		t.Fatalf("unexpected row %v", rows[1])
+	}
+	if rows[2][12] != "kthreadd" {
+		t.Fatalf("unexpected row %v", rows[2])
+	}
+}
@@ -1,13 +1,11 @@
 package inspect
 
 import (
-	"bytes"
 	"fmt"
 
 	"github.com/gyuho/linux-inspect/proc"
 
 	"github.com/gyuho/dataframe"
-	"github.com/olekukonko/tablewriter"
 )
 
 // DSEntry represents disk statistics.
@@ -98,21 +96,6 @@ func ConvertDS(dss ...DSEntry) (header []string, rows [][]string) {
 }
 
 // StringDS converts in print-friendly format.
-func StringDS(header []string, rows [][]string, topLimit int) string {
-	buf := new(bytes.Buffer)
-	tw := tablewriter.NewWriter(buf)
-	tw.SetHeader(header[:columnsDSToShow:columnsDSToShow])
-
-	if topLimit > 0 && len(rows) > topLimit {
-		rows = rows[:topLimit:topLimit]
-	}
-
-	for _, row := range rows {
-		tw.Append(row[:columnsDSToShow:columnsDSToShow])
-	}
-	tw.SetAutoFormatHeaders(false)
-	tw.SetAlignment(tablewriter.ALIGN_RIGHT)
-	tw.Render()
-
-	return buf.String()
+func StringDS(header []string, rows [][]string, topLimit int, opts ...ColumnOption) string {
+	return renderTable(header, rows, topLimit, firstColumns(columnsDSToShow), opts)
 }
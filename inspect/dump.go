@@ -0,0 +1,155 @@
+package inspect
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+
+	"github.com/gyuho/linux-inspect/proc"
+)
+
+// DefaultEnvironRedact matches environment variable keys that
+// commonly hold secrets.
+var DefaultEnvironRedact = regexp.MustCompile(`(?i)(PASS|SECRET|TOKEN|KEY|CREDENTIAL|AUTH)`)
+
+// redactedValue replaces the redacted environment variable values.
+const redactedValue = "[REDACTED]"
+
+// ProcDump is everything readable about a process, for attaching
+// to a support ticket. Sections that cannot be read (e.g. permission
+// denied) are left empty, with the reason in Errors.
+type ProcDump struct {
+	PID int64
+	// UnixNanosecond is when the dump was taken.
+	UnixNanosecond int64
+
+	Stat    *proc.Stat
+	Status  *proc.Status
+	Cmdline []string
+	Exe     string
+	Cwd     string
+	Root    string
+	// Environ is the environment at process start,
+	// with 'WithEnvironRedact' applied.
+	Environ map[string]string
+	// Limits is read from '/proc/$PID/limits', so it is available
+	// for other users' processes without CAP_SYS_RESOURCE.
+	Limits  *proc.Limits
+	FDCount int
+	// FDTypes counts the file descriptors by type
+	// (e.g. "socket", "pipe", "file", "anon_inode").
+	FDTypes    map[proc.FDType]int
+	Sockets    []SSEntry
+	Cgroups    []proc.ProcCgroup
+	Namespaces map[string]string
+	Memory     *proc.SmapsRollup
+
+	// Errors maps the section name (e.g. "environ") to its read error.
+	Errors map[string]string
+}
+
+// DumpProc reads everything about the process. It only returns an
+// error if the process does not exist; other sections degrade
+// gracefully (see 'ProcDump.Errors').
+func DumpProc(pid int64, opts ...OpFunc) (ProcDump, error) {
+	op := &EntryOp{}
+	op.applyOpts(opts)
+
+	dp := ProcDump{
+		PID:            pid,
+		UnixNanosecond: op.Clock.Now().UnixNano(),
+		Errors:         make(map[string]string),
+	}
+	fail := func(section string, err error) {
+		dp.Errors[section] = err.Error()
+	}
+
+	stat, err := proc.GetStatByPID(pid)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ProcDump{}, err
+		}
+		fail("stat", err)
+	} else {
+		dp.Stat = &stat
+	}
+
+	if status, err := proc.GetStatusByPID(pid); err != nil {
+		fail("status", err)
+	} else {
+		dp.Status = &status
+	}
+
+	if dp.Cmdline, err = proc.GetCmdlineByPID(pid); err != nil {
+		fail("cmdline", err)
+	}
+	if dp.Exe, err = proc.GetExeByPID(pid); err != nil {
+		fail("exe", err)
+	}
+	if dp.Cwd, err = proc.GetCwdByPID(pid); err != nil {
+		fail("cwd", err)
+	}
+	if dp.Root, err = proc.GetRootByPID(pid); err != nil {
+		fail("root", err)
+	}
+
+	if dp.Environ, err = proc.GetEnvironByPID(pid); err != nil {
+		fail("environ", err)
+	} else if op.EnvironRedact != nil {
+		redactEnviron(dp.Environ, op.EnvironRedact)
+	}
+
+	if limits, err := proc.GetLimitsByPID(pid); err != nil {
+		fail("limits", err)
+	} else {
+		dp.Limits = &limits
+	}
+
+	if dp.FDCount, err = proc.GetFDCountByPID(pid); err != nil {
+		fail("fds", err)
+	}
+	if fds, err := proc.GetFDsByPID(pid); err != nil {
+		fail("fd-types", err)
+	} else {
+		dp.FDTypes = make(map[proc.FDType]int)
+		for _, fd := range fds {
+			dp.FDTypes[fd.Type]++
+		}
+	}
+
+	// the options (e.g. 'WithInstrument', 'WithStrictErrors')
+	// also apply to the socket listing
+	ssOpts := append(append([]OpFunc(nil), opts...), WithPID(pid))
+	if dp.Sockets, err = GetSS(ssOpts...); err != nil {
+		fail("sockets", err)
+	}
+
+	if dp.Cgroups, err = proc.GetCgroupsByPID(pid); err != nil {
+		fail("cgroups", err)
+	}
+
+	if dp.Namespaces, err = proc.GetNamespacesByPID(pid); err != nil {
+		fail("namespaces", err)
+	}
+
+	if mem, err := proc.GetSmapsRollupByPID(pid); err != nil {
+		fail("memory", err)
+	} else {
+		dp.Memory = &mem
+	}
+
+	return dp, nil
+}
+
+// JSON encodes the dump in indented JSON.
+func (dp ProcDump) JSON() ([]byte, error) {
+	return json.MarshalIndent(dp, "", "  ")
+}
+
+func redactEnviron(env map[string]string, re *regexp.Regexp) {
+	for k := range env {
+		if re.MatchString(k) {
+			env[k] = redactedValue
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package inspect
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestDumpProc(t *testing.T) {
+	os.Setenv("LINUX_INSPECT_TEST_TOKEN", "secret")
+	defer os.Unsetenv("LINUX_INSPECT_TEST_TOKEN")
+
+	pid := int64(os.Getpid())
+	dp, err := DumpProc(pid, WithEnvironRedact(DefaultEnvironRedact))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dp.Stat == nil || dp.Stat.Pid != pid {
+		t.Fatalf("unexpected stat %+v", dp.Stat)
+	}
+	if len(dp.Cmdline) == 0 || dp.FDCount < 3 || dp.Exe == "" || dp.Root != "/" {
+		t.Fatalf("unexpected dump %+v", dp)
+	}
+	if len(dp.FDTypes) == 0 {
+		t.Fatalf("unexpected fd types %v (errors %v)", dp.FDTypes, dp.Errors)
+	}
+	if dp.Limits == nil || dp.Limits.OpenFiles.Soft == 0 {
+		t.Fatalf("unexpected limits %+v (errors %v)", dp.Limits, dp.Errors)
+	}
+	// environ is read at process start, so only check the redaction of
+	// variables set before the test binary started
+	for k, v := range dp.Environ {
+		if DefaultEnvironRedact.MatchString(k) && v != redactedValue {
+			t.Fatalf("%s expected redacted, got %q", k, v)
+		}
+	}
+	env := map[string]string{"DB_PASSWORD": "x", "HOME": "/root"}
+	redactEnviron(env, DefaultEnvironRedact)
+	if env["DB_PASSWORD"] != redactedValue || env["HOME"] != "/root" {
+		t.Fatalf("unexpected redaction %v", env)
+	}
+
+	b, err := dp.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err = json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println("DumpProc errors:", dp.Errors, "JSON bytes:", len(b))
+}
+
+func TestDumpProcNotExist(t *testing.T) {
+	if _, err := DumpProc(1 << 30); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestDumpProcOptions(t *testing.T) {
+	in := NewInstrument()
+	if _, err := DumpProc(int64(os.Getpid()), WithInstrument(in)); err != nil {
+		t.Fatal(err)
+	}
+	if st := in.Stats(); st.Calls["GetSS"].Count != 1 {
+		t.Fatalf("expected the socket section instrumented, got %+v", st.Calls)
+	}
+}
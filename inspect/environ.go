@@ -0,0 +1,24 @@
+package inspect
+
+import (
+	"github.com/gyuho/linux-inspect/proc"
+)
+
+// GetEnviron reads the environment of the process at its start, with
+// the values masked whose keys match 'WithEnvironRedact'. Unreadable
+// environ (e.g. other users' processes) returns the *os.PathError,
+// so that 'os.IsPermission' can be used to check permission errors.
+func GetEnviron(pid int64, opts ...OpFunc) (map[string]string, error) {
+	op := &EntryOp{}
+	op.applyOpts(opts)
+
+	env, err := proc.GetEnvironByPID(pid)
+	op.Instrument.procRead(err)
+	if err != nil {
+		return nil, err
+	}
+	if op.EnvironRedact != nil {
+		redactEnviron(env, op.EnvironRedact)
+	}
+	return env, nil
+}
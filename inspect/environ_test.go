@@ -0,0 +1,28 @@
+package inspect
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGetEnviron(t *testing.T) {
+	pid := int64(os.Getpid())
+	env, err := GetEnviron(pid, WithEnvironRedact(DefaultEnvironRedact))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range env {
+		if DefaultEnvironRedact.MatchString(k) && v != redactedValue {
+			t.Fatalf("%s not redacted", k)
+		}
+	}
+	if v, ok := os.LookupEnv("PATH"); ok && env["PATH"] != v {
+		t.Fatalf("PATH expected %q, got %q", v, env["PATH"])
+	}
+
+	if os.Geteuid() != 0 {
+		if _, err = GetEnviron(1); !os.IsPermission(err) {
+			t.Fatalf("expected permission error, got %v", err)
+		}
+	}
+}
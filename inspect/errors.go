@@ -0,0 +1,102 @@
+package inspect
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// PIDError is an error reading '/proc' for a process.
+type PIDError struct {
+	PID int64
+	// Op is the failed operation (e.g. "proc.GetStatByPID").
+	Op  string
+	Err error
+}
+
+func (e *PIDError) Error() string {
+	return fmt.Sprintf("%s error %v for PID %d", e.Op, e.Err, e.PID)
+}
+
+// PartialError is returned with the results when some processes
+// could not be read (e.g. permission denied), unless 'WithStrictErrors'.
+type PartialError struct {
+	Errors []*PIDError
+}
+
+func (e *PartialError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	return fmt.Sprintf("%d errors, first: %v", len(e.Errors), e.Errors[0])
+}
+
+// pidErrors collects the per-PID errors by the 'EntryOp' error mode.
+type pidErrors struct {
+	op *EntryOp
+
+	mu   sync.Mutex
+	errs []*PIDError
+}
+
+// add records the error, and returns non-nil error if the call must
+// stop (with 'WithStrictErrors'). Processes that exited during the
+// scan are not errors.
+func (pe *pidErrors) add(pid int64, op string, err error) error {
+	if err == nil || isProcessExited(err) {
+		return nil
+	}
+	e := &PIDError{PID: pid, Op: op, Err: err}
+	if pe.op.StrictErrors {
+		return e
+	}
+	pe.record(e)
+	return nil
+}
+
+// record collects the error without failing the call, even with
+// 'WithStrictErrors' (e.g. when falling back to another source).
+func (pe *pidErrors) record(e *PIDError) {
+	pe.mu.Lock()
+	pe.errs = append(pe.errs, e)
+	pe.mu.Unlock()
+}
+
+// merge collects the errors of a '*PartialError' returned by a nested
+// call, and returns any other error.
+func (pe *pidErrors) merge(err error) error {
+	perr, ok := err.(*PartialError)
+	if !ok {
+		return err
+	}
+	for _, e := range perr.Errors {
+		pe.record(e)
+	}
+	return nil
+}
+
+func isPartialError(err error) bool {
+	_, ok := err.(*PartialError)
+	return ok
+}
+
+// err returns the '*PartialError', if any.
+func (pe *pidErrors) err() error {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+	if len(pe.errs) == 0 {
+		return nil
+	}
+	return &PartialError{Errors: pe.errs}
+}
+
+func isProcessExited(err error) bool {
+	if os.IsNotExist(err) {
+		return true
+	}
+	if pe, ok := err.(*os.PathError); ok {
+		err = pe.Err
+	}
+	return err == syscall.ESRCH
+}
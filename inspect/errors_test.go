@@ -0,0 +1,91 @@
+package inspect
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/gyuho/linux-inspect/proc"
+)
+
+// checkPartialError fails the test on errors other than '*PartialError',
+// which is returned for the processes of other users when not run as root.
+func checkPartialError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil && !isPartialError(err) {
+		t.Fatal(err)
+	}
+}
+
+func TestPIDErrors(t *testing.T) {
+	exited := &os.PathError{Op: "open", Path: "/proc/1/stat", Err: syscall.ENOENT}
+	denied := &os.PathError{Op: "open", Path: "/proc/1/fd", Err: syscall.EACCES}
+
+	pe := &pidErrors{op: &EntryOp{}}
+	if err := pe.add(1, "proc.GetStatByPID", exited); err != nil {
+		t.Fatal(err)
+	}
+	if err := pe.err(); err != nil {
+		t.Fatalf("exited process must not be an error, got %v", err)
+	}
+	if err := pe.add(1, "proc.GetSocketInodesByPID", denied); err != nil {
+		t.Fatal(err)
+	}
+	perr, ok := pe.err().(*PartialError)
+	if !ok || len(perr.Errors) != 1 || perr.Errors[0].PID != 1 {
+		t.Fatalf("unexpected %v", pe.err())
+	}
+	fmt.Println(perr)
+
+	pe = &pidErrors{op: &EntryOp{StrictErrors: true}}
+	err := pe.add(2, "proc.GetSocketInodesByPID", denied)
+	var pidErr *PIDError
+	if !errors.As(err, &pidErr) || pidErr.PID != 2 {
+		t.Fatalf("unexpected %v", err)
+	}
+
+	// errors of nested calls are collected, even in strict mode
+	if err = pe.merge(perr); err != nil {
+		t.Fatal(err)
+	}
+	if perr, ok = pe.err().(*PartialError); !ok || len(perr.Errors) != 1 {
+		t.Fatalf("unexpected %v", pe.err())
+	}
+	if err = pe.merge(syscall.EINVAL); err != syscall.EINVAL {
+		t.Fatalf("unexpected %v", err)
+	}
+}
+
+func TestGetSSPartialErrorsByDefault(t *testing.T) {
+	if _, err := proc.GetSocketFDsByPID(1); err == nil {
+		t.Skip("PID 1 is readable")
+	}
+	// the read error is returned with the results, not logged
+	ss, err := GetSS(WithPIDs(1, int64(os.Getpid())))
+	perr, ok := err.(*PartialError)
+	if !ok || perr.Errors[0].PID != 1 {
+		t.Fatalf("expected partial error for PID 1, got %v", err)
+	}
+	fmt.Println("GetSS entries:", len(ss), "error:", err)
+}
+
+func TestGetSSWithPartialErrors(t *testing.T) {
+	ss, err := GetSS(WithPartialErrors())
+	if err != nil {
+		if _, ok := err.(*PartialError); !ok {
+			t.Fatal(err)
+		}
+	}
+	fmt.Println("GetSS entries:", len(ss), "error:", err)
+}
+
+func TestWithPartialAndStrictErrors(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	GetSS(WithPartialErrors(), WithStrictErrors())
+}
@@ -0,0 +1,47 @@
+package inspect
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// forEachPID calls f for each PID with a pool of n workers
+// taking PIDs from a channel. The first error cancels the
+// context passed to f and is returned.
+func forEachPID(ctx context.Context, pids []int64, n int, f func(ctx context.Context, pid int64) error) error {
+	if n < 1 {
+		n = 1
+	}
+	if n > len(pids) {
+		n = len(pids)
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	pidc := make(chan int64)
+	g.Go(func() error {
+		defer close(pidc)
+		for _, pid := range pids {
+			select {
+			case pidc <- pid:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+	for i := 0; i < n; i++ {
+		g.Go(func() error {
+			for pid := range pidc {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if err := f(ctx, pid); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
@@ -0,0 +1,75 @@
+package inspect
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestForEachPID(t *testing.T) {
+	pids := make([]int64, 200)
+	for i := range pids {
+		pids[i] = int64(i + 1)
+	}
+
+	var mu sync.Mutex
+	seen := make(map[int64]bool)
+	var inflight, maxInflight int64
+	const workers = 4
+	err := forEachPID(context.Background(), pids, workers, func(_ context.Context, pid int64) error {
+		n := atomic.AddInt64(&inflight, 1)
+		defer atomic.AddInt64(&inflight, -1)
+		mu.Lock()
+		if n > maxInflight {
+			maxInflight = n
+		}
+		seen[pid] = true
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != len(pids) {
+		t.Fatalf("expected %d PIDs, got %d", len(pids), len(seen))
+	}
+	if maxInflight > workers {
+		t.Fatalf("expected at most %d in flight, got %d", workers, maxInflight)
+	}
+}
+
+func TestForEachPIDError(t *testing.T) {
+	errExpected := errors.New("test")
+	err := forEachPID(context.Background(), []int64{1, 2, 3}, 2, func(_ context.Context, pid int64) error {
+		if pid == 2 {
+			return errExpected
+		}
+		return nil
+	})
+	if err != errExpected {
+		t.Fatalf("expected %v, got %v", errExpected, err)
+	}
+}
+
+func TestGetSSWithConcurrency(t *testing.T) {
+	_, err := GetSS(WithConcurrency(1))
+	checkPartialError(t, err)
+}
+
+func TestGetSSContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := GetSSContext(ctx); err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+	if _, err := GetUnixSocketsContext(ctx); err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+	if _, err := GetSSContext(ctx, WithPID(1)); err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,250 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gyuho/linux-inspect/proc"
+)
+
+// FDEntry is an open file descriptor of a process, like a row of 'lsof'.
+// Simplified from 'proc.FD'.
+type FDEntry struct {
+	Program string
+	PID     int64
+	User    user.User
+
+	FD   int64
+	Type proc.FDType
+	// Mode is "r", "w" or "u" (read and write).
+	Mode   string
+	Inode  uint64
+	Offset int64
+
+	// Name is the file path, or for sockets the address
+	// (e.g. "TCP 127.0.0.1:22->10.0.0.1:5555 (ESTABLISHED)") or unix
+	// socket path when found in the socket tables. Otherwise, it is
+	// the link target (e.g. "pipe:[1234]", "anon_inode:[eventfd]").
+	Name string
+}
+
+// GetFDs lists the open file descriptors of the processes, from
+// '/proc/$PID/fd' and '/proc/$PID/fdinfo'. The socket inodes are
+// resolved to addresses from the TCP and unix socket tables.
+func GetFDs(opts ...OpFunc) ([]FDEntry, error) {
+	return GetFDsContext(context.Background(), opts...)
+}
+
+// GetFDsContext is 'GetFDs' with a context. Once the context is done,
+// it stops reading '/proc' and returns the context error.
+func GetFDsContext(ctx context.Context, opts ...OpFunc) (fds []FDEntry, err error) {
+	ft := &EntryOp{}
+	ft.applyOpts(opts)
+	defer func(start time.Time) { ft.Instrument.Observe("GetFDs", start, err) }(ft.Instrument.Now())
+
+	var pids []int64
+	switch {
+	case len(ft.PIDs) > 0:
+		pids = ft.PIDs
+	case ft.PID > 0:
+		pids = []int64{ft.PID}
+	default:
+		if pids, err = proc.ListPIDsContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+	match := ft.ProgramMatchFunc
+	if match == nil {
+		match = func(string) bool { return true }
+	}
+	if ft.ExcludeSelf {
+		pids = excludePID(pids, int64(os.Getpid()))
+	}
+
+	var (
+		mu         sync.Mutex
+		socketPIDs []int64
+		users      = newUserCache(ft.Instrument)
+		errs       = &pidErrors{op: ft}
+	)
+	err = forEachPID(ctx, pids, ft.Concurrency, func(_ context.Context, pid int64) error {
+		stat, serr := proc.GetStatByPID(pid)
+		ft.Instrument.procRead(serr)
+		if serr != nil {
+			return errs.add(pid, "proc.GetStatByPID", serr)
+		}
+		if !match(stat.Comm) {
+			return nil
+		}
+		if ok, cerr := ft.matchCgroup(pid); cerr != nil {
+			return errs.add(pid, "proc.GetCgroupsByPID", cerr)
+		} else if !ok {
+			return nil
+		}
+		pfds, ferr := proc.GetFDsByPID(pid)
+		ft.Instrument.procRead(ferr)
+		if ferr != nil {
+			return errs.add(pid, "proc.GetFDsByPID", ferr)
+		}
+		var u user.User
+		if !ft.NoUserLookup {
+			uid, uerr := realUIDByPID(pid)
+			ft.Instrument.procRead(uerr)
+			if uerr != nil {
+				return errs.add(pid, "proc.GetStatusByPID", uerr)
+			}
+			mu.Lock()
+			u = users.lookupOrUID(uid)
+			mu.Unlock()
+		}
+
+		hasSocket := false
+		ents := make([]FDEntry, len(pfds))
+		for i, fd := range pfds {
+			ents[i] = FDEntry{
+				Program: stat.Comm,
+				PID:     pid,
+				User:    u,
+				FD:      fd.FD,
+				Type:    fd.Type,
+				Mode:    fd.Mode(),
+				Inode:   fd.Inode,
+				Offset:  fd.Pos,
+				Name:    fd.Target,
+			}
+			hasSocket = hasSocket || fd.Type == proc.FDTypeSocket
+		}
+
+		mu.Lock()
+		fds = append(fds, ents...)
+		if hasSocket {
+			socketPIDs = append(socketPIDs, pid)
+		}
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(socketPIDs) > 0 {
+		names, nerr := getSocketNames(ctx, socketPIDs, ft, errs)
+		if nerr != nil {
+			return nil, nerr
+		}
+		for i := range fds {
+			if name, ok := names[fds[i].Inode]; ok && fds[i].Type == proc.FDTypeSocket {
+				fds[i].Name = name
+			}
+		}
+	}
+
+	sort.Slice(fds, func(i, j int) bool {
+		if fds[i].PID != fds[j].PID {
+			return fds[i].PID < fds[j].PID
+		}
+		return fds[i].FD < fds[j].FD
+	})
+	if ft.TopLimit > 0 && len(fds) > ft.TopLimit {
+		fds = fds[:ft.TopLimit:ft.TopLimit]
+	}
+	return fds, errs.err()
+}
+
+// getSocketNames maps the socket inodes of the processes
+// to the addresses in the TCP tables and the unix socket paths.
+// The per-PID errors of the socket tables are collected in 'errs'.
+func getSocketNames(ctx context.Context, pids []int64, ft *EntryOp, errs *pidErrors) (map[uint64]string, error) {
+	names := make(map[uint64]string)
+
+	sopts := []OpFunc{WithPIDs(pids...), WithTCP(), WithTCP6(), WithoutUserLookup(), WithConcurrency(ft.Concurrency)}
+	if ft.StrictErrors {
+		sopts = append(sopts, WithStrictErrors())
+	}
+	sss, err := GetSSContext(ctx, sopts...)
+	if err = errs.merge(err); err != nil {
+		return nil, err
+	}
+	for _, ss := range sss {
+		names[ss.Inode] = socketName(ss)
+	}
+
+	nus, err := proc.GetNetUnix()
+	ft.Instrument.procRead(err)
+	if err != nil {
+		return nil, err
+	}
+	for _, nu := range nus {
+		if nu.Path != "" {
+			names[nu.Inode] = "UNIX " + nu.Path
+		}
+	}
+	return names, nil
+}
+
+// socketName formats the socket like 'lsof'
+// (e.g. "TCP 127.0.0.1:22->10.0.0.1:5555 (ESTABLISHED)").
+func socketName(ss SSEntry) string {
+	name := strings.ToUpper(ss.Protocol) + " " + net.JoinHostPort(ss.LocalIP, fmt.Sprintf("%d", ss.LocalPort))
+	if ss.State != "LISTEN" {
+		name += "->" + net.JoinHostPort(ss.RemoteIP, fmt.Sprintf("%d", ss.RemotePort))
+	}
+	return name + " (" + ss.State + ")"
+}
+
+const columnsFDsToShow = 8
+
+var columnsFDEntry = []string{
+	"PROGRAM",
+	"PID",
+	"USER",
+	"FD",
+	"TYPE",
+	"INODE",
+	"OFFSET",
+	"NAME",
+}
+
+// ConvertFDs converts to rows, sorted by program, PID and FD.
+// The FD column has the access mode like 'lsof' (e.g. "3u").
+func ConvertFDs(fds ...FDEntry) (header []string, rows [][]string) {
+	fds = append([]FDEntry(nil), fds...)
+	sort.SliceStable(fds, func(i, j int) bool {
+		if fds[i].Program != fds[j].Program {
+			return fds[i].Program < fds[j].Program
+		}
+		if fds[i].PID != fds[j].PID {
+			return fds[i].PID < fds[j].PID
+		}
+		return fds[i].FD < fds[j].FD
+	})
+
+	header = columnsFDEntry
+	rows = make([][]string, len(fds))
+	for i, elem := range fds {
+		row := make([]string, len(columnsFDEntry))
+		row[0] = sanitizeUTF8(elem.Program)
+		row[1] = fmt.Sprintf("%d", elem.PID)
+		row[2] = sanitizeUTF8(elem.User.Username)
+		row[3] = fmt.Sprintf("%d%s", elem.FD, elem.Mode)
+		row[4] = string(elem.Type)
+		row[5] = fmt.Sprintf("%d", elem.Inode)
+		row[6] = fmt.Sprintf("%d", elem.Offset)
+		row[7] = sanitizeUTF8(elem.Name)
+
+		rows[i] = row
+	}
+	return
+}
+
+// StringFDs converts in print-friendly format.
+func StringFDs(header []string, rows [][]string, topLimit int, opts ...ColumnOption) string {
+	return renderTable(header, rows, topLimit, firstColumns(columnsFDsToShow), opts)
+}
@@ -0,0 +1,62 @@
+package inspect
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/gyuho/linux-inspect/proc"
+)
+
+func TestGetFDs(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	pid := int64(os.Getpid())
+	fds, err := GetFDs(WithPID(pid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := fmt.Sprintf("TCP 127.0.0.1:%d (LISTEN)", port)
+	found := false
+	for _, elem := range fds {
+		if elem.PID != pid {
+			t.Fatalf("unexpected PID %d", elem.PID)
+		}
+		if elem.Type == proc.FDTypeSocket && elem.Name == exp {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("%q not found in %+v", exp, fds)
+	}
+
+	hd, rows := ConvertFDs(fds...)
+	fmt.Println(StringFDs(hd, rows, -1))
+}
+
+func Test_socketName(t *testing.T) {
+	ss := SSEntry{Protocol: "tcp6", State: "ESTABLISHED", LocalIP: "::1", LocalPort: 22, RemoteIP: "::1", RemotePort: 5555}
+	if s := socketName(ss); s != "TCP6 [::1]:22->[::1]:5555 (ESTABLISHED)" {
+		t.Fatalf("unexpected name %q", s)
+	}
+}
+
+func TestGetTopFDConsumers(t *testing.T) {
+	us, err := GetTopFDConsumers(3)
+	checkPartialError(t, err)
+	if len(us) == 0 || len(us) > 3 {
+		t.Fatalf("unexpected entries %+v", us)
+	}
+	for i := 1; i < len(us); i++ {
+		if us[i-1].Open < us[i].Open {
+			t.Fatalf("not sorted %+v", us)
+		}
+	}
+	fmt.Printf("%+v\n", us)
+}
@@ -0,0 +1,77 @@
+package inspect
+
+import (
+	"context"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gyuho/linux-inspect/proc"
+)
+
+// FDUsageEntry is the open file descriptor count of a process
+// against its RLIMIT_NOFILE.
+type FDUsageEntry struct {
+	Program string
+	PID     int64
+	proc.FDUsage
+}
+
+// GetTopFDConsumers returns the 'n' processes with the most open file
+// descriptors, most first; all processes if 'n' is not positive. Use
+// 'FDUsage.Percent' to alert on processes close to their soft limit.
+func GetTopFDConsumers(n int, opts ...OpFunc) ([]FDUsageEntry, error) {
+	return GetTopFDConsumersContext(context.Background(), n, opts...)
+}
+
+// GetTopFDConsumersContext is 'GetTopFDConsumers' with a context. Once
+// the context is done, it stops reading '/proc' and returns the context error.
+func GetTopFDConsumersContext(ctx context.Context, n int, opts ...OpFunc) (us []FDUsageEntry, err error) {
+	ft := &EntryOp{}
+	ft.applyOpts(opts)
+	defer func(start time.Time) { ft.Instrument.Observe("GetTopFDConsumers", start, err) }(ft.Instrument.Now())
+
+	pids, err := proc.ListPIDsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ft.ExcludeSelf {
+		pids = excludePID(pids, int64(os.Getpid()))
+	}
+
+	var (
+		mu   sync.Mutex
+		errs = &pidErrors{op: ft}
+	)
+	err = forEachPID(ctx, pids, ft.Concurrency, func(_ context.Context, pid int64) error {
+		u, uerr := proc.GetFDUsageByPID(pid)
+		ft.Instrument.procRead(uerr)
+		if uerr != nil {
+			return errs.add(pid, "proc.GetFDUsageByPID", uerr)
+		}
+		program, perr := proc.GetProgram(pid)
+		if perr != nil {
+			return errs.add(pid, "proc.GetProgram", perr)
+		}
+
+		mu.Lock()
+		us = append(us, FDUsageEntry{Program: program, PID: pid, FDUsage: u})
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(us, func(i, j int) bool {
+		if us[i].Open != us[j].Open {
+			return us[i].Open > us[j].Open
+		}
+		return us[i].PID < us[j].PID
+	})
+	if n > 0 && len(us) > n {
+		us = us[:n:n]
+	}
+	return us, errs.err()
+}
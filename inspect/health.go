@@ -0,0 +1,67 @@
+package inspect
+
+import (
+	"fmt"
+
+	"github.com/gyuho/linux-inspect/df"
+	"github.com/gyuho/linux-inspect/proc"
+)
+
+// LoadAverageExceeds returns true if the 1-minute load average
+// in '/proc/loadavg' is greater than n. It also returns the load average.
+func LoadAverageExceeds(n float64) (bool, float64, error) {
+	lvg, err := proc.GetLoadAvg()
+	if err != nil {
+		return false, 0, err
+	}
+	return lvg.LoadAvg1Minute > n, lvg.LoadAvg1Minute, nil
+}
+
+// MemoryAvailableBelow returns true if 'MemAvailable' in '/proc/meminfo'
+// is less than the given bytes. It also returns the available bytes.
+func MemoryAvailableBelow(bytes int64) (bool, int64, error) {
+	m, err := proc.GetMeminfo()
+	if err != nil {
+		return false, 0, err
+	}
+	avail := int64(m.MemAvailableBytesN)
+	return avail < bytes, avail, nil
+}
+
+// SwapUsedExceeds returns true if the used swap space in percentage
+// is greater than pct. It also returns the used percentage,
+// which is 0 when no swap is configured.
+func SwapUsedExceeds(pct float64) (bool, float64, error) {
+	m, err := proc.GetMeminfo()
+	if err != nil {
+		return false, 0, err
+	}
+	if m.SwapTotalBytesN == 0 {
+		return false, 0, nil
+	}
+	used := float64(m.SwapTotalBytesN-m.SwapFreeBytesN) / float64(m.SwapTotalBytesN) * 100
+	return used > pct, used, nil
+}
+
+// DiskUsageExceeds returns true if the used disk space in percentage
+// of the file system mounted on the given path is greater than pct.
+// It also returns the used percentage, computed the same way as 'df'
+// (used / (used + available)).
+func DiskUsageExceeds(mount string, pct float64) (bool, float64, error) {
+	rows, err := df.GetDefault(mount)
+	if err != nil {
+		return false, 0, err
+	}
+	for _, row := range rows {
+		if row.MountedOn != mount {
+			continue
+		}
+		total := row.UsedBlocks + row.AvailableBlocks
+		if total == 0 {
+			return false, 0, nil
+		}
+		used := float64(row.UsedBlocks) / float64(total) * 100
+		return used > pct, used, nil
+	}
+	return false, 0, fmt.Errorf("mount %q was not found", mount)
+}
@@ -0,0 +1,50 @@
+package inspect
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLoadAverageExceeds(t *testing.T) {
+	ok, v, err := LoadAverageExceeds(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatalf("load average %f expected to exceed -1", v)
+	}
+}
+
+func TestMemoryAvailableBelow(t *testing.T) {
+	ok, v, err := MemoryAvailableBelow(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatalf("available memory %d expected not to be below 0", v)
+	}
+	if v <= 0 {
+		t.Fatalf("expected available memory, got %d", v)
+	}
+}
+
+func TestSwapUsedExceeds(t *testing.T) {
+	ok, v, err := SwapUsedExceeds(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatalf("used swap %f%% expected not to exceed 100%%", v)
+	}
+}
+
+func TestDiskUsageExceeds(t *testing.T) {
+	ok, v, err := DiskUsageExceeds("/", 100)
+	if err != nil {
+		t.Skip(err)
+	}
+	if ok {
+		t.Fatalf("disk usage %f%% expected not to exceed 100%%", v)
+	}
+	fmt.Printf("disk usage of '/': %.2f%%\n", v)
+}
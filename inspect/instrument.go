@@ -0,0 +1,180 @@
+package inspect
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gyuho/linux-inspect/pkg/timeutil"
+)
+
+// Instrument records how long calls take and how many '/proc' reads
+// and errors occur, for diagnosing the monitoring tool itself.
+// Pass it with 'WithInstrument'. A nil *Instrument is valid and
+// records nothing, so instrumentation costs nothing when disabled.
+type Instrument struct {
+	clock timeutil.Clock
+
+	mu         sync.Mutex
+	calls      map[string]*CallStats
+	procReads  uint64
+	procErrors map[string]uint64
+
+	userLookups   uint64
+	userCacheHits uint64
+}
+
+// NewInstrument creates a new Instrument.
+func NewInstrument() *Instrument {
+	return NewInstrumentWithClock(timeutil.RealClock)
+}
+
+// NewInstrumentWithClock creates a new Instrument that times calls
+// with the clock.
+func NewInstrumentWithClock(clock timeutil.Clock) *Instrument {
+	return &Instrument{
+		clock:      clock,
+		calls:      make(map[string]*CallStats),
+		procErrors: make(map[string]uint64),
+	}
+}
+
+// Now returns the current time of the Instrument's clock, to be
+// passed to 'Observe' as the start of a call. It returns the zero
+// time for a nil *Instrument.
+func (in *Instrument) Now() time.Time {
+	if in == nil {
+		return time.Time{}
+	}
+	return in.clock.Now()
+}
+
+// LatencyBuckets are the upper bounds of call latency histogram buckets.
+// Calls slower than the last bound are counted in an extra bucket.
+var LatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// CallStats is the statistics of an instrumented call (e.g. "GetSS").
+type CallStats struct {
+	Count  uint64
+	Errors uint64
+	Total  time.Duration
+	Max    time.Duration
+	// Buckets counts calls by latency, indexed by 'LatencyBuckets'
+	// with one more bucket for slower calls.
+	Buckets []uint64
+}
+
+// InstrumentStats is a snapshot of an Instrument.
+type InstrumentStats struct {
+	Calls map[string]CallStats
+	// ProcReads is the number of '/proc' file reads.
+	ProcReads uint64
+	// ProcErrors is the number of '/proc' read errors by type
+	// ("not-exist" for exited processes, "permission", "other").
+	ProcErrors map[string]uint64
+
+	// UserLookups is the number of socket and process owner lookups
+	// by UID, and UserCacheHits is how many of them were served
+	// from the per-call cache without 'user.LookupId'.
+	UserLookups   uint64
+	UserCacheHits uint64
+}
+
+// Observe records a call that started at 'start', as returned by 'Now'.
+// It can also be used to time calls outside this package
+// (e.g. 'proc.GetStats').
+func (in *Instrument) Observe(name string, start time.Time, err error) {
+	if in == nil {
+		return
+	}
+	took := in.clock.Now().Sub(start)
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	cs, ok := in.calls[name]
+	if !ok {
+		cs = &CallStats{Buckets: make([]uint64, len(LatencyBuckets)+1)}
+		in.calls[name] = cs
+	}
+	cs.Count++
+	if err != nil {
+		cs.Errors++
+	}
+	cs.Total += took
+	if took > cs.Max {
+		cs.Max = took
+	}
+	i := 0
+	for i < len(LatencyBuckets) && took > LatencyBuckets[i] {
+		i++
+	}
+	cs.Buckets[i]++
+}
+
+// procRead records a '/proc' read and its error, if any.
+func (in *Instrument) procRead(err error) {
+	if in == nil {
+		return
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.procReads++
+	if err == nil {
+		return
+	}
+	switch {
+	case os.IsNotExist(err):
+		in.procErrors["not-exist"]++
+	case os.IsPermission(err):
+		in.procErrors["permission"]++
+	default:
+		in.procErrors["other"]++
+	}
+}
+
+// userLookup records a user lookup by UID, and whether it was cached.
+func (in *Instrument) userLookup(hit bool) {
+	if in == nil {
+		return
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.userLookups++
+	if hit {
+		in.userCacheHits++
+	}
+}
+
+// Stats returns a snapshot of the recorded statistics.
+func (in *Instrument) Stats() InstrumentStats {
+	if in == nil {
+		return InstrumentStats{}
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	st := InstrumentStats{
+		Calls:         make(map[string]CallStats, len(in.calls)),
+		ProcReads:     in.procReads,
+		ProcErrors:    make(map[string]uint64, len(in.procErrors)),
+		UserLookups:   in.userLookups,
+		UserCacheHits: in.userCacheHits,
+	}
+	for name, cs := range in.calls {
+		c := *cs
+		c.Buckets = append([]uint64(nil), cs.Buckets...)
+		st.Calls[name] = c
+	}
+	for k, v := range in.procErrors {
+		st.ProcErrors[k] = v
+	}
+	return st
+}
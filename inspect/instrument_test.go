@@ -0,0 +1,56 @@
+package inspect
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gyuho/linux-inspect/pkg/timeutil"
+)
+
+func TestInstrument(t *testing.T) {
+	clock := timeutil.NewFakeClock(time.Unix(0, 0))
+	in := NewInstrumentWithClock(clock)
+	start := in.Now()
+	clock.Advance(2 * time.Millisecond)
+	in.Observe("test", start, nil)
+	start = in.Now()
+	clock.Advance(time.Minute)
+	in.Observe("test", start, errors.New("test"))
+	in.procRead(nil)
+	in.procRead(&os.PathError{Op: "open", Path: "/proc/1/io", Err: os.ErrPermission})
+	in.procRead(&os.PathError{Op: "open", Path: "/proc/0/stat", Err: os.ErrNotExist})
+
+	st := in.Stats()
+	cs := st.Calls["test"]
+	if cs.Count != 2 || cs.Errors != 1 || cs.Max != time.Minute || cs.Total != time.Minute+2*time.Millisecond {
+		t.Fatalf("unexpected call stats %+v", cs)
+	}
+	if cs.Buckets[1] != 1 || cs.Buckets[len(LatencyBuckets)] != 1 {
+		t.Fatalf("unexpected buckets %v", cs.Buckets)
+	}
+	if st.ProcReads != 3 || st.ProcErrors["permission"] != 1 || st.ProcErrors["not-exist"] != 1 {
+		t.Fatalf("unexpected proc stats %+v", st)
+	}
+
+	// disabled
+	var nilIn *Instrument
+	nilIn.Observe("test", nilIn.Now(), nil)
+	nilIn.procRead(nil)
+	if st = nilIn.Stats(); st.ProcReads != 0 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+}
+
+func TestGetSSWithInstrument(t *testing.T) {
+	in := NewInstrument()
+	_, err := GetSS(WithInstrument(in), WithTopLimit(1))
+	checkPartialError(t, err)
+	st := in.Stats()
+	if st.Calls["GetSS"].Count != 1 {
+		t.Fatalf("expected 1 GetSS call, got %+v", st.Calls)
+	}
+	fmt.Printf("%+v\n", st)
+}
@@ -0,0 +1,38 @@
+package inspect
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestGetNetlinkSockets(t *testing.T) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW, syscall.NETLINK_ROUTE)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer syscall.Close(fd)
+	if err = syscall.Bind(fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		t.Skip(err)
+	}
+
+	pid := int64(os.Getpid())
+	nss, err := GetNetlinkSockets(WithPID(pid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, elem := range nss {
+		if elem.PID != pid {
+			t.Fatalf("unexpected PID %d", elem.PID)
+		}
+		found = found || elem.Protocol == "ROUTE"
+	}
+	if !found {
+		t.Fatalf("ROUTE socket not found in %+v", nss)
+	}
+
+	hd, rows := ConvertNetlinkSockets(nss...)
+	fmt.Println(StringNetlinkSockets(hd, rows, -1))
+}
@@ -1,13 +1,11 @@
 package inspect
 
 import (
-	"bytes"
 	"fmt"
 
 	"github.com/gyuho/linux-inspect/proc"
 
 	"github.com/gyuho/dataframe"
-	"github.com/olekukonko/tablewriter"
 )
 
 // NSEntry represents network statistics.
@@ -89,21 +87,6 @@ func ConvertNS(nss ...NSEntry) (header []string, rows [][]string) {
 }
 
 // StringNS converts in print-friendly format.
-func StringNS(header []string, rows [][]string, topLimit int) string {
-	buf := new(bytes.Buffer)
-	tw := tablewriter.NewWriter(buf)
-	tw.SetHeader(header[:columnsNSToShow:columnsNSToShow])
-
-	if topLimit > 0 && len(rows) > topLimit {
-		rows = rows[:topLimit:topLimit]
-	}
-
-	for _, row := range rows {
-		tw.Append(row[:columnsNSToShow:columnsNSToShow])
-	}
-	tw.SetAutoFormatHeaders(false)
-	tw.SetAlignment(tablewriter.ALIGN_RIGHT)
-	tw.Render()
-
-	return buf.String()
+func StringNS(header []string, rows [][]string, topLimit int, opts ...ColumnOption) string {
+	return renderTable(header, rows, topLimit, firstColumns(columnsNSToShow), opts)
 }
@@ -2,8 +2,15 @@ package inspect
 
 import (
 	"fmt"
+	"net"
+	"os/user"
+	"regexp"
+	"runtime"
+	"strconv"
 	"strings"
 
+	"github.com/gyuho/linux-inspect/pkg/timeutil"
+	"github.com/gyuho/linux-inspect/proc"
 	"github.com/gyuho/linux-inspect/top"
 )
 
@@ -13,13 +20,72 @@ type EntryOp struct {
 	program          string
 
 	PID      int64
+	PIDs     []int64
 	TopLimit int
 
+	// ExcludeSelf excludes the scanning process itself.
+	ExcludeSelf bool
+
+	// Concurrency is the number of workers reading '/proc' per call;
+	// defaults to twice the number of CPUs.
+	Concurrency int
+
+	// Clock is used for timestamps, sampling intervals and lookup
+	// timeouts; defaults to 'timeutil.RealClock'.
+	Clock timeutil.Clock
+
+	// Instrument records call timings and '/proc' reads, if not nil.
+	Instrument *Instrument
+
+	// CgroupPathPrefix matches the processes in a cgroup
+	// whose path has the prefix.
+	CgroupPathPrefix string
+
+	// PartialErrors returns '*PartialError' with the results
+	// if some processes could not be read, which is the default.
+	PartialErrors bool
+	// StrictErrors fails the call on the first process read error.
+	StrictErrors bool
+
+	// for DumpProc, GetEnviron
+	EnvironRedact *regexp.Regexp
+
 	// for ss
 	TCP        bool
 	TCP6       bool
+	SCTP       bool
+	Raw        bool
+	ICMP       bool
 	LocalPort  int64
 	RemotePort int64
+	Direction  bool
+	Netlink    bool
+	TCPInfo    bool
+	// SSLess orders 'GetSS' results before TopLimit;
+	// defaults to the 'ConvertSS' order.
+	SSLess func(a, b SSEntry) bool
+	// KernelSockets lists the sockets without a visible owner.
+	KernelSockets bool
+	// NoUserLookup leaves 'SSEntry' User empty.
+	NoUserLookup bool
+	States       []string
+	// UIDs match the socket owner UIDs, or the effective UIDs
+	// of the processes in 'GetPS', with the usernames resolved
+	// to UIDs.
+	UIDs      []uint64
+	usernames []string
+	// ResolveHostnames enables reverse DNS of local and remote IPs.
+	ResolveHostnames bool
+	// ServiceNames sets the service names of the ports,
+	// with ServiceOverrides taking precedence over '/etc/services'.
+	ServiceNames     bool
+	ServiceOverrides map[int64]string
+	LocalCIDRs       []*net.IPNet
+	// LocalPortRanges, RemotePortRanges match in addition to
+	// LocalPort, RemotePort.
+	LocalPortRanges  []PortRange
+	RemotePortRanges []PortRange
+	RemoteCIDRs      []*net.IPNet
 
 	// for ps
 	TopExecPath string
@@ -54,11 +120,71 @@ func WithPID(pid int64) OpFunc {
 	return func(op *EntryOp) { op.PID = pid }
 }
 
+// WithPIDs to filter entries by the set of PIDs.
+// It skips listing all PIDs, and only queries the given PIDs.
+// Can't be used with 'WithPID' or program match.
+func WithPIDs(pids ...int64) OpFunc {
+	return func(op *EntryOp) { op.PIDs = pids }
+}
+
 // WithTopLimit to filter entries with limit.
 func WithTopLimit(limit int) OpFunc {
 	return func(op *EntryOp) { op.TopLimit = limit }
 }
 
+// WithExcludeSelf excludes the scanning process itself (os.Getpid())
+// from the results, so that a monitor does not report its own
+// processes, sockets and file descriptors. Without it, the scanner's
+// fd counts still leave out the descriptor opened on its own
+// '/proc/$PID/fd', but not other files it has open while scanning.
+func WithExcludeSelf() OpFunc {
+	return func(op *EntryOp) { op.ExcludeSelf = true }
+}
+
+// WithCgroupPathPrefix to filter processes (and their sockets) by
+// '/proc/$PID/cgroup', matching if any of its cgroup paths has the
+// prefix (e.g. "/system.slice/nginx.service", "/docker/abc123",
+// "/kubepods"). Both cgroup v1 and v2 paths are matched.
+func WithCgroupPathPrefix(prefix string) OpFunc {
+	return func(op *EntryOp) { op.CgroupPathPrefix = prefix }
+}
+
+// WithConcurrency sets the number of workers reading '/proc' per call.
+func WithConcurrency(n int) OpFunc {
+	return func(op *EntryOp) { op.Concurrency = n }
+}
+
+// WithClock sets the clock for time-dependent results,
+// so that tests can inject a fake clock.
+func WithClock(clock timeutil.Clock) OpFunc {
+	return func(op *EntryOp) { op.Clock = clock }
+}
+
+// WithInstrument records call timings and '/proc' reads and errors.
+func WithInstrument(in *Instrument) OpFunc {
+	return func(op *EntryOp) { op.Instrument = in }
+}
+
+// WithPartialErrors makes 'GetSS' return '*PartialError' with the
+// results when some processes could not be read (e.g. permission denied).
+// It is the default, and only conflicts with 'WithStrictErrors'.
+func WithPartialErrors() OpFunc {
+	return func(op *EntryOp) { op.PartialErrors = true }
+}
+
+// WithStrictErrors makes 'GetSS' fail with '*PIDError' on the first
+// process that could not be read. Processes that exit during the
+// scan are not errors.
+func WithStrictErrors() OpFunc {
+	return func(op *EntryOp) { op.StrictErrors = true }
+}
+
+// WithEnvironRedact masks environment variable values in 'DumpProc'
+// and 'GetEnviron' whose keys match the pattern (e.g. 'DefaultEnvironRedact').
+func WithEnvironRedact(re *regexp.Regexp) OpFunc {
+	return func(op *EntryOp) { op.EnvironRedact = re }
+}
+
 // WithLocalPort to filter entries by local port.
 func WithLocalPort(port int64) OpFunc {
 	return func(op *EntryOp) { op.LocalPort = port }
@@ -69,6 +195,44 @@ func WithRemotePort(port int64) OpFunc {
 	return func(op *EntryOp) { op.RemotePort = port }
 }
 
+// PortRange is an inclusive port range.
+type PortRange struct {
+	Min int64
+	Max int64
+}
+
+func (r PortRange) contains(port int64) bool {
+	return r.Min <= port && port <= r.Max
+}
+
+// WithLocalPortRange to filter entries by local port range, inclusive.
+func WithLocalPortRange(min, max int64) OpFunc {
+	return func(op *EntryOp) { op.LocalPortRanges = append(op.LocalPortRanges, PortRange{Min: min, Max: max}) }
+}
+
+// WithRemotePortRange to filter entries by remote port range, inclusive.
+func WithRemotePortRange(min, max int64) OpFunc {
+	return func(op *EntryOp) { op.RemotePortRanges = append(op.RemotePortRanges, PortRange{Min: min, Max: max}) }
+}
+
+// WithLocalPorts to filter entries by any of the local ports.
+func WithLocalPorts(ports ...int64) OpFunc {
+	return func(op *EntryOp) {
+		for _, port := range ports {
+			op.LocalPortRanges = append(op.LocalPortRanges, PortRange{Min: port, Max: port})
+		}
+	}
+}
+
+// WithRemotePorts to filter entries by any of the remote ports.
+func WithRemotePorts(ports ...int64) OpFunc {
+	return func(op *EntryOp) {
+		for _, port := range ports {
+			op.RemotePortRanges = append(op.RemotePortRanges, PortRange{Min: port, Max: port})
+		}
+	}
+}
+
 // WithTCP to filter entries by TCP.
 // Can be used with 'WithTCP6'.
 func WithTCP() OpFunc {
@@ -81,6 +245,141 @@ func WithTCP6() OpFunc {
 	return func(op *EntryOp) { op.TCP6 = true }
 }
 
+// WithSCTP to filter entries by SCTP associations and
+// listening endpoints. SCTP is not listed by default.
+func WithSCTP() OpFunc {
+	return func(op *EntryOp) { op.SCTP = true }
+}
+
+// WithRaw to filter entries by raw sockets (IPv4 and IPv6).
+// The local port of raw sockets is the IP protocol number.
+// Raw sockets are not listed by default.
+func WithRaw() OpFunc {
+	return func(op *EntryOp) { op.Raw = true }
+}
+
+// WithICMP to filter entries by ICMP datagram ("ping") sockets
+// (IPv4 and IPv6). ICMP sockets are not listed by default.
+func WithICMP() OpFunc {
+	return func(op *EntryOp) { op.ICMP = true }
+}
+
+// WithDirection infers the connection direction of each socket entry.
+// A socket is inbound when its local port has a listener in the same
+// network namespace and protocol, found in the socket tables before
+// any filter (e.g. 'WithState("ESTABLISHED")' still finds listeners).
+func WithDirection() OpFunc {
+	return func(op *EntryOp) { op.Direction = true }
+}
+
+// WithState to filter entries by TCP states (e.g. "LISTEN", "ESTABLISHED").
+// Entries in any of the given states are returned.
+func WithState(states ...string) OpFunc {
+	return func(op *EntryOp) {
+		for _, st := range states {
+			op.States = append(op.States, strings.ToUpper(st))
+		}
+	}
+}
+
+// WithUID to filter sockets by the UID that owns them
+// (the effective UID of the creator), or processes by
+// their effective UID in 'GetPS'. Can be given multiple times,
+// or with 'WithUsername', to match any of them.
+func WithUID(uid uint64) OpFunc {
+	return func(op *EntryOp) { op.UIDs = append(op.UIDs, uid) }
+}
+
+// WithUsername is 'WithUID' with the UID of the user.
+// 'GetSS' and 'GetPS' return an error if the user does not exist.
+func WithUsername(name string) OpFunc {
+	return func(op *EntryOp) { op.usernames = append(op.usernames, name) }
+}
+
+// WithKernelSockets makes 'GetSS' include the sockets held by no
+// visible process (e.g. TIME_WAIT, orphaned, or owned by processes
+// whose '/proc/$PID/fd' is not readable), with PID -1 and program "-",
+// so that the full socket table is listed. They are only included
+// without a PID, program or cgroup filter.
+func WithKernelSockets() OpFunc {
+	return func(op *EntryOp) { op.KernelSockets = true }
+}
+
+// WithListenOnly to filter entries in LISTEN state, like 'ss -l'.
+// 'StringSS' omits the remote address columns for such entries.
+func WithListenOnly() OpFunc {
+	return WithState("LISTEN")
+}
+
+// WithLocalCIDR to filter entries by local IP subnets (e.g. "10.0.0.0/8").
+// Entries in any of the given subnets are returned.
+func WithLocalCIDR(cidrs ...string) OpFunc {
+	return func(op *EntryOp) { op.LocalCIDRs = append(op.LocalCIDRs, mustParseCIDRs(cidrs)...) }
+}
+
+// WithRemoteCIDR to filter entries by remote IP subnets (e.g. "10.0.0.0/8").
+// Entries in any of the given subnets are returned.
+func WithRemoteCIDR(cidrs ...string) OpFunc {
+	return func(op *EntryOp) { op.RemoteCIDRs = append(op.RemoteCIDRs, mustParseCIDRs(cidrs)...) }
+}
+
+func mustParseCIDRs(cidrs []string) []*net.IPNet {
+	ns := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(fmt.Errorf("not-valid CIDR %q (%v)", cidr, err))
+		}
+		ns = append(ns, n)
+	}
+	return ns
+}
+
+// WithResolveHostnames sets 'SSEntry' LocalHost and RemoteHost with
+// reverse DNS lookups. Lookups time out, and results are cached.
+func WithResolveHostnames() OpFunc {
+	return func(op *EntryOp) { op.ResolveHostnames = true }
+}
+
+// WithServiceNames sets 'SSEntry' LocalService and RemoteService from
+// '/etc/services' (e.g. 443 to "https"), so that 'ConvertSS' shows the
+// names instead of the port numbers. overrides maps ports to names,
+// taking precedence over '/etc/services' (e.g. {8080: "api"}); it can
+// be nil. Ports without a name are shown as numbers.
+func WithServiceNames(overrides map[int64]string) OpFunc {
+	return func(op *EntryOp) {
+		op.ServiceNames = true
+		op.ServiceOverrides = overrides
+	}
+}
+
+// WithNetlinkBackend makes 'GetSS' list sockets with NETLINK_SOCK_DIAG
+// instead of reading '/proc/$PID/net/tcp(6)'. Only the network namespace
+// of this process is dumped with netlink; others and failures fall back
+// to '/proc'.
+func WithNetlinkBackend() OpFunc {
+	return func(op *EntryOp) { op.Netlink = true }
+}
+
+// WithTCPInfo sets 'SSEntry' TCPInfo (e.g. RTT, congestion window,
+// retransmits) from INET_DIAG_INFO, as in 'ss -i'. It implies
+// 'WithNetlinkBackend'; TCPInfo is nil if netlink is not available.
+func WithTCPInfo() OpFunc {
+	return func(op *EntryOp) { op.Netlink, op.TCPInfo = true, true }
+}
+
+// WithSSSort sets the order of 'GetSS' results, applied to
+// all matches before 'WithTopLimit' truncates them.
+func WithSSSort(less func(a, b SSEntry) bool) OpFunc {
+	return func(op *EntryOp) { op.SSLess = less }
+}
+
+// WithoutUserLookup leaves 'SSEntry' User empty, skipping
+// the user database lookups (e.g. on LDAP-backed hosts).
+func WithoutUserLookup() OpFunc {
+	return func(op *EntryOp) { op.NoUserLookup = true }
+}
+
 // WithTopExecPath configures 'top' command path.
 func WithTopExecPath(path string) OpFunc {
 	return func(op *EntryOp) { op.TopExecPath = path }
@@ -113,22 +412,172 @@ func (op *EntryOp) applyOpts(opts []OpFunc) {
 	}
 
 	if op.DiskDevice != "" || op.NetworkInterface != "" || op.ExtraPath != "" {
-		if (op.program != "" || op.ProgramMatchFunc != nil) || op.TopLimit > 0 || op.LocalPort > 0 || op.RemotePort > 0 || op.TCP || op.TCP6 {
+		if (op.program != "" || op.ProgramMatchFunc != nil) || op.TopLimit > 0 || op.LocalPort > 0 || op.RemotePort > 0 || op.TCP || op.TCP6 || op.SCTP || op.Raw || op.ICMP {
 			panic(fmt.Errorf("not-valid Proc fileter; disk device %q or network interface %q or extra path %q", op.DiskDevice, op.NetworkInterface, op.ExtraPath))
 		}
 	}
 	if (op.program != "" || op.ProgramMatchFunc != nil) && op.PID > 0 {
 		panic(fmt.Errorf("can't filter both by program(%q or %p) and PID(%d)", op.program, op.ProgramMatchFunc, op.PID))
 	}
-	if !op.TCP && !op.TCP6 {
+	if len(op.PIDs) > 0 && (op.PID > 0 || op.program != "" || op.ProgramMatchFunc != nil) {
+		panic(fmt.Errorf("can't filter both by PIDs(%v) and PID(%d) or program(%q or %p)", op.PIDs, op.PID, op.program, op.ProgramMatchFunc))
+	}
+	if !op.TCP && !op.TCP6 && !op.SCTP && !op.Raw && !op.ICMP {
 		// choose both
 		op.TCP, op.TCP6 = true, true
 	}
 	if op.LocalPort > 0 && op.RemotePort > 0 {
 		panic(fmt.Errorf("can't query by both local(%d) and remote(%d) ports", op.LocalPort, op.RemotePort))
 	}
-
+	if op.PartialErrors && op.StrictErrors {
+		panic(fmt.Errorf("can't use both partial and strict errors"))
+	}
+	for _, r := range append(op.LocalPortRanges, op.RemotePortRanges...) {
+		if r.Min < 0 || r.Min > r.Max {
+			panic(fmt.Errorf("not-valid port range [%d, %d]", r.Min, r.Max))
+		}
+	}
+	for _, st := range op.States {
+		if _, ok := proc.TCPStateCode(st); !ok && !isSCTPState(st) {
+			panic(fmt.Errorf("unknown TCP state %q", st))
+		}
+	}
 	if op.TopExecPath == "" {
 		op.TopExecPath = top.DefaultExecPath
 	}
+	if op.Clock == nil {
+		op.Clock = timeutil.RealClock
+	}
+	if op.Concurrency < 1 {
+		op.Concurrency = 2 * runtime.NumCPU()
+	}
+}
+
+// resolveUsernames resolves the users of 'WithUsername' to 'UIDs'.
+// Unlike the option checks in 'applyOpts', a lookup can fail at
+// runtime (e.g. unknown user, NSS outage), so it returns the error.
+func (op *EntryOp) resolveUsernames() error {
+	for _, name := range op.usernames {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return fmt.Errorf("unknown user %q (%v)", name, err)
+		}
+		uid, err := strconv.ParseUint(u.Uid, 10, 64)
+		if err != nil {
+			return fmt.Errorf("not-valid UID %q of user %q (%v)", u.Uid, name, err)
+		}
+		op.UIDs = append(op.UIDs, uid)
+	}
+	op.usernames = nil
+	return nil
+}
+
+func isSCTPState(name string) bool {
+	for _, n := range proc.SCTPStates {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// matchCgroup returns true if there is no cgroup filter,
+// or the process is in a matching cgroup.
+func (op *EntryOp) matchCgroup(pid int64) (bool, error) {
+	if op.CgroupPathPrefix == "" {
+		return true, nil
+	}
+	cgs, err := proc.GetCgroupsByPID(pid)
+	op.Instrument.procRead(err)
+	if err != nil {
+		return false, err
+	}
+	for _, cg := range cgs {
+		if strings.HasPrefix(cg.Path, op.CgroupPathPrefix) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchNetTCP returns true if the socket passes the ss filters.
+func (op *EntryOp) matchNetTCP(elem proc.NetTCP) bool {
+	if len(op.UIDs) > 0 && !containsUID(op.UIDs, elem.Uid) {
+		return false
+	}
+	if !matchPort(op.LocalPort, op.LocalPortRanges, elem.LocalAddressParsedIPPort) {
+		return false
+	}
+	if !matchPort(op.RemotePort, op.RemotePortRanges, elem.RemAddressParsedIPPort) {
+		return false
+	}
+	if len(op.States) > 0 {
+		found := false
+		for _, st := range op.States {
+			if st == elem.StParsedStatus {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(op.LocalCIDRs) > 0 && !containsIP(op.LocalCIDRs, elem.LocalAddressParsedIPHost) {
+		return false
+	}
+	if len(op.RemoteCIDRs) > 0 && !containsIP(op.RemoteCIDRs, elem.RemAddressParsedIPHost) {
+		return false
+	}
+	return true
+}
+
+func containsUID(uids []uint64, uid uint64) bool {
+	for _, v := range uids {
+		if v == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPort returns true if there is no port filter, or the port
+// is the given port or in any of the ranges.
+func matchPort(port int64, ranges []PortRange, v int64) bool {
+	if port <= 0 && len(ranges) == 0 {
+		return true
+	}
+	if port > 0 && port == v {
+		return true
+	}
+	for _, r := range ranges {
+		if r.contains(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsIP(ns []*net.IPNet, host string) bool {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, n := range ns {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludePID returns PIDs without the given PID.
+func excludePID(pids []int64, pid int64) []int64 {
+	ps := make([]int64, 0, len(pids))
+	for _, p := range pids {
+		if p != pid {
+			ps = append(ps, p)
+		}
+	}
+	return ps
 }
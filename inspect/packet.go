@@ -0,0 +1,149 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/user"
+	"time"
+
+	"github.com/gyuho/linux-inspect/proc"
+
+	"github.com/gyuho/dataframe"
+)
+
+// PacketSocketEntry is an AF_PACKET socket entry, as opened by
+// packet capture tools (e.g. tcpdump, libpcap users, DHCP clients).
+// Simplified from 'proc.NetPacket'.
+type PacketSocketEntry struct {
+	Type     string
+	Protocol string
+	// Interface is the bound interface name, or "*" if the socket
+	// captures on all interfaces.
+	Interface string
+	Running   bool
+	Inode     uint64
+
+	// PID, Program, User are of the owning process; zero if no
+	// visible process holds the socket.
+	PID     int64
+	Program string
+	User    user.User
+}
+
+// GetPacketSockets lists the AF_PACKET sockets in '/proc/net/packet',
+// with the owning processes resolved from '/proc/$PID/fd'. A socket
+// shared by several processes is listed once per process.
+//
+// With a PID or program filter, only the sockets owned by the matching
+// processes are returned.
+func GetPacketSockets(opts ...OpFunc) ([]PacketSocketEntry, error) {
+	return GetPacketSocketsContext(context.Background(), opts...)
+}
+
+// GetPacketSocketsContext is 'GetPacketSockets' with a context.
+func GetPacketSocketsContext(ctx context.Context, opts ...OpFunc) (pss []PacketSocketEntry, err error) {
+	ft := &EntryOp{}
+	ft.applyOpts(opts)
+	defer func(start time.Time) { ft.Instrument.Observe("GetPacketSockets", start, err) }(ft.Instrument.Now())
+
+	pks, err := proc.GetNetPacket()
+	ft.Instrument.procRead(err)
+	if err != nil {
+		return nil, err
+	}
+	errs := &pidErrors{op: ft}
+	owners, filtered, err := getSocketOwners(ctx, ft, errs)
+	if err != nil {
+		return nil, err
+	}
+
+	ifaces := make(map[int]string)
+	for _, pk := range pks {
+		entry := PacketSocketEntry{
+			Type:      pk.Type,
+			Protocol:  pk.ProtocolName,
+			Interface: interfaceName(ifaces, pk.Ifindex),
+			Running:   pk.Running,
+			Inode:     pk.Inode,
+		}
+		ows, ok := owners[pk.Inode]
+		if !ok {
+			if !filtered {
+				pss = append(pss, entry)
+			}
+			continue
+		}
+		for _, o := range ows {
+			entry.PID, entry.Program, entry.User = o.pid, o.program, o.user
+			pss = append(pss, entry)
+		}
+	}
+
+	if ft.TopLimit > 0 && len(pss) > ft.TopLimit {
+		pss = pss[:ft.TopLimit:ft.TopLimit]
+	}
+	return pss, errs.err()
+}
+
+// interfaceName returns the name of the interface index, caching
+// lookups in 'cache'. Unknown indexes are returned as numbers.
+func interfaceName(cache map[int]string, idx int) string {
+	if idx == 0 {
+		return "*"
+	}
+	if name, ok := cache[idx]; ok {
+		return name
+	}
+	name := fmt.Sprintf("%d", idx)
+	if iface, err := net.InterfaceByIndex(idx); err == nil {
+		name = iface.Name
+	}
+	cache[idx] = name
+	return name
+}
+
+const columnsPacketSocketsToShow = 8
+
+var columnsPacketSocketEntry = []string{
+	"PROGRAM",
+	"PID",
+	"TYPE",
+	"PROTOCOL",
+	"INTERFACE",
+	"RUNNING",
+	"INODE",
+	"USER",
+}
+
+// ConvertPacketSockets converts to rows.
+func ConvertPacketSockets(pss ...PacketSocketEntry) (header []string, rows [][]string) {
+	header = columnsPacketSocketEntry
+	rows = make([][]string, len(pss))
+	for i, elem := range pss {
+		row := make([]string, len(columnsPacketSocketEntry))
+		row[0] = sanitizeUTF8(elem.Program)
+		row[1] = fmt.Sprintf("%d", elem.PID)
+		row[2] = elem.Type
+		row[3] = elem.Protocol
+		row[4] = sanitizeUTF8(elem.Interface)
+		row[5] = fmt.Sprintf("%v", elem.Running)
+		row[6] = fmt.Sprintf("%d", elem.Inode)
+		row[7] = sanitizeUTF8(elem.User.Username)
+
+		rows[i] = row
+	}
+	dataframe.SortBy(
+		rows,
+		dataframe.StringAscendingFunc(0), // Program
+		dataframe.StringAscendingFunc(1), // PID
+		dataframe.StringAscendingFunc(4), // Interface
+	).Sort(rows)
+
+	return
+}
+
+// StringPacketSockets converts in print-friendly format.
+func StringPacketSockets(header []string, rows [][]string, topLimit int, opts ...ColumnOption) string {
+	return renderTable(header, rows, topLimit, firstColumns(columnsPacketSocketsToShow), opts)
+}
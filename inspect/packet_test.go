@@ -0,0 +1,36 @@
+package inspect
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestGetPacketSockets(t *testing.T) {
+	// ETH_P_ALL in network byte order
+	fd, err := syscall.Socket(syscall.AF_PACKET, syscall.SOCK_RAW, 0x0300)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer syscall.Close(fd)
+
+	pid := int64(os.Getpid())
+	pss, err := GetPacketSockets(WithPID(pid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, elem := range pss {
+		if elem.PID != pid {
+			t.Fatalf("unexpected PID %d", elem.PID)
+		}
+		found = found || (elem.Protocol == "ALL" && elem.Interface == "*")
+	}
+	if !found {
+		t.Fatalf("packet socket not found in %+v", pss)
+	}
+
+	hd, rows := ConvertPacketSockets(pss...)
+	fmt.Println(StringPacketSockets(hd, rows, -1))
+}
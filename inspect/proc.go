@@ -3,7 +3,6 @@ package inspect
 import (
 	"fmt"
 	"io/ioutil"
-	"time"
 
 	"github.com/gyuho/linux-inspect/pkg/fileutil"
 	"github.com/gyuho/linux-inspect/proc"
@@ -79,7 +78,7 @@ func GetProc(opts ...OpFunc) (Proc, error) {
 	if op.PID == 0 {
 		return Proc{}, fmt.Errorf("unknown PID %d", op.PID)
 	}
-	ts := time.Now().UnixNano()
+	ts := op.Clock.Now().UnixNano()
 	pc := Proc{UnixNanosecond: ts, UnixSecond: nanoToUnix(ts)}
 
 	toFinish := 0
@@ -89,7 +88,7 @@ func GetProc(opts ...OpFunc) (Proc, error) {
 	go func() {
 		// get process stats
 		ets, err := GetPS(WithPID(op.PID), WithTopStream(op.TopStream))
-		if err != nil {
+		if err != nil && (!isPartialError(err) || len(ets) != 1) {
 			errc <- err
 			return
 		}
@@ -241,7 +240,7 @@ func (p *Proc) ToRow() (row []string) {
 	row[0] = fmt.Sprintf("%d", p.UnixNanosecond) // UNIX-NANOSECOND
 	row[1] = fmt.Sprintf("%d", p.UnixSecond)     // UNIX-SECOND
 
-	row[2] = p.PSEntry.Program                       // PROGRAM
+	row[2] = sanitizeUTF8(p.PSEntry.Program)         // PROGRAM
 	row[3] = p.PSEntry.State                         // STATE
 	row[4] = fmt.Sprintf("%d", p.PSEntry.PID)        // PID
 	row[5] = fmt.Sprintf("%d", p.PSEntry.PPID)       // PPID
@@ -0,0 +1,198 @@
+package inspect
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/gyuho/linux-inspect/pkg/timeutil"
+	"github.com/gyuho/linux-inspect/proc"
+)
+
+// Profiler samples CPU ticks of all live processes at a fixed interval,
+// and reports the top CPU consumers averaged over the window.
+type Profiler struct {
+	// Interval is the delay between two stat snapshots.
+	// Defaults to 1 second.
+	Interval time.Duration
+
+	// Duration is the length of the profiling window.
+	// Defaults to 1 minute.
+	Duration time.Duration
+
+	// TopN is the number of top CPU consumers to report.
+	// Defaults to 10.
+	TopN int
+
+	// Clock is used for sampling intervals and CPU rates.
+	// Defaults to 'timeutil.RealClock'.
+	Clock timeutil.Clock
+}
+
+// ProfileEntry is the CPU usage of a process over the profiling window.
+type ProfileEntry struct {
+	Program string
+	PID     int64
+
+	// CPUAvg is the average CPU usage in percentage.
+	CPUAvg float64
+	// CPUVariance is the variance of CPU usage samples.
+	// High variance means bursty, low variance means steady.
+	CPUVariance float64
+	// Samples is the number of samples taken for the process.
+	Samples int
+}
+
+// cpuAccumulator keeps running mean and variance (Welford's algorithm),
+// so that no per-sample history is kept in memory.
+type cpuAccumulator struct {
+	program   string
+	starttime uint64
+
+	n    int
+	mean float64
+	m2   float64
+}
+
+func (acc *cpuAccumulator) add(v float64) {
+	acc.n++
+	delta := v - acc.mean
+	acc.mean += delta / float64(acc.n)
+	acc.m2 += delta * (v - acc.mean)
+}
+
+// Run profiles the processes until the duration elapses, and returns the
+// top CPU consumers in descending order of average CPU usage.
+// If ctx is canceled, it returns the report so far with ctx.Err().
+//
+// Memory is bounded: at most TopN plus 'profileSlack' processes keep
+// constant-size running statistics between samples, ordered by running
+// mean. This is an approximation: a process evicted as a low CPU consumer
+// starts over when it is sampled again, so its CPUAvg and CPUVariance
+// only cover the samples since then (see 'ProfileEntry.Samples').
+func (p *Profiler) Run(ctx context.Context) ([]ProfileEntry, error) {
+	interval, duration, topN := p.Interval, p.Duration, p.TopN
+	if interval <= 0 {
+		interval = time.Second
+	}
+	if duration <= 0 {
+		duration = time.Minute
+	}
+	if topN <= 0 {
+		topN = 10
+	}
+	maxTracked := topN + profileSlack
+	clock := p.Clock
+	if clock == nil {
+		clock = timeutil.RealClock
+	}
+
+	accs := make(map[int64]*cpuAccumulator)
+	prevTicks := make(map[int64]uint64)
+	prevStart := make(map[int64]uint64)
+
+	sm, err := proc.GetStats()
+	if err != nil {
+		return nil, err
+	}
+	for pid, st := range sm {
+		prevTicks[pid] = st.Utime + st.Stime
+		prevStart[pid] = st.Starttime
+	}
+	last := clock.Now()
+
+	deadline := clock.After(duration)
+	for {
+		select {
+		case <-ctx.Done():
+			return topProfileEntries(accs, topN), ctx.Err()
+		case <-deadline:
+			return topProfileEntries(accs, topN), nil
+		case <-clock.After(interval):
+		}
+
+		sm, err = proc.GetStats()
+		if err != nil {
+			return topProfileEntries(accs, topN), err
+		}
+		now := clock.Now()
+		elapsed := now.Sub(last).Seconds()
+		last = now
+
+		curTicks := make(map[int64]uint64, len(sm))
+		curStart := make(map[int64]uint64, len(sm))
+		for pid, st := range sm {
+			ticks := st.Utime + st.Stime
+			curTicks[pid] = ticks
+			curStart[pid] = st.Starttime
+
+			pt, ok := prevTicks[pid]
+			if !ok || prevStart[pid] != st.Starttime || ticks < pt {
+				// new process, or PID reused
+				continue
+			}
+			cpu := float64(ticks-pt) / proc.UserHZ / elapsed * 100
+
+			acc, ok := accs[pid]
+			if !ok || acc.starttime != st.Starttime {
+				acc = &cpuAccumulator{program: st.Comm, starttime: st.Starttime}
+				accs[pid] = acc
+			}
+			acc.add(cpu)
+		}
+		prevTicks, prevStart = curTicks, curStart
+
+		if len(accs) > maxTracked {
+			pruneAccumulators(accs, maxTracked)
+		}
+	}
+}
+
+// profileSlack is the number of processes tracked beyond TopN, so that
+// a process ranked just below the top keeps its statistics and can
+// move up without starting over.
+const profileSlack = 32
+
+// pruneAccumulators keeps the n highest running means, live or exited,
+// and drops the rest.
+func pruneAccumulators(accs map[int64]*cpuAccumulator, n int) {
+	pids := make([]int64, 0, len(accs))
+	for pid := range accs {
+		pids = append(pids, pid)
+	}
+	sort.Slice(pids, func(i, j int) bool {
+		if accs[pids[i]].mean != accs[pids[j]].mean {
+			return accs[pids[i]].mean > accs[pids[j]].mean
+		}
+		return pids[i] < pids[j]
+	})
+	for _, pid := range pids[n:] {
+		delete(accs, pid)
+	}
+}
+
+func topProfileEntries(accs map[int64]*cpuAccumulator, n int) []ProfileEntry {
+	ps := make([]ProfileEntry, 0, len(accs))
+	for pid, acc := range accs {
+		ent := ProfileEntry{
+			Program: acc.program,
+			PID:     pid,
+			CPUAvg:  acc.mean,
+			Samples: acc.n,
+		}
+		if acc.n > 0 {
+			ent.CPUVariance = acc.m2 / float64(acc.n)
+		}
+		ps = append(ps, ent)
+	}
+	sort.Slice(ps, func(i, j int) bool {
+		if ps[i].CPUAvg != ps[j].CPUAvg {
+			return ps[i].CPUAvg > ps[j].CPUAvg
+		}
+		return ps[i].PID < ps[j].PID
+	})
+	if len(ps) > n {
+		ps = ps[:n:n]
+	}
+	return ps
+}
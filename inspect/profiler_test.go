@@ -0,0 +1,89 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gyuho/linux-inspect/pkg/timeutil"
+)
+
+func TestProfiler(t *testing.T) {
+	p := &Profiler{
+		Interval: 100 * time.Millisecond,
+		Duration: 500 * time.Millisecond,
+		TopN:     3,
+	}
+	ps, err := p.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) > 3 {
+		t.Fatalf("expected at most 3 entries, got %d", len(ps))
+	}
+	for _, ent := range ps {
+		fmt.Printf("%+v\n", ent)
+	}
+}
+
+func TestProfilerCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	p := &Profiler{Interval: 50 * time.Millisecond, Duration: time.Hour}
+	if _, err := p.Run(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestProfilerFakeClock(t *testing.T) {
+	clock := timeutil.NewFakeClock(time.Unix(0, 0))
+	p := &Profiler{
+		Interval: time.Second,
+		Duration: 3 * time.Second,
+		Clock:    clock,
+	}
+
+	donec := make(chan struct{})
+	go func() {
+		defer close(donec)
+		if _, err := p.Run(context.Background()); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	for {
+		select {
+		case <-donec:
+			if got := clock.Now(); !got.Equal(time.Unix(3, 0)) {
+				t.Fatalf("expected profiling to end at 3s, got %v", got.Sub(time.Unix(0, 0)))
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+		// deadline and one interval pending
+		if clock.Waiters() == 2 {
+			clock.Advance(time.Second)
+		}
+	}
+}
+
+func TestPruneAccumulators(t *testing.T) {
+	accs := map[int64]*cpuAccumulator{
+		1: {starttime: 10, n: 5, mean: 90}, // exited, top
+		2: {starttime: 20, n: 5, mean: 50},
+		3: {starttime: 30, n: 5, mean: 1}, // live, low rate
+		4: {starttime: 40, n: 5, mean: 2},
+	}
+	pruneAccumulators(accs, 3)
+	if len(accs) != 3 {
+		t.Fatalf("expected 3 accumulators, got %d", len(accs))
+	}
+	if _, ok := accs[3]; ok {
+		t.Fatal("expected the lowest CPU consumer evicted")
+	}
+	if accs[1].n != 5 || accs[4].mean != 2 {
+		t.Fatalf("unexpected accumulators %+v %+v", accs[1], accs[4])
+	}
+}
@@ -1,25 +1,38 @@
 package inspect
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"log"
+	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/gyuho/linux-inspect/proc"
 	"github.com/gyuho/linux-inspect/top"
 
 	"github.com/gyuho/dataframe"
-	"github.com/olekukonko/tablewriter"
 )
 
 // PSEntry is a process entry.
 // Simplied from 'Stat' and 'Status'.
 type PSEntry struct {
 	Program string
-	State   string
-	PID     int64
-	PPID    int64
+	// User is the name of the effective user,
+	// or the UID if it has no name.
+	User  string
+	UID   uint64
+	State string
+	PID   int64
+	PPID  int64
+
+	// TTY is the controlling terminal (e.g. "pts/0"), "?" if none.
+	TTY       string
+	StartTime time.Time
+	// Command is the full command line, or the program
+	// in brackets (e.g. "[kthreadd]") for kernel threads.
+	Command string
 
 	CPU    string
 	VMRSS  string
@@ -37,18 +50,33 @@ type PSEntry struct {
 	VMSizeNum uint64
 }
 
-const maxConcurrentProcFDLimit = 32
+// GetPS finds all PSEntry by given filter, like 'ps'.
+// It matches the user with 'WithUID' or 'WithUsername'.
+// Processes that could not be read are returned in '*PartialError'
+// with the other entries, unless 'WithStrictErrors'.
+func GetPS(opts ...OpFunc) ([]PSEntry, error) {
+	return GetPSContext(context.Background(), opts...)
+}
 
-// GetPS finds all PSEntry by given filter.
-func GetPS(opts ...OpFunc) (pss []PSEntry, err error) {
+// GetPSContext is 'GetPS' with a context. Once the context is done,
+// it stops reading '/proc' and returns the context error.
+func GetPSContext(ctx context.Context, opts ...OpFunc) (pss []PSEntry, err error) {
 	op := &EntryOp{}
 	op.applyOpts(opts)
+	defer func(start time.Time) { op.Instrument.Observe("GetPS", start, err) }(op.Instrument.Now())
+	if err = op.resolveUsernames(); err != nil {
+		return nil, err
+	}
 
 	var pids []int64
 	switch {
+	case len(op.PIDs) > 0:
+		// already know PIDs to query
+		pids = op.PIDs
+
 	case op.ProgramMatchFunc == nil && op.PID < 1:
 		// get all PIDs
-		pids, err = proc.ListPIDs()
+		pids, err = proc.ListPIDsContext(ctx)
 		if err != nil {
 			return
 		}
@@ -67,14 +95,22 @@ func GetPS(opts ...OpFunc) (pss []PSEntry, err error) {
 	// can't filter both by program and by PID
 	if len(pids) == 0 {
 		// list all PIDs, or later to match by Program
-		if pids, err = proc.ListPIDs(); err != nil {
+		if pids, err = proc.ListPIDsContext(ctx); err != nil {
 			return
 		}
 	} else {
 		op.ProgramMatchFunc = func(string) bool { return true }
 	}
+	if op.ExcludeSelf {
+		pids = excludePID(pids, int64(os.Getpid()))
+	}
 
-	var topM map[int64]top.Row
+	var (
+		topM map[int64]top.Row
+		// noTop are the PIDs missing in the 'top' output,
+		// reported if the process is still there
+		noTop = make(map[int64]bool)
+	)
 	if op.TopStream == nil {
 		var topRows []top.Row
 		if len(pids) == 1 {
@@ -95,70 +131,107 @@ func GetPS(opts ...OpFunc) (pss []PSEntry, err error) {
 		for _, pid := range pids {
 			if _, ok := topM[pid]; !ok {
 				topM[pid] = top.Row{PID: pid}
-				log.Printf("PID %d is not found at 'top' command output", pid)
+				noTop[pid] = true
 			}
 		}
 	} else {
 		topM = op.TopStream.Latest()
 	}
 
+	uptime, err := proc.GetUptime()
+	if err != nil {
+		return nil, err
+	}
+	boot := op.Clock.Now().Add(-time.Duration(uptime.UptimeTotal * float64(time.Second)))
+
 	var pmu sync.RWMutex
-	var wg sync.WaitGroup
-	wg.Add(len(pids))
-	limitc := make(chan struct{}, maxConcurrentProcFDLimit)
-	for _, pid := range pids {
-		go func(pid int64) {
-			defer func() {
-				<-limitc
-				wg.Done()
-			}()
-
-			limitc <- struct{}{}
-
-			topRow := topM[pid]
-			if !op.ProgramMatchFunc(topRow.COMMAND) {
-				return
-			}
+	errs := &pidErrors{op: op}
+	err = forEachPID(ctx, pids, op.Concurrency, func(_ context.Context, pid int64) error {
+		topRow := topM[pid]
+		if !op.ProgramMatchFunc(topRow.COMMAND) {
+			return nil
+		}
+		if ok, cerr := op.matchCgroup(pid); cerr != nil {
+			return errs.add(pid, "proc.GetCgroupsByPID", cerr)
+		} else if !ok {
+			return nil
+		}
 
-			pmu.RLock()
-			done := op.TopLimit > 0 && len(pss) >= op.TopLimit
-			pmu.RUnlock()
-			if done {
-				return
-			}
+		pmu.RLock()
+		done := op.TopLimit > 0 && len(pss) >= op.TopLimit
+		pmu.RUnlock()
+		if done {
+			return nil
+		}
 
-			ent, err := getPSEntry(pid, topRow)
-			if err != nil {
-				log.Printf("getPSEntry error %v for PID %d", err, pid)
-				return
-			}
+		ent, err := getPSEntry(pid, topRow, boot)
+		op.Instrument.procRead(err)
+		if err != nil {
+			return errs.add(pid, "getPSEntry", err)
+		}
+		if len(op.UIDs) > 0 && !containsUID(op.UIDs, ent.UID) {
+			return nil
+		}
+		if noTop[pid] {
+			errs.record(&PIDError{PID: pid, Op: "top.Get", Err: fmt.Errorf("PID not found in 'top' output")})
+		}
 
-			pmu.Lock()
-			pss = append(pss, ent)
-			pmu.Unlock()
-		}(pid)
+		pmu.Lock()
+		pss = append(pss, ent)
+		pmu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	wg.Wait()
 
 	if op.TopLimit > 0 && len(pss) > op.TopLimit {
 		pss = pss[:op.TopLimit:op.TopLimit]
 	}
-	return
+
+	// look up the users once the scan is done, not under 'pmu'
+	users := newUserCache(op.Instrument)
+	for i := range pss {
+		pss[i].User = users.lookupOrUID(pss[i].UID).Username
+	}
+	return pss, errs.err()
 }
 
-func getPSEntry(pid int64, topRow top.Row) (PSEntry, error) {
+func getPSEntry(pid int64, topRow top.Row, boot time.Time) (PSEntry, error) {
 	status, err := proc.GetStatusByPID(pid)
 	if err != nil {
 		return PSEntry{}, err
 	}
+	stat, err := proc.GetStatByPID(pid)
+	if err != nil {
+		return PSEntry{}, err
+	}
+	cmdline, err := proc.GetCmdlineByPID(pid)
+	if err != nil {
+		return PSEntry{}, err
+	}
+	// real, effective, saved set, and filesystem UIDs
+	uids := strings.Fields(status.Uid)
+	if len(uids) < 2 {
+		return PSEntry{}, fmt.Errorf("unexpected Uid %q", status.Uid)
+	}
+	euid, err := strconv.ParseUint(uids[1], 10, 64)
+	if err != nil {
+		return PSEntry{}, err
+	}
 
 	entry := PSEntry{
 		Program: status.Name,
 		State:   status.StateParsedStatus,
 
+		UID:  euid,
 		PID:  status.Pid,
 		PPID: status.PPid,
 
+		TTY:       ttyName(stat.TtyNr),
+		StartTime: boot.Add(time.Duration(stat.Starttime) * time.Second / proc.UserHZ),
+		Command:   strings.Join(cmdline, " "),
+
 		CPU:    fmt.Sprintf("%3.2f %%", topRow.CPUPercent),
 		VMRSS:  status.VmRSSParsedBytes,
 		VMSize: status.VmSizeParsedBytes,
@@ -180,12 +253,67 @@ func getPSEntry(pid int64, topRow top.Row) (PSEntry, error) {
 	if status.StateParsedStatus != "" {
 		entry.State = status.StateParsedStatus
 	}
+	if len(cmdline) == 0 {
+		// kernel thread or zombie
+		entry.Command = "[" + status.Name + "]"
+	}
 
 	return entry, nil
 }
 
-const columnsPSToShow = 11
+// ttyName returns the name of the terminal device number
+// in '/proc/$PID/stat', "?" if none.
+func ttyName(nr int64) string {
+	if nr == 0 {
+		return "?"
+	}
+	major := (nr >> 8) & 0xfff
+	minor := (nr & 0xff) | ((nr >> 12) & 0xfff00)
+	switch {
+	case major == 4 && minor < 64:
+		return fmt.Sprintf("tty%d", minor)
+	case major == 4:
+		return fmt.Sprintf("ttyS%d", minor-64)
+	case major >= 136 && major <= 143:
+		return fmt.Sprintf("pts/%d", (major-136)<<8|minor)
+	default:
+		return fmt.Sprintf("%d,%d", major, minor)
+	}
+}
+
+const columnsPSToShow = 15
+
+var columnsPS = []string{
+	"PROGRAM",
+	"USER",
+
+	"STATE",
+	"PID",
+	"PPID",
+
+	"TTY",
+	"START",
+
+	"CPU",
+	"VMRSS",
+	"VMSIZE",
+
+	"FD",
+	"THREADS",
 
+	"VOLUNTARY-CTXT-SWITCHES",
+	"NON-VOLUNTARY-CTXT-SWITCHES",
+
+	"COMMAND",
+
+	// extra for sorting
+	"CPU-NUM",
+	"VMRSS-NUM",
+	"VMSIZE-NUM",
+}
+
+// columnsPSEntry is the columns of PSEntry in
+// the 'Proc' CSV files ('ProcHeader').
 var columnsPSEntry = []string{
 	"PROGRAM",
 
@@ -211,58 +339,51 @@ var columnsPSEntry = []string{
 
 // ConvertPS converts to rows.
 func ConvertPS(nss ...PSEntry) (header []string, rows [][]string) {
-	header = columnsPSEntry
+	header = columnsPS
 	rows = make([][]string, len(nss))
 	for i, elem := range nss {
-		row := make([]string, len(columnsPSEntry))
-		row[0] = elem.Program
+		row := make([]string, len(columnsPS))
+		row[0] = sanitizeUTF8(elem.Program)
+		row[1] = sanitizeUTF8(elem.User)
+
+		row[2] = elem.State
+		row[3] = fmt.Sprintf("%d", elem.PID)
+		row[4] = fmt.Sprintf("%d", elem.PPID)
 
-		row[1] = elem.State
-		row[2] = fmt.Sprintf("%d", elem.PID)
-		row[3] = fmt.Sprintf("%d", elem.PPID)
+		row[5] = elem.TTY
+		if !elem.StartTime.IsZero() {
+			row[6] = elem.StartTime.Format(time.RFC3339)
+		}
 
-		row[4] = elem.CPU
-		row[5] = elem.VMRSS
-		row[6] = elem.VMSize
+		row[7] = elem.CPU
+		row[8] = elem.VMRSS
+		row[9] = elem.VMSize
 
-		row[7] = fmt.Sprintf("%d", elem.FD)
-		row[8] = fmt.Sprintf("%d", elem.Threads)
+		row[10] = fmt.Sprintf("%d", elem.FD)
+		row[11] = fmt.Sprintf("%d", elem.Threads)
 
-		row[9] = fmt.Sprintf("%d", elem.VoluntaryCtxtSwitches)
-		row[10] = fmt.Sprintf("%d", elem.NonvoluntaryCtxtSwitches)
+		row[12] = fmt.Sprintf("%d", elem.VoluntaryCtxtSwitches)
+		row[13] = fmt.Sprintf("%d", elem.NonvoluntaryCtxtSwitches)
 
-		row[11] = fmt.Sprintf("%3.2f", elem.CPUNum)
-		row[12] = fmt.Sprintf("%d", elem.VMRSSNum)
-		row[13] = fmt.Sprintf("%d", elem.VMSizeNum)
+		row[14] = sanitizeUTF8(elem.Command)
+
+		row[15] = fmt.Sprintf("%3.2f", elem.CPUNum)
+		row[16] = fmt.Sprintf("%d", elem.VMRSSNum)
+		row[17] = fmt.Sprintf("%d", elem.VMSizeNum)
 
 		rows[i] = row
 	}
 	dataframe.SortBy(
 		rows,
-		dataframe.Float64DescendingFunc(12), // VMRSSNum
-		dataframe.Float64DescendingFunc(11), // CPUNum
-		dataframe.Float64DescendingFunc(13), // VMSizeNum
+		dataframe.Float64DescendingFunc(16), // VMRSSNum
+		dataframe.Float64DescendingFunc(15), // CPUNum
+		dataframe.Float64DescendingFunc(17), // VMSizeNum
 	).Sort(rows)
 
 	return
 }
 
 // StringPS converts in print-friendly format.
-func StringPS(header []string, rows [][]string, topLimit int) string {
-	buf := new(bytes.Buffer)
-	tw := tablewriter.NewWriter(buf)
-	tw.SetHeader(header[:columnsPSToShow:columnsPSToShow])
-
-	if topLimit > 0 && len(rows) > topLimit {
-		rows = rows[:topLimit:topLimit]
-	}
-
-	for _, row := range rows {
-		tw.Append(row[:columnsPSToShow:columnsPSToShow])
-	}
-	tw.SetAutoFormatHeaders(false)
-	tw.SetAlignment(tablewriter.ALIGN_RIGHT)
-	tw.Render()
-
-	return buf.String()
+func StringPS(header []string, rows [][]string, topLimit int, opts ...ColumnOption) string {
+	return renderTable(header, rows, topLimit, firstColumns(columnsPSToShow), opts)
 }
@@ -2,8 +2,12 @@ package inspect
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gyuho/linux-inspect/top"
 )
@@ -68,3 +72,77 @@ func TestGetPSWithTopStream(t *testing.T) {
 	}
 	fmt.Println("total", len(rm), "processes")
 }
+
+func TestGetPSWithUser(t *testing.T) {
+	pid := int64(os.Getpid())
+
+	ns, err := GetPS(WithPID(pid), WithUID(uint64(os.Geteuid())))
+	if err != nil {
+		t.Skip(err)
+	}
+	if len(ns) != 1 {
+		t.Fatalf("expected 1 entry, got %+v", ns)
+	}
+	ent := ns[0]
+	if ent.User == "" || ent.TTY == "" || !strings.Contains(ent.Command, os.Args[0]) {
+		t.Fatalf("unexpected entry %+v", ent)
+	}
+	if now := time.Now(); ent.StartTime.After(now) || ent.StartTime.Before(now.Add(-time.Hour)) {
+		t.Fatalf("unexpected start time %v", ent.StartTime)
+	}
+
+	ns, err = GetPS(WithPID(pid), WithUID(uint64(os.Geteuid())+1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ns) != 0 {
+		t.Fatalf("expected no entry, got %+v", ns)
+	}
+}
+
+func Test_ttyName(t *testing.T) {
+	tests := []struct {
+		nr   int64
+		name string
+	}{
+		{0, "?"},
+		{4<<8 | 1, "tty1"},
+		{4<<8 | 65, "ttyS1"},
+		{136<<8 | 3, "pts/3"},
+		{137<<8 | 2, "pts/258"},
+		{136<<8 | 0x100<<12, "pts/256"},
+		{5<<8 | 1, "5,1"},
+	}
+	for i, tt := range tests {
+		if name := ttyName(tt.nr); name != tt.name {
+			t.Fatalf("#%d: expected %q, got %q", i, tt.name, name)
+		}
+	}
+}
+
+func TestGetPSNotInTop(t *testing.T) {
+	// 'top' that lists no process
+	fpath := filepath.Join(os.TempDir(), fmt.Sprintf("test-top-%010d", time.Now().UnixNano()))
+	if err := ioutil.WriteFile(fpath, []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(fpath)
+
+	pid := int64(os.Getpid())
+	for _, opts := range [][]OpFunc{
+		{WithPID(pid), WithTopExecPath(fpath)},
+		{WithPID(pid), WithTopExecPath(fpath), WithStrictErrors()},
+	} {
+		ns, err := GetPS(opts...)
+		perr, ok := err.(*PartialError)
+		if !ok {
+			t.Fatalf("expected partial error, got %v", err)
+		}
+		if len(perr.Errors) != 1 || perr.Errors[0].PID != pid || perr.Errors[0].Op != "top.Get" {
+			t.Fatalf("unexpected errors %+v", perr.Errors)
+		}
+		if len(ns) != 1 || ns[0].PID != pid || ns[0].User == "" {
+			t.Fatalf("unexpected entries %+v", ns)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gyuho/linux-inspect/proc"
+)
+
+// socketOwner is a process holding a socket open.
+type socketOwner struct {
+	pid     int64
+	program string
+	user    user.User
+}
+
+// getSocketOwners maps the socket inodes to the processes holding them
+// open in '/proc/$PID/fd', for the processes matching the PID, program
+// or cgroup filter. It returns true if the processes are filtered, in which case
+// the sockets without an owner are to be excluded. The per-PID errors are
+// collected in 'errs'.
+func getSocketOwners(ctx context.Context, ft *EntryOp, errs *pidErrors) (owners map[uint64][]socketOwner, filtered bool, err error) {
+	filtered = len(ft.PIDs) > 0 || ft.PID > 0 || ft.ProgramMatchFunc != nil || ft.CgroupPathPrefix != ""
+	var pids []int64
+	switch {
+	case len(ft.PIDs) > 0:
+		pids = ft.PIDs
+	case ft.PID > 0:
+		pids = []int64{ft.PID}
+	default:
+		if pids, err = proc.ListPIDsContext(ctx); err != nil {
+			return nil, false, err
+		}
+	}
+	match := ft.ProgramMatchFunc
+	if match == nil {
+		match = func(string) bool { return true }
+	}
+	if ft.ExcludeSelf {
+		pids = excludePID(pids, int64(os.Getpid()))
+	}
+
+	var mu sync.Mutex
+	users := newUserCache(ft.Instrument)
+	owners = make(map[uint64][]socketOwner)
+	err = forEachPID(ctx, pids, ft.Concurrency, func(_ context.Context, pid int64) error {
+		stat, serr := proc.GetStatByPID(pid)
+		ft.Instrument.procRead(serr)
+		if serr != nil {
+			return errs.add(pid, "proc.GetStatByPID", serr)
+		}
+		if !match(stat.Comm) {
+			return nil
+		}
+		if ok, cerr := ft.matchCgroup(pid); cerr != nil {
+			return errs.add(pid, "proc.GetCgroupsByPID", cerr)
+		} else if !ok {
+			return nil
+		}
+		inodes, ierr := proc.GetSocketInodesByPID(pid)
+		ft.Instrument.procRead(ierr)
+		if ierr != nil {
+			return errs.add(pid, "proc.GetSocketInodesByPID", ierr)
+		}
+		if len(inodes) == 0 {
+			return nil
+		}
+		o := socketOwner{pid: pid, program: stat.Comm}
+		uid, uerr := realUIDByPID(pid)
+		ft.Instrument.procRead(uerr)
+		if uerr != nil {
+			return errs.add(pid, "proc.GetStatusByPID", uerr)
+		}
+
+		mu.Lock()
+		o.user = users.lookupOrUID(uid)
+		for _, inode := range inodes {
+			owners[inode] = append(owners[inode], o)
+		}
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return owners, filtered, nil
+}
+
+// realUIDByPID returns the real user ID of the process.
+func realUIDByPID(pid int64) (uint64, error) {
+	status, err := proc.GetStatusByPID(pid)
+	if err != nil {
+		return 0, err
+	}
+	fs := strings.Fields(status.Uid)
+	if len(fs) == 0 {
+		return 0, fmt.Errorf("no Uid in status for PID %d", pid)
+	}
+	return strconv.ParseUint(fs[0], 10, 64)
+}
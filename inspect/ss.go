@@ -1,16 +1,16 @@
 package inspect
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"log"
+	"os"
 	"os/user"
+	"sort"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/gyuho/linux-inspect/proc"
-
-	"github.com/gyuho/dataframe"
-	"github.com/olekukonko/tablewriter"
 )
 
 // SSEntry is a socket entry.
@@ -22,25 +22,95 @@ type SSEntry struct {
 	State   string
 	PID     int64
 
+	// StateCode is the raw numeric TCP state (e.g. 0x0A for LISTEN).
+	// Use 'proc.TCPStateName' to translate. For SCTP associations,
+	// it is the SCTP state (see 'proc.SCTPStates').
+	StateCode int
+
 	LocalIP   string
 	LocalPort int64
 
 	RemoteIP   string
 	RemotePort int64
 
+	// Interface is the scope of a link-local IPv6 socket (e.g. "eth0"),
+	// empty for other sockets. 'ConvertSS' renders the link-local
+	// addresses with the zone (e.g. "fe80::1%eth0").
+	Interface string
+
 	User user.User
+
+	// TxQueueBytes, RxQueueBytes are the bytes in the send and receive
+	// queues (like 'Send-Q', 'Recv-Q' in 'ss'). For LISTEN sockets,
+	// RxQueueBytes is the number of connections waiting to be accepted,
+	// and TxQueueBytes the accept backlog.
+	TxQueueBytes uint64
+	RxQueueBytes uint64
+
+	// Inode is the socket inode, and FD the file descriptor of
+	// the process holding it (as in '/proc/$PID/fd/$FD').
+	Inode uint64
+	FD    int64
+
+	// NetNS is the network namespace of the socket (e.g. "net:[4026531992]"),
+	// empty if not readable.
+	NetNS string
+
+	// TCPInfo is the kernel TCP_INFO (e.g. RTT, congestion window),
+	// only set with 'WithTCPInfo'.
+	TCPInfo *proc.TCPInfo
+
+	// LocalService, RemoteService are the service names of the ports
+	// (e.g. "https"), only set with 'WithServiceNames'.
+	LocalService  string
+	RemoteService string
+
+	// LocalHost, RemoteHost are the reverse DNS names,
+	// only set with 'WithResolveHostnames'.
+	LocalHost  string
+	RemoteHost string
+
+	// Direction is the inferred connection direction
+	// ("listen", "inbound", "outbound"), only set with 'WithDirection'.
+	Direction string
 }
 
 // GetSS finds all SSEntry by given filter.
-func GetSS(opts ...OpFunc) (sss []SSEntry, err error) {
+//
+// The socket tables are read once per network namespace, and each
+// socket is attributed to the processes holding its inode open
+// in '/proc/$PID/fd'. A socket shared by several processes (e.g. a
+// listener inherited by forked workers) is listed once per process,
+// like 'ss -p'; the entries have the same Inode. Sockets without an
+// owner (e.g. TIME_WAIT) are not listed, unless 'WithKernelSockets'.
+// The entries are sorted (see 'WithSSSort') before 'WithTopLimit'
+// is applied.
+func GetSS(opts ...OpFunc) ([]SSEntry, error) {
+	return GetSSContext(context.Background(), opts...)
+}
+
+// GetSSContext is 'GetSS' with a context. Once the context is done,
+// it stops reading '/proc' and returns the context error.
+func GetSSContext(ctx context.Context, opts ...OpFunc) (sss []SSEntry, err error) {
 	ft := &EntryOp{}
 	ft.applyOpts(opts)
+	defer func(start time.Time) { ft.Instrument.Observe("GetSS", start, err) }(ft.Instrument.Now())
+	if err = ft.resolveUsernames(); err != nil {
+		return nil, err
+	}
+
+	// kernel sockets are only listed without a process filter
+	kernel := ft.KernelSockets && len(ft.PIDs) == 0 && ft.PID < 1 && ft.ProgramMatchFunc == nil && ft.CgroupPathPrefix == ""
 
 	var pids []int64
 	switch {
+	case len(ft.PIDs) > 0:
+		// already know PIDs to query
+		pids = ft.PIDs
+
 	case ft.ProgramMatchFunc == nil && ft.PID < 1:
 		// get all PIDs
-		pids, err = proc.ListPIDs()
+		pids, err = proc.ListPIDsContext(ctx)
 		if err != nil {
 			return
 		}
@@ -58,99 +128,374 @@ func GetSS(opts ...OpFunc) (sss []SSEntry, err error) {
 
 	if len(pids) == 0 {
 		// find PIDs by Program
-		if pids, err = proc.ListPIDs(); err != nil {
+		if pids, err = proc.ListPIDsContext(ctx); err != nil {
 			return
 		}
 	} else {
 		// already know PIDs to query
 		ft.ProgramMatchFunc = func(string) bool { return true }
 	}
+	if ft.ExcludeSelf {
+		pids = excludePID(pids, int64(os.Getpid()))
+	}
 
-	var pmu sync.RWMutex
-	var wg sync.WaitGroup
-	limitc := make(chan struct{}, maxConcurrentProcFDLimit)
-
-	f := func(pid int64, ttype proc.TransportProtocol) {
-		defer func() {
-			<-limitc
-			wg.Done()
-		}()
-		limitc <- struct{}{}
+	var ttypes []proc.TransportProtocol
+	if ft.TCP {
+		ttypes = append(ttypes, proc.TypeTCP)
+	}
+	if ft.TCP6 {
+		ttypes = append(ttypes, proc.TypeTCP6)
+	}
+	if ft.SCTP {
+		ttypes = append(ttypes, proc.TypeSCTP)
+	}
+	if ft.Raw {
+		ttypes = append(ttypes, proc.TypeRaw, proc.TypeRaw6)
+	}
+	if ft.ICMP {
+		ttypes = append(ttypes, proc.TypeICMP, proc.TypeICMP6)
+	}
 
-		stat, err := proc.GetStatByPID(pid)
-		if err != nil {
-			log.Printf("proc.GetStatByPID error %v for PID %d", err, pid)
-			return
+	// find the sockets of each process, and its network namespace
+	var (
+		mu     sync.Mutex
+		owners []ssOwner
+		errs   = &pidErrors{op: ft}
+	)
+	err = forEachPID(ctx, pids, ft.Concurrency, func(_ context.Context, pid int64) error {
+		stat, serr := proc.GetStatByPID(pid)
+		ft.Instrument.procRead(serr)
+		if serr != nil {
+			return errs.add(pid, "proc.GetStatByPID", serr)
 		}
 		if !ft.ProgramMatchFunc(stat.Comm) {
-			return
+			return nil
 		}
-
-		pmu.RLock()
-		done := ft.TopLimit > 0 && len(sss) >= ft.TopLimit
-		pmu.RUnlock()
-		if done {
-			return
+		if ok, cerr := ft.matchCgroup(pid); cerr != nil {
+			return errs.add(pid, "proc.GetCgroupsByPID", cerr)
+		} else if !ok {
+			return nil
+		}
+		sfds, ierr := proc.GetSocketFDsByPID(pid)
+		ft.Instrument.procRead(ierr)
+		if ierr != nil {
+			return errs.add(pid, "proc.GetSocketFDsByPID", ierr)
 		}
+		if len(sfds) == 0 {
+			return nil
+		}
+		o := ssOwner{pid: pid, sfds: sfds}
+		o.netns, o.netnsKey = ssNetNamespace(pid)
+
+		mu.Lock()
+		owners = append(owners, o)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		ents, err := getSSEntry(pid, ttype, ft.LocalPort, ft.RemotePort)
-		if err != nil {
-			log.Printf("getSSEntry error %v for PID %d", err, pid)
-			return
+	// read the socket tables once per network namespace
+	readers := owners
+	if kernel {
+		// this namespace is read even if no process is visible in it
+		self := ssOwner{pid: int64(os.Getpid())}
+		self.netns, self.netnsKey = ssNetNamespace(self.pid)
+		readers = append([]ssOwner{self}, owners...)
+	}
+	tables := make(map[string]map[proc.TransportProtocol]ssTable)
+	raws := make(map[string]map[proc.TransportProtocol][]proc.NetTCPInfo)
+	for _, o := range readers {
+		if _, ok := tables[o.netnsKey]; ok {
+			continue
 		}
+		tables[o.netnsKey] = make(map[proc.TransportProtocol]ssTable, len(ttypes))
+		raws[o.netnsKey] = make(map[proc.TransportProtocol][]proc.NetTCPInfo, len(ttypes))
+		for _, ttype := range ttypes {
+			if err = ctx.Err(); err != nil {
+				return nil, err
+			}
+			nis, terr := readSSTable(o.pid, o.netnsKey, ttype, ft, errs)
+			ft.Instrument.procRead(terr)
+			var table ssTable
+			if terr == nil {
+				table, terr = indexSSTable(nis)
+			}
+			if terr != nil {
+				if err = errs.add(o.pid, "socket table", terr); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			tables[o.netnsKey][ttype] = table
+			if kernel {
+				raws[o.netnsKey][ttype] = nis
+			}
+		}
+	}
 
-		pmu.Lock()
-		sss = append(sss, ents...)
-		pmu.Unlock()
+	var lps map[ssListenKey]struct{}
+	if ft.Direction {
+		lps = ssListenPorts(tables)
 	}
 
-	wg.Add(len(pids))
-	if ft.TCP && ft.TCP6 {
-		wg.Add(len(pids))
+	// join the socket inodes of each process with its namespace tables
+	users := newUserCache(ft.Instrument)
+	scopes := map[string]*ssScopes{selfNetNamespace(): newSSScopes()}
+	sort.Slice(owners, func(i, j int) bool { return owners[i].pid < owners[j].pid })
+	for _, o := range owners {
+		var ownerSSs []SSEntry
+		for _, ttype := range ttypes {
+			table := tables[o.netnsKey][ttype]
+			if len(table) == 0 {
+				continue
+			}
+			var socks []ssSocket
+			for _, sfd := range o.sfds {
+				if elem, ok := table[sfd.Inode]; ok {
+					socks = append(socks, ssSocket{NetTCPInfo: elem, SocketFD: sfd})
+				}
+			}
+			ents, cerr := convertNetTCP(o.pid, socks, scopes[o.netnsKey], users, ft)
+			if cerr != nil {
+				if err = errs.add(o.pid, "convertNetTCP", cerr); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			ownerSSs = append(ownerSSs, ents...)
+		}
+		if len(ownerSSs) == 0 {
+			continue
+		}
+
+		// only read the program of the processes with matching sockets
+		pname, perr := proc.GetProgram(o.pid)
+		ft.Instrument.procRead(perr)
+		if perr != nil {
+			if err = errs.add(o.pid, "proc.GetProgram", perr); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for i := range ownerSSs {
+			ownerSSs[i].Program = pname
+			ownerSSs[i].NetNS = o.netns
+		}
+		if ft.Direction {
+			setSSDirections(ownerSSs, o.netnsKey, lps)
+		}
+		sss = append(sss, ownerSSs...)
 	}
-	for _, pid := range pids {
-		if ft.TCP {
-			go f(pid, proc.TypeTCP)
+
+	if kernel {
+		claimers := owners
+		if ft.ExcludeSelf {
+			// the sockets of this process are excluded,
+			// not held by the kernel
+			self := ssOwner{pid: int64(os.Getpid())}
+			self.netns, self.netnsKey = ssNetNamespace(self.pid)
+			sfds, serr := proc.GetSocketFDsByPID(self.pid)
+			ft.Instrument.procRead(serr)
+			if serr != nil {
+				if err = errs.add(self.pid, "proc.GetSocketFDsByPID", serr); err != nil {
+					return nil, err
+				}
+			}
+			self.sfds = sfds
+			claimers = append(owners[:len(owners):len(owners)], self)
 		}
-		if ft.TCP6 {
-			go f(pid, proc.TypeTCP6)
+		kss, kerr := convertKernelSockets(readers, claimers, raws, scopes, lps, users, ft, errs)
+		if kerr != nil {
+			return nil, kerr
 		}
+		sss = append(sss, kss...)
 	}
-	wg.Wait()
 
+	// sort all matches before truncating, so top N is stable
+	less := ft.SSLess
+	if less == nil {
+		less = defaultSSLess
+	}
+	sort.SliceStable(sss, func(i, j int) bool { return less(sss[i], sss[j]) })
 	if ft.TopLimit > 0 && len(sss) > ft.TopLimit {
 		sss = sss[:ft.TopLimit:ft.TopLimit]
 	}
-	return
+	if ft.ResolveHostnames {
+		resolveSS(ctx, defaultResolver, sss, ft.Clock)
+	}
+	if ft.ServiceNames {
+		resolveServices(loadServices(), ft.ServiceOverrides, sss)
+	}
+	return sss, errs.err()
+}
+
+// ssOwner is a process holding sockets.
+type ssOwner struct {
+	pid  int64
+	sfds []proc.SocketFD
+
+	// netns is the network namespace, empty if not readable.
+	netns string
+	// netnsKey groups the processes sharing the socket tables.
+	netnsKey string
+}
+
+// ssNetNamespace returns the network namespace of the process, and
+// the key of its socket tables. If the namespace is not readable,
+// the process reads its own tables.
+func ssNetNamespace(pid int64) (netns, key string) {
+	netns, err := proc.GetNamespaceByPID(pid, "net")
+	if err != nil {
+		return "", fmt.Sprintf("pid:%d", pid)
+	}
+	return netns, netns
+}
+
+// ssTable is a socket table indexed by inode.
+type ssTable map[uint64]proc.NetTCPInfo
+
+// ssSocket is a socket table entry with the file descriptor
+// of the process holding it.
+type ssSocket struct {
+	proc.NetTCPInfo
+	proc.SocketFD
 }
 
-func getSSEntry(pid int64, tp proc.TransportProtocol, lport int64, rport int64) (sss []SSEntry, err error) {
-	nss, nerr := proc.GetNetTCPByPID(pid, tp)
-	if nerr != nil {
-		return nil, nerr
+// selfNetNamespace returns the network namespace of this process,
+// which is the only namespace netlink can dump.
+func selfNetNamespace() string {
+	ns, _ := proc.GetNamespaceByPID(int64(os.Getpid()), "net")
+	return ns
+}
+
+// readSSTable reads the socket table of the network namespace of the process.
+// The netlink errors are recorded in 'errs' before falling back to '/proc'.
+func readSSTable(pid int64, netnsKey string, tp proc.TransportProtocol, ft *EntryOp, errs *pidErrors) ([]proc.NetTCPInfo, error) {
+	netlink := tp == proc.TypeTCP || tp == proc.TypeTCP6
+	if ft.Netlink && netlink && netnsKey == selfNetNamespace() {
+		// always dumped with TCP_INFO, since only netlink reports
+		// the scope of link-local sockets; convertNetTCP drops the
+		// info unless 'WithTCPInfo'
+		nis, err := proc.GetNetTCPInfoByNetlink(tp)
+		if err == nil {
+			return nis, nil
+		}
+		errs.record(&PIDError{PID: pid, Op: "netlink sock_diag", Err: err})
+	}
+	nss, err := proc.GetNetTCPByPID(pid, tp)
+	if err != nil {
+		return nil, err
 	}
-	pname, perr := proc.GetProgram(pid)
-	if perr != nil {
-		return nil, perr
+	return wrapNetTCP(nss), nil
+}
+
+func wrapNetTCP(nss []proc.NetTCP) []proc.NetTCPInfo {
+	nis := make([]proc.NetTCPInfo, len(nss))
+	for i := range nss {
+		nis[i].NetTCP = nss[i]
 	}
+	return nis
+}
 
-	for _, elem := range nss {
-		u, uerr := user.LookupId(fmt.Sprintf("%d", elem.Uid))
-		if uerr != nil {
-			return nil, uerr
+// indexSSTable indexes the socket table by inode. Sockets
+// without an inode (e.g. TIME_WAIT) are skipped.
+func indexSSTable(nis []proc.NetTCPInfo) (ssTable, error) {
+	table := make(ssTable, len(nis))
+	for _, ni := range nis {
+		inode, err := strconv.ParseUint(ni.Inode, 10, 64)
+		if err != nil {
+			return nil, err
 		}
-		if lport > 0 && lport != elem.LocalAddressParsedIPPort {
+		if inode == 0 {
 			continue
 		}
-		if rport > 0 && rport != elem.RemAddressParsedIPPort {
+		table[inode] = ni
+	}
+	return table, nil
+}
+
+// convertKernelSockets converts the sockets of the tables not held by
+// any of 'claimers' (e.g. TIME_WAIT, orphaned), with PID -1 and program "-".
+// The directions are set from 'lps' if 'WithDirection'.
+func convertKernelSockets(readers, claimers []ssOwner, raws map[string]map[proc.TransportProtocol][]proc.NetTCPInfo, scopes map[string]*ssScopes, lps map[ssListenKey]struct{}, users *userCache, ft *EntryOp, errs *pidErrors) (sss []SSEntry, err error) {
+	claimed := make(map[string]map[uint64]bool)
+	for _, o := range claimers {
+		if claimed[o.netnsKey] == nil {
+			claimed[o.netnsKey] = make(map[uint64]bool)
+		}
+		for _, sfd := range o.sfds {
+			claimed[o.netnsKey][sfd.Inode] = true
+		}
+	}
+
+	done := make(map[string]bool)
+	for _, o := range readers {
+		if done[o.netnsKey] {
+			continue
+		}
+		done[o.netnsKey] = true
+
+		for _, nis := range raws[o.netnsKey] {
+			var socks []ssSocket
+			for _, ni := range nis {
+				inode, perr := strconv.ParseUint(ni.Inode, 10, 64)
+				if perr != nil {
+					return nil, perr
+				}
+				if inode != 0 && claimed[o.netnsKey][inode] {
+					continue
+				}
+				socks = append(socks, ssSocket{NetTCPInfo: ni, SocketFD: proc.SocketFD{FD: -1, Inode: inode}})
+			}
+			ents, cerr := convertNetTCP(-1, socks, scopes[o.netnsKey], users, ft)
+			if cerr != nil {
+				if err = errs.add(o.pid, "convertNetTCP", cerr); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			for i := range ents {
+				ents[i].Program = "-"
+				ents[i].NetNS = o.netns
+			}
+			if ft.Direction {
+				setSSDirections(ents, o.netnsKey, lps)
+			}
+			sss = append(sss, ents...)
+		}
+	}
+	return sss, nil
+}
+
+// convertNetTCP converts the socket table entries that pass
+// the filter to SSEntry. scopes resolves the interface of link-local
+// addresses, nil if the table is of another network namespace.
+func convertNetTCP(pid int64, socks []ssSocket, scopes *ssScopes, users *userCache, ft *EntryOp) (sss []SSEntry, err error) {
+	for _, sock := range socks {
+		elem := sock.NetTCP
+		if !ft.matchNetTCP(elem) {
 			continue
 		}
+		var u user.User
+		if !ft.NoUserLookup {
+			u = users.lookupOrUID(elem.Uid)
+		}
+		code, cerr := strconv.ParseInt(elem.St, 16, 64)
+		if cerr != nil {
+			return nil, cerr
+		}
+		txq, rxq, qerr := parseQueues(elem)
+		if qerr != nil {
+			return nil, qerr
+		}
 		entry := SSEntry{
 			Protocol: elem.Type,
 
-			Program: pname,
-			State:   elem.StParsedStatus,
-			PID:     pid,
+			State:     elem.StParsedStatus,
+			StateCode: int(code),
+			PID:       pid,
 
 			LocalIP:   elem.LocalAddressParsedIPHost,
 			LocalPort: elem.LocalAddressParsedIPPort,
@@ -158,7 +503,19 @@ func getSSEntry(pid int64, tp proc.TransportProtocol, lport int64, rport int64)
 			RemoteIP:   elem.RemAddressParsedIPHost,
 			RemotePort: elem.RemAddressParsedIPPort,
 
-			User: *u,
+			User: u,
+
+			TxQueueBytes: txq,
+			RxQueueBytes: rxq,
+
+			Inode: sock.Inode,
+			FD:    sock.FD,
+		}
+		if ft.TCPInfo {
+			entry.TCPInfo = sock.Info
+		}
+		if scopes != nil {
+			entry.Interface = scopes.resolve(entry.LocalIP, entry.RemoteIP, sock.Ifindex)
 		}
 		sss = append(sss, entry)
 	}
@@ -182,58 +539,164 @@ var columnsSSEntry = []string{
 	"REMOTE-PORT",
 
 	"USER",
+
+	"LOCAL-HOST",
+	"REMOTE-HOST",
+
+	"RTT-MS",
+	"CWND",
+	"RETRANS",
+
+	"NETNS",
+
+	"SEND-Q",
+	"RECV-Q",
+
+	"INODE",
+	"FD",
 }
 
-// ConvertSS converts to rows.
+// ConvertSS converts to rows, sorted by program, state, protocol,
+// PID, then local IP.
 func ConvertSS(nss ...SSEntry) (header []string, rows [][]string) {
+	return ConvertSSWith(nss, defaultSSSorts...)
+}
+
+// ConvertSSWith converts to rows, sorted by the columns in order
+// (see 'SortAscending', 'SortDescending'). PIDs, ports and TCP info
+// are sorted numerically, and IPs by address. The order of entries
+// equal in all columns is kept.
+func ConvertSSWith(nss []SSEntry, sorts ...SortOption) (header []string, rows [][]string) {
+	nss = sortSS(nss, sorts)
 	header = columnsSSEntry
 	rows = make([][]string, len(nss))
 	for i, elem := range nss {
 		row := make([]string, len(columnsSSEntry))
 		row[0] = elem.Protocol
 
-		row[1] = elem.Program
+		row[1] = sanitizeUTF8(elem.Program)
 		row[2] = elem.State
 		row[3] = fmt.Sprintf("%d", elem.PID)
 
-		row[4] = elem.LocalIP
-		row[5] = fmt.Sprintf("%d", elem.LocalPort)
+		row[4] = scopedIP(elem.LocalIP, elem.Interface)
+		row[5] = servicePort(elem.LocalPort, elem.LocalService)
+
+		row[6] = scopedIP(elem.RemoteIP, elem.Interface)
+		row[7] = servicePort(elem.RemotePort, elem.RemoteService)
 
-		row[6] = elem.RemoteIP
-		row[7] = fmt.Sprintf("%d", elem.RemotePort)
+		row[8] = sanitizeUTF8(elem.User.Username)
 
-		row[8] = elem.User.Username
+		row[9] = elem.LocalHost
+		row[10] = elem.RemoteHost
+
+		if elem.TCPInfo != nil {
+			row[11] = fmt.Sprintf("%.3f", float64(elem.TCPInfo.RTT)/1000)
+			row[12] = fmt.Sprintf("%d", elem.TCPInfo.SndCwnd)
+			row[13] = fmt.Sprintf("%d", elem.TCPInfo.TotalRetrans)
+		}
+
+		row[14] = elem.NetNS
+
+		row[15] = fmt.Sprintf("%d", elem.TxQueueBytes)
+		row[16] = fmt.Sprintf("%d", elem.RxQueueBytes)
+
+		row[17] = fmt.Sprintf("%d", elem.Inode)
+		row[18] = fmt.Sprintf("%d", elem.FD)
 
 		rows[i] = row
 	}
-	dataframe.SortBy(
-		rows,
-		dataframe.StringAscendingFunc(1), // Program
-		dataframe.StringAscendingFunc(2), // State
-		dataframe.StringAscendingFunc(0), // Protocol
-		dataframe.StringAscendingFunc(3), // PID
-		dataframe.StringAscendingFunc(4), // LocalIP
-	).Sort(rows)
-
 	return
 }
 
-// StringSS converts in print-friendly format.
-func StringSS(header []string, rows [][]string, topLimit int) string {
-	buf := new(bytes.Buffer)
-	tw := tablewriter.NewWriter(buf)
-	tw.SetHeader(header[:columnsSSToShow:columnsSSToShow])
+// StringSS converts in print-friendly format. By default, the remote
+// columns are hidden if all sockets are listening, and the host, TCP info,
+// namespace and queue columns are shown only if set (see 'WithColumns').
+func StringSS(header []string, rows [][]string, topLimit int, opts ...ColumnOption) string {
+	listen := allListen(rows)
+	var idxs []int
+	for i := 0; i < columnsSSToShow; i++ {
+		if listen && (i == 6 || i == 7) { // REMOTE-IP, REMOTE-PORT
+			continue
+		}
+		idxs = append(idxs, i)
+	}
+	if anyHost(rows) {
+		idxs = append(idxs, 9)
+		if !listen {
+			idxs = append(idxs, 10)
+		}
+	}
+	if anyTCPInfo(rows) {
+		idxs = append(idxs, 11, 12, 13)
+	}
+	if multiNetNS(rows) {
+		idxs = append(idxs, 14)
+	}
+	if anyQueued(rows) {
+		idxs = append(idxs, 15, 16)
+	}
+	return renderTable(header, rows, topLimit, idxs, opts)
+}
+
+func allListen(rows [][]string) bool {
+	if len(rows) == 0 {
+		return false
+	}
+	for _, row := range rows {
+		if row[2] != "LISTEN" {
+			return false
+		}
+	}
+	return true
+}
+
+func anyHost(rows [][]string) bool {
+	for _, row := range rows {
+		if row[9] != "" || row[10] != "" {
+			return true
+		}
+	}
+	return false
+}
 
-	if topLimit > 0 && len(rows) > topLimit {
-		rows = rows[:topLimit:topLimit]
+func anyTCPInfo(rows [][]string) bool {
+	for _, row := range rows {
+		if row[11] != "" {
+			return true
+		}
 	}
+	return false
+}
 
+func anyQueued(rows [][]string) bool {
 	for _, row := range rows {
-		tw.Append(row[:columnsSSToShow:columnsSSToShow])
+		if row[15] != "0" || row[16] != "0" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseQueues parses the hex 'tx_queue', 'rx_queue' of the socket.
+func parseQueues(elem proc.NetTCP) (tx, rx uint64, err error) {
+	if elem.TxQueue != "" {
+		if tx, err = strconv.ParseUint(elem.TxQueue, 16, 64); err != nil {
+			return 0, 0, err
+		}
+	}
+	if elem.RxQueue != "" {
+		if rx, err = strconv.ParseUint(elem.RxQueue, 16, 64); err != nil {
+			return 0, 0, err
+		}
 	}
-	tw.SetAutoFormatHeaders(false)
-	tw.SetAlignment(tablewriter.ALIGN_RIGHT)
-	tw.Render()
+	return tx, rx, nil
+}
 
-	return buf.String()
+func multiNetNS(rows [][]string) bool {
+	for _, row := range rows {
+		if row[14] != rows[0][14] {
+			return true
+		}
+	}
+	return false
 }
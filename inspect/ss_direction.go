@@ -0,0 +1,82 @@
+package inspect
+
+import "github.com/gyuho/linux-inspect/proc"
+
+const (
+	// DirectionListen is a server endpoint socket in LISTEN state.
+	DirectionListen = "listen"
+	// DirectionInbound is a connection accepted by a local listener.
+	DirectionInbound = "inbound"
+	// DirectionOutbound is a connection initiated from this host
+	// (from an ephemeral local port).
+	DirectionOutbound = "outbound"
+)
+
+// ListenPorts returns the local ports of all LISTEN sockets in the slice.
+func ListenPorts(sss []SSEntry) map[int64]struct{} {
+	lps := make(map[int64]struct{})
+	for _, elem := range sss {
+		if elem.State == "LISTEN" {
+			lps[elem.LocalPort] = struct{}{}
+		}
+	}
+	return lps
+}
+
+// ConnectionDirection infers the direction of the socket entry.
+// A LISTEN socket is a server endpoint. Any other socket whose
+// local port matches a listener on the same host is inbound,
+// otherwise outbound.
+//
+// The heuristic needs the full socket set to identify local listeners,
+// so 'listenPorts' must be computed over an unfiltered slice with
+// 'ListenPorts', not per-entry. 'WithDirection' computes it from the
+// unfiltered socket tables of each network namespace instead.
+func ConnectionDirection(ent SSEntry, listenPorts map[int64]struct{}) string {
+	if ent.State == "LISTEN" {
+		return DirectionListen
+	}
+	if _, ok := listenPorts[ent.LocalPort]; ok {
+		return DirectionInbound
+	}
+	return DirectionOutbound
+}
+
+// ssListenKey is a listening port of a socket table.
+type ssListenKey struct {
+	netnsKey string
+	protocol string
+	port     int64
+}
+
+// ssListenPorts returns the listening ports of the socket tables,
+// before any filter, so that inbound connections are found even
+// when the listeners themselves are filtered out.
+func ssListenPorts(tables map[string]map[proc.TransportProtocol]ssTable) map[ssListenKey]struct{} {
+	lps := make(map[ssListenKey]struct{})
+	for netnsKey, tts := range tables {
+		for _, table := range tts {
+			for _, ni := range table {
+				if ni.StParsedStatus == "LISTEN" {
+					lps[ssListenKey{netnsKey, ni.Type, ni.LocalAddressParsedIPPort}] = struct{}{}
+				}
+			}
+		}
+	}
+	return lps
+}
+
+// setSSDirections sets the Direction of the entries read from
+// the socket tables of the network namespace.
+func setSSDirections(sss []SSEntry, netnsKey string, lps map[ssListenKey]struct{}) {
+	for i := range sss {
+		switch _, ok := lps[ssListenKey{netnsKey, sss[i].Protocol, sss[i].LocalPort}]; {
+		case sss[i].State == "LISTEN":
+			sss[i].Direction = DirectionListen
+		case ok:
+			sss[i].Direction = DirectionInbound
+		default:
+			sss[i].Direction = DirectionOutbound
+		}
+	}
+}
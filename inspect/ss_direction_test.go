@@ -0,0 +1,72 @@
+package inspect
+
+import (
+	"net"
+	"os"
+	"testing"
+)
+
+func TestConnectionDirection(t *testing.T) {
+	sss := []SSEntry{
+		{State: "LISTEN", LocalPort: 2379},
+		{State: "ESTABLISHED", LocalPort: 2379, RemotePort: 51234},
+		{State: "ESTABLISHED", LocalPort: 51235, RemotePort: 443},
+	}
+	lps := ListenPorts(sss)
+	if len(lps) != 1 {
+		t.Fatalf("len(ListenPorts) expected 1, got %d", len(lps))
+	}
+
+	exp := []string{DirectionListen, DirectionInbound, DirectionOutbound}
+	for i, elem := range sss {
+		if d := ConnectionDirection(elem, lps); d != exp[i] {
+			t.Fatalf("#%d: direction expected %q, got %q", i, exp[i], d)
+		}
+	}
+}
+
+func TestGetSSDirectionFiltered(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	port := int64(ln.Addr().(*net.TCPAddr).Port)
+
+	conn, err := net.Dial("tcp4", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	accepted, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer accepted.Close()
+	clientPort := int64(conn.LocalAddr().(*net.TCPAddr).Port)
+
+	// the listener is filtered out by state, but still marks
+	// the accepted connection as inbound
+	sss, err := GetSS(WithPID(int64(os.Getpid())), WithTCP(), WithState("ESTABLISHED"), WithDirection())
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := 0
+	for _, elem := range sss {
+		switch {
+		case elem.LocalPort == port && elem.RemotePort == clientPort:
+			if elem.Direction != DirectionInbound {
+				t.Fatalf("expected inbound, got %+v", elem)
+			}
+			found++
+		case elem.LocalPort == clientPort && elem.RemotePort == port:
+			if elem.Direction != DirectionOutbound {
+				t.Fatalf("expected outbound, got %+v", elem)
+			}
+			found++
+		}
+	}
+	if found != 2 {
+		t.Fatalf("expected 2 connections, got %+v", sss)
+	}
+}
@@ -0,0 +1,92 @@
+package inspect
+
+import (
+	"encoding/json"
+
+	"github.com/gyuho/linux-inspect/proc"
+)
+
+// SSEntryJSON is the flattened JSON representation of SSEntry,
+// with the owner reduced to its username and UID. The optional
+// fields are omitted when not set.
+type SSEntryJSON struct {
+	Protocol  string `json:"protocol"`
+	Program   string `json:"program"`
+	State     string `json:"state"`
+	StateCode int    `json:"state_code"`
+	PID       int64  `json:"pid"`
+
+	LocalIP    string `json:"local_ip"`
+	LocalPort  int64  `json:"local_port"`
+	RemoteIP   string `json:"remote_ip"`
+	RemotePort int64  `json:"remote_port"`
+	Interface  string `json:"interface,omitempty"`
+
+	Username string `json:"username,omitempty"`
+	UID      string `json:"uid,omitempty"`
+
+	TxQueueBytes uint64 `json:"tx_queue_bytes"`
+	RxQueueBytes uint64 `json:"rx_queue_bytes"`
+
+	Inode uint64 `json:"inode"`
+	FD    int64  `json:"fd"`
+
+	NetNS         string        `json:"netns,omitempty"`
+	TCPInfo       *proc.TCPInfo `json:"tcp_info,omitempty"`
+	LocalService  string        `json:"local_service,omitempty"`
+	RemoteService string        `json:"remote_service,omitempty"`
+	LocalHost     string        `json:"local_host,omitempty"`
+	RemoteHost    string        `json:"remote_host,omitempty"`
+	Direction     string        `json:"direction,omitempty"`
+}
+
+// ToJSON returns the flattened JSON representation.
+func (e SSEntry) ToJSON() SSEntryJSON {
+	return SSEntryJSON{
+		Protocol:  e.Protocol,
+		Program:   sanitizeUTF8(e.Program),
+		State:     e.State,
+		StateCode: e.StateCode,
+		PID:       e.PID,
+
+		LocalIP:    e.LocalIP,
+		LocalPort:  e.LocalPort,
+		RemoteIP:   e.RemoteIP,
+		RemotePort: e.RemotePort,
+		Interface:  e.Interface,
+
+		Username: sanitizeUTF8(e.User.Username),
+		UID:      e.User.Uid,
+
+		TxQueueBytes: e.TxQueueBytes,
+		RxQueueBytes: e.RxQueueBytes,
+
+		Inode: e.Inode,
+		FD:    e.FD,
+
+		NetNS:         e.NetNS,
+		TCPInfo:       e.TCPInfo,
+		LocalService:  e.LocalService,
+		RemoteService: e.RemoteService,
+		LocalHost:     e.LocalHost,
+		RemoteHost:    e.RemoteHost,
+		Direction:     e.Direction,
+	}
+}
+
+// MarshalJSON encodes the entry as 'SSEntryJSON', so that
+// types embedding SSEntry (e.g. 'ProcDump', 'SSEvent') are
+// encoded the same.
+func (e SSEntry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.ToJSON())
+}
+
+// ConvertSSJSON encodes the entries as a JSON array, for shipping
+// to log pipelines (e.g. jq, Elasticsearch).
+func ConvertSSJSON(sss []SSEntry) ([]byte, error) {
+	js := make([]SSEntryJSON, len(sss))
+	for i := range sss {
+		js[i] = sss[i].ToJSON()
+	}
+	return json.Marshal(js)
+}
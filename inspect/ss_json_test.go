@@ -0,0 +1,93 @@
+package inspect
+
+import (
+	"encoding/json"
+	"os/user"
+	"reflect"
+	"testing"
+
+	"github.com/gyuho/linux-inspect/proc"
+)
+
+func TestConvertSSJSON(t *testing.T) {
+	sss := []SSEntry{
+		{
+			Protocol:   "tcp",
+			Program:    "nginx",
+			State:      "LISTEN",
+			StateCode:  0x0A,
+			PID:        123,
+			LocalIP:    "0.0.0.0",
+			LocalPort:  80,
+			RemoteIP:   "0.0.0.0",
+			RemotePort: 0,
+			User:       user.User{Uid: "33", Username: "www-data", HomeDir: "/var/www"},
+		},
+		{
+			Protocol:   "tcp",
+			Program:    "curl",
+			State:      "ESTABLISHED",
+			StateCode:  0x01,
+			PID:        456,
+			LocalIP:    "10.0.0.1",
+			LocalPort:  50000,
+			RemoteIP:   "10.0.0.2",
+			RemotePort: 443,
+			TCPInfo:    &proc.TCPInfo{RTT: 1500, SndCwnd: 10},
+			Direction:  "outbound",
+		},
+	}
+	b, err := ConvertSSJSON(sss)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ms []map[string]interface{}
+	if err = json.Unmarshal(b, &ms); err != nil {
+		t.Fatal(err)
+	}
+	if len(ms) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(ms))
+	}
+	exp := map[string]interface{}{
+		"protocol":    "tcp",
+		"program":     "nginx",
+		"state":       "LISTEN",
+		"state_code":  float64(10),
+		"pid":         float64(123),
+		"local_ip":    "0.0.0.0",
+		"local_port":  float64(80),
+		"remote_ip":   "0.0.0.0",
+		"remote_port": float64(0),
+		"username":    "www-data",
+		"uid":         "33",
+
+		"tx_queue_bytes": float64(0),
+		"rx_queue_bytes": float64(0),
+		"inode":          float64(0),
+		"fd":             float64(0),
+	}
+	if !reflect.DeepEqual(ms[0], exp) {
+		t.Fatalf("expected %v, got %v", exp, ms[0])
+	}
+	info, ok := ms[1]["tcp_info"].(map[string]interface{})
+	if !ok || info["rtt_us"] != float64(1500) || info["snd_cwnd"] != float64(10) {
+		t.Fatalf("unexpected tcp_info %v", ms[1]["tcp_info"])
+	}
+	if ms[1]["direction"] != "outbound" {
+		t.Fatalf("unexpected direction %v", ms[1]["direction"])
+	}
+
+	// SSEntry encodes the same as its flattened form
+	eb, err := json.Marshal(sss[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err = json.Unmarshal(eb, &m); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m, exp) {
+		t.Fatalf("expected %v, got %v", exp, m)
+	}
+}
@@ -0,0 +1,50 @@
+package inspect
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// StringSSLogfmt converts to logfmt format, one line per entry
+// (e.g. 'protocol=tcp pid=123 program=nginx state=LISTEN local=0.0.0.0:80 ...'),
+// which is friendlier for log pipelines than the table.
+// Values with spaces, quotes, or '=' are quoted, and invalid
+// UTF-8 sequences are replaced with U+FFFD.
+func StringSSLogfmt(nss ...SSEntry) string {
+	buf := new(bytes.Buffer)
+	for _, elem := range nss {
+		writeLogfmt(buf, "protocol", elem.Protocol)
+		buf.WriteByte(' ')
+		writeLogfmt(buf, "pid", fmt.Sprintf("%d", elem.PID))
+		buf.WriteByte(' ')
+		writeLogfmt(buf, "program", elem.Program)
+		buf.WriteByte(' ')
+		writeLogfmt(buf, "state", elem.State)
+		buf.WriteByte(' ')
+		writeLogfmt(buf, "local", net.JoinHostPort(elem.LocalIP, fmt.Sprintf("%d", elem.LocalPort)))
+		buf.WriteByte(' ')
+		writeLogfmt(buf, "remote", net.JoinHostPort(elem.RemoteIP, fmt.Sprintf("%d", elem.RemotePort)))
+		buf.WriteByte(' ')
+		writeLogfmt(buf, "user", elem.User.Username)
+		if elem.Direction != "" {
+			buf.WriteByte(' ')
+			writeLogfmt(buf, "direction", elem.Direction)
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+func writeLogfmt(buf *bytes.Buffer, key, value string) {
+	value = sanitizeUTF8(value)
+	buf.WriteString(key)
+	buf.WriteByte('=')
+	if value == "" || strings.ContainsAny(value, " =\"\t\n\\") {
+		buf.WriteString(strconv.Quote(value))
+		return
+	}
+	buf.WriteString(value)
+}
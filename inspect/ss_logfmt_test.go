@@ -0,0 +1,24 @@
+package inspect
+
+import (
+	"os/user"
+	"testing"
+)
+
+func TestStringSSLogfmt(t *testing.T) {
+	txt := StringSSLogfmt(SSEntry{
+		Protocol:   "tcp",
+		Program:    "my server",
+		State:      "LISTEN",
+		PID:        123,
+		LocalIP:    "0.0.0.0",
+		LocalPort:  80,
+		RemoteIP:   "0.0.0.0",
+		RemotePort: 0,
+		User:       user.User{Username: "root"},
+	})
+	exp := `protocol=tcp pid=123 program="my server" state=LISTEN local=0.0.0.0:80 remote=0.0.0.0:0 user=root` + "\n"
+	if txt != exp {
+		t.Fatalf("expected %q, got %q", exp, txt)
+	}
+}
@@ -0,0 +1,147 @@
+package inspect
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// SSSample is a gauge sample of the socket metrics.
+type SSSample struct {
+	Name string
+	// Labels are label name and value pairs, sorted by name.
+	Labels [][2]string
+	Value  float64
+}
+
+const (
+	metricSockets     = "psn_sockets"
+	metricListenPorts = "psn_listen_ports"
+)
+
+// ssDescs are the metric descriptions, whose variable labels
+// are in the order of 'SSSample.Labels'.
+var ssDescs = map[string]*prometheus.Desc{
+	metricSockets: prometheus.NewDesc(
+		metricSockets,
+		"Number of sockets by program, state and protocol.",
+		[]string{"program", "protocol", "state"}, nil,
+	),
+	metricListenPorts: prometheus.NewDesc(
+		metricListenPorts,
+		"Listening ports by program, 1 if listening.",
+		[]string{"port", "program"}, nil,
+	),
+}
+
+// SSSamples returns the socket metrics of the entries:
+//
+//	psn_sockets{program,protocol,state}  number of sockets
+//	psn_listen_ports{port,program}       1 for each listening port
+//
+// The samples are sorted by name then labels, so that the
+// output is stable across scrapes.
+func SSSamples(sss []SSEntry) []SSSample {
+	type socketKey struct{ program, protocol, state string }
+	type listenKey struct {
+		program string
+		port    int64
+	}
+	sockets := make(map[socketKey]int)
+	listens := make(map[listenKey]bool)
+	for _, elem := range sss {
+		sockets[socketKey{elem.Program, elem.Protocol, elem.State}]++
+		if elem.State == "LISTEN" {
+			listens[listenKey{elem.Program, elem.LocalPort}] = true
+		}
+	}
+
+	samples := make([]SSSample, 0, len(sockets)+len(listens))
+	for k, n := range sockets {
+		samples = append(samples, SSSample{
+			Name:   metricSockets,
+			Labels: [][2]string{{"program", k.program}, {"protocol", k.protocol}, {"state", k.state}},
+			Value:  float64(n),
+		})
+	}
+	for k := range listens {
+		samples = append(samples, SSSample{
+			Name:   metricListenPorts,
+			Labels: [][2]string{{"port", fmt.Sprintf("%d", k.port)}, {"program", k.program}},
+			Value:  1,
+		})
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		if samples[i].Name != samples[j].Name {
+			return samples[i].Name < samples[j].Name
+		}
+		return lessLabels(samples[i].Labels, samples[j].Labels)
+	})
+	return samples
+}
+
+func lessLabels(a, b [][2]string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i][0] != b[i][0] {
+				return a[i][0] < b[i][0]
+			}
+			return a[i][1] < b[i][1]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// SSCollector is a 'prometheus.Collector' of the socket metrics
+// (see 'SSSamples'), listing the sockets with 'GetSS' on each scrape.
+type SSCollector struct {
+	list func() ([]SSEntry, error)
+}
+
+// NewSSCollector creates a new SSCollector that lists
+// the sockets with the options.
+func NewSSCollector(opts ...OpFunc) *SSCollector {
+	return &SSCollector{list: func() ([]SSEntry, error) { return GetSS(opts...) }}
+}
+
+// Describe implements 'prometheus.Collector'.
+func (c *SSCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range ssDescs {
+		ch <- desc
+	}
+}
+
+// Collect implements 'prometheus.Collector'. The scrape fails
+// only if 'GetSS' returns no entries with its error.
+func (c *SSCollector) Collect(ch chan<- prometheus.Metric) {
+	sss, err := c.list()
+	if err != nil && len(sss) == 0 {
+		for _, desc := range ssDescs {
+			ch <- prometheus.NewInvalidMetric(desc, err)
+		}
+		return
+	}
+	for _, s := range SSSamples(sss) {
+		desc := ssDescs[s.Name]
+		vs := make([]string, len(s.Labels))
+		for i, l := range s.Labels {
+			vs[i] = sanitizeUTF8(l[1])
+		}
+		m, err := prometheus.NewConstMetric(desc, prometheus.GaugeValue, s.Value, vs...)
+		if err != nil {
+			m = prometheus.NewInvalidMetric(desc, err)
+		}
+		ch <- m
+	}
+}
+
+// SSMetricsHandler serves the socket metrics for Prometheus to scrape,
+// with an 'SSCollector' of the options in its own registry.
+func SSMetricsHandler(opts ...OpFunc) http.Handler {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(NewSSCollector(opts...))
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,84 @@
+package inspect
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+func TestSSCollector(t *testing.T) {
+	sss := []SSEntry{
+		{Program: "nginx", Protocol: "tcp", State: "LISTEN", LocalPort: 80},
+		{Program: "nginx", Protocol: "tcp6", State: "LISTEN", LocalPort: 80},
+		{Program: "nginx", Protocol: "tcp", State: "ESTABLISHED", LocalPort: 80},
+		{Program: "nginx", Protocol: "tcp", State: "ESTABLISHED", LocalPort: 80},
+		{Program: `we"ird`, Protocol: "tcp", State: "LISTEN", LocalPort: 8080},
+	}
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(&SSCollector{list: func() ([]SSEntry, error) { return sss, nil }})
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	for _, mf := range mfs {
+		if _, err = expfmt.MetricFamilyToText(buf, mf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exp := `# HELP psn_listen_ports Listening ports by program, 1 if listening.
+# TYPE psn_listen_ports gauge
+psn_listen_ports{port="80",program="nginx"} 1
+psn_listen_ports{port="8080",program="we\"ird"} 1
+# HELP psn_sockets Number of sockets by program, state and protocol.
+# TYPE psn_sockets gauge
+psn_sockets{program="nginx",protocol="tcp",state="ESTABLISHED"} 2
+psn_sockets{program="nginx",protocol="tcp",state="LISTEN"} 1
+psn_sockets{program="nginx",protocol="tcp6",state="LISTEN"} 1
+psn_sockets{program="we\"ird",protocol="tcp",state="LISTEN"} 1
+`
+	if buf.String() != exp {
+		t.Fatalf("expected\n%s\ngot\n%s", exp, buf.String())
+	}
+
+	// a failed listing fails the scrape
+	reg = prometheus.NewRegistry()
+	reg.MustRegister(&SSCollector{list: func() ([]SSEntry, error) { return nil, errors.New("test") }})
+	if _, err = reg.Gather(); err == nil {
+		t.Fatal("expected gather error")
+	}
+}
+
+func TestSSMetricsHandler(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	srv := httptest.NewServer(SSMetricsHandler(WithPID(int64(os.Getpid())), WithTCP()))
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "psn_listen_ports{port=\""+strconv.Itoa(port)+"\"") {
+		t.Fatalf("listen port %d not found in\n%s", port, b)
+	}
+}
@@ -0,0 +1,77 @@
+package inspect
+
+import (
+	"fmt"
+)
+
+// PortSpec is a port required by a service.
+type PortSpec struct {
+	// Protocol is "tcp", "tcp6", or "" for both.
+	Protocol string
+	Port     int64
+}
+
+// PortCheck is the result of checking a 'PortSpec'.
+type PortCheck struct {
+	PortSpec
+	Available bool
+	// Conflict is the socket bound to the port, if not available.
+	Conflict *SSEntry
+}
+
+// PortAvailable returns true if no socket is bound to the local port.
+// Otherwise, it returns the conflicting entry, preferring a listener.
+// TIME_WAIT sockets are ignored, since they do not prevent a listener
+// with 'SO_REUSEADDR' from binding.
+func PortAvailable(proto string, port int64) (bool, *SSEntry, error) {
+	cs, err := CheckPorts([]PortSpec{{Protocol: proto, Port: port}})
+	if err != nil {
+		return false, nil, err
+	}
+	return cs[0].Available, cs[0].Conflict, nil
+}
+
+// CheckPorts checks all ports at once with a single socket table scan,
+// for deployment readiness checks. The whole socket table is checked,
+// including the sockets whose owner is not visible (e.g. a daemon of
+// another user when not run as root), which conflict with PID -1.
+func CheckPorts(specs []PortSpec) ([]PortCheck, error) {
+	return checkPorts(specs, func() ([]SSEntry, error) { return GetSS(WithKernelSockets()) })
+}
+
+// checkPorts is 'CheckPorts' over the socket entries of 'list'.
+func checkPorts(specs []PortSpec, list func() ([]SSEntry, error)) ([]PortCheck, error) {
+	for _, spec := range specs {
+		switch spec.Protocol {
+		case "", "tcp", "tcp6":
+		default:
+			return nil, fmt.Errorf("unsupported protocol %q for port %d", spec.Protocol, spec.Port)
+		}
+	}
+
+	// unreadable owners only leave the conflicts without a PID
+	sss, err := list()
+	if err != nil && !isPartialError(err) {
+		return nil, err
+	}
+
+	cs := make([]PortCheck, len(specs))
+	for i, spec := range specs {
+		cs[i] = PortCheck{PortSpec: spec, Available: true}
+		for j := range sss {
+			ent := &sss[j]
+			if ent.LocalPort != spec.Port || ent.State == "TIME_WAIT" {
+				continue
+			}
+			if spec.Protocol != "" && ent.Protocol != spec.Protocol {
+				continue
+			}
+			if cs[i].Conflict == nil || (ent.State == "LISTEN" && cs[i].Conflict.State != "LISTEN") {
+				c := *ent
+				cs[i].Conflict = &c
+			}
+			cs[i].Available = false
+		}
+	}
+	return cs, nil
+}
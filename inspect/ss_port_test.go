@@ -0,0 +1,55 @@
+package inspect
+
+import (
+	"net"
+	"testing"
+)
+
+func TestPortAvailable(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	port := int64(ln.Addr().(*net.TCPAddr).Port)
+
+	ok, ent, err := PortAvailable("tcp", port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok || ent == nil || ent.State != "LISTEN" {
+		t.Fatalf("expected port %d in use by listener, got %v %+v", port, ok, ent)
+	}
+
+	cs, err := CheckPorts([]PortSpec{{Protocol: "tcp", Port: port}, {Protocol: "tcp6", Port: port}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cs[0].Available || !cs[1].Available {
+		t.Fatalf("unexpected checks %+v", cs)
+	}
+
+	if _, err = CheckPorts([]PortSpec{{Protocol: "udp", Port: 53}}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestCheckPortsUnownedListener(t *testing.T) {
+	// a listener without a visible owner, as for other users'
+	// processes when not run as root
+	list := func() ([]SSEntry, error) {
+		return []SSEntry{
+			{Protocol: "tcp", Program: "-", PID: -1, State: "LISTEN", LocalIP: "0.0.0.0", LocalPort: 8080},
+		}, nil
+	}
+	cs, err := checkPorts([]PortSpec{{Protocol: "tcp", Port: 8080}, {Protocol: "tcp", Port: 8081}}, list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cs[0].Available || cs[0].Conflict == nil || cs[0].Conflict.PID != -1 || cs[0].Conflict.State != "LISTEN" {
+		t.Fatalf("expected port 8080 in use by unowned listener, got %+v", cs[0])
+	}
+	if !cs[1].Available {
+		t.Fatalf("expected port 8081 available, got %+v", cs[1])
+	}
+}
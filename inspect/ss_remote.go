@@ -0,0 +1,72 @@
+package inspect
+
+import (
+	"context"
+	"net"
+	"sort"
+)
+
+// RemoteHostCount is the number of established connections to a remote host.
+type RemoteHostCount struct {
+	RemoteIP string
+	// Hostname is only set with 'ResolveRemoteHosts'.
+	Hostname string
+	Count    int
+}
+
+// TopRemoteHosts groups ESTABLISHED entries by remote IP, and returns
+// the top n hosts in descending order of connection count.
+// Listeners and unconnected sockets (remote '0.0.0.0' or '::') are excluded.
+// If n <= 0, it returns all hosts.
+func TopRemoteHosts(nss []SSEntry, n int) []RemoteHostCount {
+	counts := make(map[string]int)
+	for _, elem := range nss {
+		if elem.State != "ESTABLISHED" || isUnspecifiedIP(elem.RemoteIP) {
+			continue
+		}
+		counts[elem.RemoteIP]++
+	}
+
+	hs := make([]RemoteHostCount, 0, len(counts))
+	for ip, cnt := range counts {
+		hs = append(hs, RemoteHostCount{RemoteIP: ip, Count: cnt})
+	}
+	sort.Slice(hs, func(i, j int) bool {
+		if hs[i].Count != hs[j].Count {
+			return hs[i].Count > hs[j].Count
+		}
+		return hs[i].RemoteIP < hs[j].RemoteIP
+	})
+	if n > 0 && len(hs) > n {
+		hs = hs[:n:n]
+	}
+	return hs
+}
+
+// ResolveRemoteHosts sets the Hostname of each entry with reverse DNS
+// lookup. Hosts that fail to resolve are left empty. Lookups time out
+// on the clock of 'WithClock'.
+func ResolveRemoteHosts(hs []RemoteHostCount, opts ...OpFunc) {
+	ResolveRemoteHostsContext(context.Background(), hs, opts...)
+}
+
+// ResolveRemoteHostsContext is 'ResolveRemoteHosts' with the lookups
+// aborted when the context is done.
+func ResolveRemoteHostsContext(ctx context.Context, hs []RemoteHostCount, opts ...OpFunc) {
+	op := &EntryOp{}
+	op.applyOpts(opts)
+
+	ips := make([]string, len(hs))
+	for i := range hs {
+		ips[i] = hs[i].RemoteIP
+	}
+	hosts := defaultResolver.resolveAll(ctx, ips, op.Clock)
+	for i := range hs {
+		hs[i].Hostname = hosts[hs[i].RemoteIP]
+	}
+}
+
+func isUnspecifiedIP(s string) bool {
+	ip := net.ParseIP(s)
+	return ip == nil || ip.IsUnspecified()
+}
@@ -0,0 +1,29 @@
+package inspect
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTopRemoteHosts(t *testing.T) {
+	nss := []SSEntry{
+		{State: "LISTEN", RemoteIP: "0.0.0.0"},
+		{State: "ESTABLISHED", RemoteIP: "10.0.0.2"},
+		{State: "ESTABLISHED", RemoteIP: "10.0.0.1"},
+		{State: "ESTABLISHED", RemoteIP: "10.0.0.2"},
+		{State: "TIME_WAIT", RemoteIP: "10.0.0.3"},
+		{State: "ESTABLISHED", RemoteIP: "::"},
+		{State: "ESTABLISHED", RemoteIP: "10.0.0.4"},
+	}
+	hs := TopRemoteHosts(nss, 2)
+	expected := []RemoteHostCount{
+		{RemoteIP: "10.0.0.2", Count: 2},
+		{RemoteIP: "10.0.0.1", Count: 1},
+	}
+	if !reflect.DeepEqual(hs, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, hs)
+	}
+	if hs = TopRemoteHosts(nss, 0); len(hs) != 3 {
+		t.Fatalf("expected 3 hosts, got %+v", hs)
+	}
+}
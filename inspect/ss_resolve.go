@@ -0,0 +1,164 @@
+package inspect
+
+import (
+	"container/list"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gyuho/linux-inspect/pkg/timeutil"
+)
+
+const (
+	// resolveTimeout is the reverse DNS lookup timeout per IP.
+	resolveTimeout = 500 * time.Millisecond
+	// resolveNegativeTTL is how long failed lookups are cached,
+	// so that unresolvable IPs are retried once DNS recovers.
+	resolveNegativeTTL = 30 * time.Second
+	// resolveCacheSize is the number of IPs cached by the default resolver.
+	resolveCacheSize = 4096
+	// maxConcurrentResolve limits the concurrent reverse DNS lookups.
+	maxConcurrentResolve = 16
+)
+
+// hostResolver is a reverse DNS resolver with an LRU cache.
+// Failed lookups are cached as empty hostnames for 'negativeTTL'.
+type hostResolver struct {
+	timeout     time.Duration
+	negativeTTL time.Duration
+	lookup      func(ctx context.Context, ip string) ([]string, error)
+
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type resolveEntry struct {
+	ip   string
+	host string
+	// expires is set for failed lookups.
+	expires time.Time
+}
+
+func newHostResolver(size int, timeout time.Duration) *hostResolver {
+	return &hostResolver{
+		timeout:     timeout,
+		negativeTTL: resolveNegativeTTL,
+		lookup:      net.DefaultResolver.LookupAddr,
+		size:        size,
+		ll:          list.New(),
+		items:       make(map[string]*list.Element),
+	}
+}
+
+var defaultResolver = newHostResolver(resolveCacheSize, resolveTimeout)
+
+// resolve returns the hostname of the IP without the trailing dot,
+// or an empty string if not resolvable. The lookup times out after
+// 'r.timeout' on the clock. Lookups aborted by 'ctx' are not cached.
+func (r *hostResolver) resolve(ctx context.Context, ip string, clock timeutil.Clock) string {
+	if isUnspecifiedIP(ip) {
+		return ""
+	}
+
+	r.mu.Lock()
+	if e, ok := r.items[ip]; ok {
+		ent := e.Value.(*resolveEntry)
+		if ent.expires.IsZero() || clock.Now().Before(ent.expires) {
+			r.ll.MoveToFront(e)
+			host := ent.host
+			r.mu.Unlock()
+			return host
+		}
+	}
+	r.mu.Unlock()
+
+	lctx, cancel := withClockTimeout(ctx, clock, r.timeout)
+	names, err := r.lookup(lctx, ip)
+	cancel()
+	if ctx.Err() != nil {
+		return ""
+	}
+	ent := &resolveEntry{ip: ip}
+	if err == nil && len(names) > 0 {
+		ent.host = strings.TrimSuffix(names[0], ".")
+	} else {
+		ent.expires = clock.Now().Add(r.negativeTTL)
+	}
+
+	r.mu.Lock()
+	if e, ok := r.items[ip]; ok {
+		r.ll.MoveToFront(e)
+		e.Value = ent
+	} else {
+		r.items[ip] = r.ll.PushFront(ent)
+		if r.ll.Len() > r.size {
+			last := r.ll.Back()
+			r.ll.Remove(last)
+			delete(r.items, last.Value.(*resolveEntry).ip)
+		}
+	}
+	r.mu.Unlock()
+	return ent.host
+}
+
+// withClockTimeout is 'context.WithTimeout' with the deadline
+// measured on the clock.
+func withClockTimeout(parent context.Context, clock timeutil.Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	timeout := clock.After(d)
+	go func() {
+		select {
+		case <-timeout:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// resolveAll resolves the unique IPs concurrently.
+func (r *hostResolver) resolveAll(ctx context.Context, ips []string, clock timeutil.Clock) map[string]string {
+	uniq := make(map[string]string)
+	for _, ip := range ips {
+		uniq[ip] = ""
+	}
+
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		sem = make(chan struct{}, maxConcurrentResolve)
+	)
+	for ip := range uniq {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(ip string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			host := r.resolve(ctx, ip, clock)
+			mu.Lock()
+			uniq[ip] = host
+			mu.Unlock()
+		}(ip)
+	}
+	wg.Wait()
+	return uniq
+}
+
+// resolveSS sets the LocalHost and RemoteHost of the entries.
+func resolveSS(ctx context.Context, r *hostResolver, sss []SSEntry, clock timeutil.Clock) {
+	ips := make([]string, 0, 2*len(sss))
+	for _, elem := range sss {
+		ips = append(ips, elem.LocalIP, elem.RemoteIP)
+	}
+	hosts := r.resolveAll(ctx, ips, clock)
+	for i := range sss {
+		sss[i].LocalHost = hosts[sss[i].LocalIP]
+		sss[i].RemoteHost = hosts[sss[i].RemoteIP]
+	}
+}
@@ -0,0 +1,121 @@
+package inspect
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gyuho/linux-inspect/pkg/timeutil"
+)
+
+func TestHostResolver(t *testing.T) {
+	var calls int32
+	clock := timeutil.NewFakeClock(time.Unix(0, 0))
+	r := newHostResolver(2, time.Second)
+	r.lookup = func(_ context.Context, ip string) ([]string, error) {
+		atomic.AddInt32(&calls, 1)
+		if ip == "10.0.0.3" {
+			return nil, errors.New("no such host")
+		}
+		return []string{"host-" + ip + "."}, nil
+	}
+
+	if h := r.resolve(context.Background(), "10.0.0.1", clock); h != "host-10.0.0.1" {
+		t.Fatalf("unexpected hostname %q", h)
+	}
+	if h := r.resolve(context.Background(), "10.0.0.1", clock); h != "host-10.0.0.1" {
+		t.Fatalf("unexpected hostname %q", h)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 lookup, got %d", calls)
+	}
+	if h := r.resolve(context.Background(), "0.0.0.0", clock); h != "" {
+		t.Fatalf("unexpected hostname %q", h)
+	}
+
+	// negative result is cached, and evicts the least recently used
+	r.resolve(context.Background(), "10.0.0.2", clock)
+	if h := r.resolve(context.Background(), "10.0.0.3", clock); h != "" {
+		t.Fatalf("unexpected hostname %q", h)
+	}
+	r.resolve(context.Background(), "10.0.0.3", clock)
+	if calls != 3 {
+		t.Fatalf("expected 3 lookups, got %d", calls)
+	}
+	if _, ok := r.items["10.0.0.1"]; ok {
+		t.Fatal("expected 10.0.0.1 evicted")
+	}
+
+	// negative result expires
+	clock.Advance(resolveNegativeTTL)
+	r.resolve(context.Background(), "10.0.0.3", clock)
+	if calls != 4 {
+		t.Fatalf("expected 4 lookups, got %d", calls)
+	}
+
+	sss := []SSEntry{{LocalIP: "10.0.0.2", RemoteIP: "10.0.0.4"}}
+	resolveSS(context.Background(), r, sss, clock)
+	if sss[0].LocalHost != "host-10.0.0.2" || sss[0].RemoteHost != "host-10.0.0.4" {
+		t.Fatalf("unexpected %+v", sss[0])
+	}
+
+	hd, rows := ConvertSS(sss...)
+	if txt := StringSS(hd, rows, -1); !strings.Contains(txt, "REMOTE-HOST") {
+		t.Fatalf("expected host columns in %s", txt)
+	}
+}
+
+func TestHostResolverTimeout(t *testing.T) {
+	clock := timeutil.NewFakeClock(time.Unix(0, 0))
+	r := newHostResolver(2, time.Second)
+	r.lookup = func(ctx context.Context, ip string) ([]string, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	donec := make(chan string)
+	go func() { donec <- r.resolve(context.Background(), "10.0.0.1", clock) }()
+	for clock.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case h := <-donec:
+		t.Fatalf("resolved %q before timeout", h)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case h := <-donec:
+		if h != "" {
+			t.Fatalf("unexpected hostname %q", h)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("lookup did not time out")
+	}
+}
+
+func TestHostResolverCanceled(t *testing.T) {
+	var calls int32
+	r := newHostResolver(2, time.Second)
+	r.lookup = func(ctx context.Context, ip string) ([]string, error) {
+		atomic.AddInt32(&calls, 1)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if h := r.resolve(ctx, "10.0.0.1", timeutil.RealClock); h != "" {
+		t.Fatalf("unexpected hostname %q", h)
+	}
+	if len(r.items) != 0 {
+		t.Fatalf("expected canceled lookup not cached, got %d entries", len(r.items))
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 lookup, got %d", calls)
+	}
+}
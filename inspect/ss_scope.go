@@ -0,0 +1,83 @@
+package inspect
+
+import (
+	"fmt"
+	"net"
+)
+
+// ssScopes resolves the interface (the IPv6 zone) of link-local
+// sockets in this network namespace. '/proc/net/tcp6' does not report
+// the scope ID, so without netlink the interface is found by the local
+// address, which is ambiguous if several interfaces share it.
+type ssScopes struct {
+	names map[int]string
+
+	// addrs maps the link-local addresses to their interfaces,
+	// loaded on first use.
+	addrs map[string][]string
+}
+
+func newSSScopes() *ssScopes {
+	return &ssScopes{names: make(map[int]string)}
+}
+
+// resolve returns the interface of the socket, or an empty string if
+// neither address is link-local or the interface is not known.
+func (s *ssScopes) resolve(localIP, remoteIP string, ifindex int) string {
+	local := net.ParseIP(localIP)
+	if !isLinkLocal(local) && !isLinkLocal(net.ParseIP(remoteIP)) {
+		return ""
+	}
+	if ifindex > 0 {
+		return interfaceName(s.names, ifindex)
+	}
+	if !isLinkLocal(local) {
+		return ""
+	}
+	if s.addrs == nil {
+		s.addrs = linkLocalAddrs()
+	}
+	if names := s.addrs[local.String()]; len(names) == 1 {
+		return names[0]
+	}
+	return ""
+}
+
+func isLinkLocal(ip net.IP) bool {
+	return ip != nil && ip.To4() == nil && ip.IsLinkLocalUnicast()
+}
+
+// linkLocalAddrs maps the link-local IPv6 addresses of the interfaces
+// to the interface names.
+func linkLocalAddrs() map[string][]string {
+	addrs := make(map[string][]string)
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return addrs
+	}
+	for _, iface := range ifaces {
+		ifas, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, ifa := range ifas {
+			ipn, ok := ifa.(*net.IPNet)
+			if !ok || !isLinkLocal(ipn.IP) {
+				continue
+			}
+			k := ipn.IP.String()
+			addrs[k] = append(addrs[k], iface.Name)
+		}
+	}
+	return addrs
+}
+
+// scopedIP appends the zone to a link-local address
+// (e.g. "FE80:0000:...:0001%eth0"), so that the same address
+// on different links is not ambiguous.
+func scopedIP(ip, iface string) string {
+	if iface == "" || !isLinkLocal(net.ParseIP(ip)) {
+		return ip
+	}
+	return fmt.Sprintf("%s%%%s", ip, iface)
+}
@@ -0,0 +1,53 @@
+package inspect
+
+import (
+	"net"
+	"testing"
+)
+
+func TestSSScopes(t *testing.T) {
+	lo, err := net.InterfaceByName("lo")
+	if err != nil {
+		t.Skip(err)
+	}
+	s := newSSScopes()
+	s.addrs = map[string][]string{
+		"fe80::1": {"eth0"},
+		"fe80::2": {"veth0", "veth1"},
+	}
+
+	tests := []struct {
+		local, remote string
+		ifindex       int
+		iface         string
+	}{
+		{"127.0.0.1", "127.0.0.1", 0, ""},
+		{"0000:0000:0000:0000:0000:0000:0000:0001", "0000:0000:0000:0000:0000:0000:0000:0000", lo.Index, ""},
+		{"FE80:0000:0000:0000:0000:0000:0000:0001", "0000:0000:0000:0000:0000:0000:0000:0000", 0, "eth0"},
+		{"FE80:0000:0000:0000:0000:0000:0000:0001", "FE80:0000:0000:0000:0000:0000:0000:0009", lo.Index, lo.Name},
+		{"FE80:0000:0000:0000:0000:0000:0000:0002", "0000:0000:0000:0000:0000:0000:0000:0000", 0, ""},
+		{"FE80:0000:0000:0000:0000:0000:0000:0003", "0000:0000:0000:0000:0000:0000:0000:0000", 0, ""},
+	}
+	for i, tt := range tests {
+		if iface := s.resolve(tt.local, tt.remote, tt.ifindex); iface != tt.iface {
+			t.Fatalf("#%d: interface expected %q, got %q", i, tt.iface, iface)
+		}
+	}
+}
+
+func TestConvertSSScopedIP(t *testing.T) {
+	_, rows := ConvertSS(SSEntry{
+		Protocol:   "tcp6",
+		LocalIP:    "FE80:0000:0000:0000:0000:0000:0000:0001",
+		RemoteIP:   "0000:0000:0000:0000:0000:0000:0000:0000",
+		Interface:  "eth0",
+		LocalPort:  22,
+		RemotePort: 0,
+	})
+	if rows[0][4] != "FE80:0000:0000:0000:0000:0000:0000:0001%eth0" {
+		t.Fatalf("unexpected local IP %q", rows[0][4])
+	}
+	if rows[0][6] != "0000:0000:0000:0000:0000:0000:0000:0000" {
+		t.Fatalf("unexpected remote IP %q", rows[0][6])
+	}
+}
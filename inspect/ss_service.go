@@ -0,0 +1,109 @@
+package inspect
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gyuho/linux-inspect/etc"
+)
+
+// serviceKey is a port of a transport protocol ("tcp", "sctp").
+type serviceKey struct {
+	protocol string
+	port     int64
+}
+
+// defaultServices is used if '/etc/services' is not readable
+// (e.g. minimal containers).
+var defaultServices = map[serviceKey]string{
+	{"tcp", 21}:    "ftp",
+	{"tcp", 22}:    "ssh",
+	{"tcp", 23}:    "telnet",
+	{"tcp", 25}:    "smtp",
+	{"tcp", 53}:    "domain",
+	{"tcp", 80}:    "http",
+	{"tcp", 110}:   "pop3",
+	{"tcp", 143}:   "imap2",
+	{"tcp", 389}:   "ldap",
+	{"tcp", 443}:   "https",
+	{"tcp", 465}:   "submissions",
+	{"tcp", 587}:   "submission",
+	{"tcp", 636}:   "ldaps",
+	{"tcp", 993}:   "imaps",
+	{"tcp", 995}:   "pop3s",
+	{"tcp", 2379}:  "etcd-client",
+	{"tcp", 2380}:  "etcd-server",
+	{"tcp", 3306}:  "mysql",
+	{"tcp", 5432}:  "postgresql",
+	{"tcp", 6379}:  "redis",
+	{"tcp", 11211}: "memcache",
+}
+
+var (
+	servicesOnce sync.Once
+	services     map[serviceKey]string
+)
+
+// loadServices reads '/etc/services' once.
+func loadServices() map[serviceKey]string {
+	servicesOnce.Do(func() {
+		ss, err := etc.GetServices()
+		if err != nil {
+			services = defaultServices
+			return
+		}
+		services = make(map[serviceKey]string, len(ss))
+		for _, s := range ss {
+			k := serviceKey{s.Protocol, s.Port}
+			if _, ok := services[k]; !ok {
+				services[k] = s.Name
+			}
+		}
+	})
+	return services
+}
+
+// serviceProtocol returns the '/etc/services' protocol of the
+// socket, or an empty string if its ports are not service ports
+// (e.g. raw sockets).
+func serviceProtocol(protocol string) string {
+	switch protocol {
+	case "tcp", "tcp6":
+		return "tcp"
+	case "sctp":
+		return "sctp"
+	default:
+		return ""
+	}
+}
+
+// resolveServices sets LocalService and RemoteService. overrides
+// take precedence over '/etc/services', for any protocol.
+func resolveServices(table map[serviceKey]string, overrides map[int64]string, sss []SSEntry) {
+	lookup := func(protocol string, port int64) string {
+		if port == 0 {
+			return ""
+		}
+		if name, ok := overrides[port]; ok {
+			return name
+		}
+		return table[serviceKey{protocol, port}]
+	}
+	for i := range sss {
+		protocol := serviceProtocol(sss[i].Protocol)
+		if protocol == "" {
+			continue
+		}
+		sss[i].LocalService = lookup(protocol, sss[i].LocalPort)
+		sss[i].RemoteService = lookup(protocol, sss[i].RemotePort)
+	}
+}
+
+// servicePort returns the service name, or the port number
+// if it has none.
+func servicePort(port int64, service string) string {
+	if service != "" {
+		return service
+	}
+	return fmt.Sprintf("%d", port)
+}
@@ -0,0 +1,59 @@
+package inspect
+
+import (
+	"net"
+	"os"
+	"testing"
+)
+
+func TestResolveServices(t *testing.T) {
+	table := map[serviceKey]string{
+		{"tcp", 22}:  "ssh",
+		{"tcp", 443}: "https",
+	}
+	sss := []SSEntry{
+		{Protocol: "tcp", LocalPort: 22, RemotePort: 0},
+		{Protocol: "tcp6", LocalPort: 50000, RemotePort: 443},
+		{Protocol: "tcp", LocalPort: 8080, RemotePort: 0},
+		{Protocol: "raw", LocalPort: 22},
+	}
+	resolveServices(table, map[int64]string{8080: "api"}, sss)
+
+	if sss[0].LocalService != "ssh" || sss[0].RemoteService != "" {
+		t.Fatalf("unexpected %+v", sss[0])
+	}
+	if sss[1].LocalService != "" || sss[1].RemoteService != "https" {
+		t.Fatalf("unexpected %+v", sss[1])
+	}
+	if sss[2].LocalService != "api" {
+		t.Fatalf("unexpected %+v", sss[2])
+	}
+	if sss[3].LocalService != "" {
+		t.Fatalf("raw sockets have no services, got %+v", sss[3])
+	}
+
+}
+
+func TestGetSSWithServiceNames(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	port := int64(ln.Addr().(*net.TCPAddr).Port)
+
+	sss, err := GetSS(WithPID(int64(os.Getpid())), WithTCP(), WithLocalPort(port), WithServiceNames(map[int64]string{port: "test-svc"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sss) != 1 {
+		t.Fatalf("expected 1 entry, got %+v", sss)
+	}
+	if sss[0].LocalService != "test-svc" {
+		t.Fatalf("local service expected %q, got %q", "test-svc", sss[0].LocalService)
+	}
+	_, rows := ConvertSS(sss...)
+	if rows[0][5] != "test-svc" {
+		t.Fatalf("local port column expected %q, got %q", "test-svc", rows[0][5])
+	}
+}
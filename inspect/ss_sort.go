@@ -0,0 +1,178 @@
+package inspect
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// SortOption sorts the 'ConvertSSWith' rows by a column.
+type SortOption struct {
+	// Column is the header name (e.g. "PID", "LOCAL-PORT").
+	Column     string
+	Descending bool
+}
+
+// SortAscending sorts by the column in ascending order.
+func SortAscending(column string) SortOption {
+	return SortOption{Column: column}
+}
+
+// SortDescending sorts by the column in descending order.
+func SortDescending(column string) SortOption {
+	return SortOption{Column: column, Descending: true}
+}
+
+// defaultSSSorts is the order of 'ConvertSS', and of 'GetSS'
+// before 'WithTopLimit' (see 'defaultSSLess').
+var defaultSSSorts = []SortOption{
+	SortAscending("PROGRAM"),
+	SortAscending("STATE"),
+	SortAscending("PROTOCOL"),
+	SortAscending("PID"),
+	SortAscending("LOCAL-IP"),
+	SortAscending("LOCAL-PORT"),
+	SortAscending("REMOTE-IP"),
+	SortAscending("REMOTE-PORT"),
+}
+
+var defaultSSCompares = ssComparesOf(defaultSSSorts)
+
+// defaultSSLess orders entries as 'ConvertSS' does, so that the
+// top entries of 'GetSS' are the first rows of 'ConvertSS'.
+func defaultSSLess(a, b SSEntry) bool {
+	return lessSS(a, b, defaultSSSorts, defaultSSCompares)
+}
+
+// ssCompare returns a negative number if a sorts before b,
+// a positive number if after, and zero if equal.
+type ssCompare func(a, b SSEntry) int
+
+var ssCompares = map[string]ssCompare{
+	"PROTOCOL":    func(a, b SSEntry) int { return strings.Compare(a.Protocol, b.Protocol) },
+	"PROGRAM":     func(a, b SSEntry) int { return strings.Compare(a.Program, b.Program) },
+	"STATE":       func(a, b SSEntry) int { return strings.Compare(a.State, b.State) },
+	"PID":         func(a, b SSEntry) int { return compareInt64(a.PID, b.PID) },
+	"LOCAL-IP":    func(a, b SSEntry) int { return compareIP(a.LocalIP, b.LocalIP) },
+	"LOCAL-PORT":  func(a, b SSEntry) int { return compareInt64(a.LocalPort, b.LocalPort) },
+	"REMOTE-IP":   func(a, b SSEntry) int { return compareIP(a.RemoteIP, b.RemoteIP) },
+	"REMOTE-PORT": func(a, b SSEntry) int { return compareInt64(a.RemotePort, b.RemotePort) },
+	"USER":        func(a, b SSEntry) int { return strings.Compare(a.User.Username, b.User.Username) },
+	"LOCAL-HOST":  func(a, b SSEntry) int { return strings.Compare(a.LocalHost, b.LocalHost) },
+	"REMOTE-HOST": func(a, b SSEntry) int { return strings.Compare(a.RemoteHost, b.RemoteHost) },
+	"RTT-MS": func(a, b SSEntry) int {
+		return compareTCPInfo(a, b, func(e SSEntry) int64 { return int64(e.TCPInfo.RTT) })
+	},
+	"CWND": func(a, b SSEntry) int {
+		return compareTCPInfo(a, b, func(e SSEntry) int64 { return int64(e.TCPInfo.SndCwnd) })
+	},
+	"RETRANS": func(a, b SSEntry) int {
+		return compareTCPInfo(a, b, func(e SSEntry) int64 { return int64(e.TCPInfo.TotalRetrans) })
+	},
+	"NETNS":  func(a, b SSEntry) int { return strings.Compare(a.NetNS, b.NetNS) },
+	"SEND-Q": func(a, b SSEntry) int { return compareUint64(a.TxQueueBytes, b.TxQueueBytes) },
+	"RECV-Q": func(a, b SSEntry) int { return compareUint64(a.RxQueueBytes, b.RxQueueBytes) },
+	"INODE":  func(a, b SSEntry) int { return compareUint64(a.Inode, b.Inode) },
+	"FD":     func(a, b SSEntry) int { return compareInt64(a.FD, b.FD) },
+}
+
+// ssComparesOf returns the compare functions of the sort columns.
+// It panics on unknown columns.
+func ssComparesOf(sorts []SortOption) []ssCompare {
+	cmps := make([]ssCompare, len(sorts))
+	for i, so := range sorts {
+		cmp, ok := ssCompares[so.Column]
+		if !ok {
+			panic(fmt.Errorf("unknown column %q (expected one of %q)", so.Column, columnsSSEntry))
+		}
+		cmps[i] = cmp
+	}
+	return cmps
+}
+
+// lessSS returns true if a sorts before b by the first
+// column that differs.
+func lessSS(a, b SSEntry, sorts []SortOption, cmps []ssCompare) bool {
+	for k, cmp := range cmps {
+		c := cmp(a, b)
+		if c == 0 {
+			continue
+		}
+		if sorts[k].Descending {
+			return c > 0
+		}
+		return c < 0
+	}
+	return false
+}
+
+// sortSS returns a sorted copy of the entries. It panics
+// on unknown columns.
+func sortSS(nss []SSEntry, sorts []SortOption) []SSEntry {
+	cmps := ssComparesOf(sorts)
+	sorted := make([]SSEntry, len(nss))
+	copy(sorted, nss)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return lessSS(sorted[i], sorted[j], sorts, cmps)
+	})
+	return sorted
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareUint64(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareIP compares the IPs by address, with IPv4 before IPv6.
+// Unparsable IPs are compared as strings, after the others.
+func compareIP(a, b string) int {
+	ipa, ipb := net.ParseIP(a), net.ParseIP(b)
+	switch {
+	case ipa == nil && ipb == nil:
+		return strings.Compare(a, b)
+	case ipa == nil:
+		return 1
+	case ipb == nil:
+		return -1
+	}
+	if a4, b4 := ipa.To4() != nil, ipb.To4() != nil; a4 != b4 {
+		if a4 {
+			return -1
+		}
+		return 1
+	}
+	return bytes.Compare(ipa.To16(), ipb.To16())
+}
+
+// compareTCPInfo compares the TCP info values, with
+// the entries without TCP info first.
+func compareTCPInfo(a, b SSEntry, v func(SSEntry) int64) int {
+	switch {
+	case a.TCPInfo == nil && b.TCPInfo == nil:
+		return 0
+	case a.TCPInfo == nil:
+		return -1
+	case b.TCPInfo == nil:
+		return 1
+	}
+	return compareInt64(v(a), v(b))
+}
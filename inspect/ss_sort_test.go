@@ -0,0 +1,84 @@
+package inspect
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/gyuho/linux-inspect/proc"
+)
+
+func TestConvertSSWith(t *testing.T) {
+	nss := []SSEntry{
+		{Program: "a", PID: 10, LocalIP: "10.0.0.10", LocalPort: 9},
+		{Program: "a", PID: 9, LocalIP: "10.0.0.9", LocalPort: 10},
+		{Program: "b", PID: 100, LocalIP: "::1", LocalPort: 443, TCPInfo: &proc.TCPInfo{RTT: 2000}},
+		{Program: "b", PID: 2, LocalIP: "127.0.0.1", LocalPort: 80, TCPInfo: &proc.TCPInfo{RTT: 1000}},
+	}
+	pids := func(rows [][]string) []string {
+		vs := make([]string, len(rows))
+		for i, row := range rows {
+			vs[i] = row[3]
+		}
+		return vs
+	}
+
+	tests := []struct {
+		sorts []SortOption
+		exp   []string
+	}{
+		{[]SortOption{SortAscending("PID")}, []string{"2", "9", "10", "100"}},
+		{[]SortOption{SortDescending("PID")}, []string{"100", "10", "9", "2"}},
+		{[]SortOption{SortAscending("LOCAL-PORT")}, []string{"10", "9", "2", "100"}},
+		{[]SortOption{SortDescending("PROGRAM"), SortAscending("PID")}, []string{"2", "100", "9", "10"}},
+		{[]SortOption{SortAscending("LOCAL-IP")}, []string{"9", "10", "2", "100"}},
+		{[]SortOption{SortDescending("RTT-MS")}, []string{"100", "2", "10", "9"}},
+		{nil, []string{"10", "9", "100", "2"}},
+	}
+	for i, tt := range tests {
+		_, rows := ConvertSSWith(nss, tt.sorts...)
+		if got := pids(rows); !reflect.DeepEqual(got, tt.exp) {
+			t.Fatalf("#%d: expected %v, got %v", i, tt.exp, got)
+		}
+	}
+
+	// ConvertSS sorts PIDs numerically
+	_, rows := ConvertSS(nss...)
+	if got := pids(rows); !reflect.DeepEqual(got, []string{"9", "10", "2", "100"}) {
+		t.Fatalf("unexpected default order %v", got)
+	}
+	if nss[0].PID != 10 {
+		t.Fatal("entries must not be reordered in place")
+	}
+}
+
+func TestConvertSSWithUnknownColumn(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic on unknown column")
+		}
+	}()
+	ConvertSSWith(nil, SortAscending("FOO"))
+}
+
+func TestDefaultSSLessMatchesConvertSS(t *testing.T) {
+	sss := []SSEntry{
+		{Protocol: "tcp", Program: "nginx", State: "LISTEN", PID: 1, LocalIP: "9.0.0.1", LocalPort: 80},
+		{Protocol: "tcp", Program: "nginx", State: "LISTEN", PID: 1, LocalIP: "10.0.0.1", LocalPort: 443},
+		{Protocol: "tcp", Program: "nginx", State: "LISTEN", PID: 1, LocalIP: "10.0.0.1", LocalPort: 80},
+		{Protocol: "tcp", Program: "etcd", State: "LISTEN", PID: 2, LocalIP: "10.0.0.1", LocalPort: 2379},
+	}
+	sorted := append([]SSEntry(nil), sss...)
+	sort.SliceStable(sorted, func(i, j int) bool { return defaultSSLess(sorted[i], sorted[j]) })
+
+	_, rows := ConvertSS(sss...)
+	for i, ent := range sorted {
+		if exp := fmt.Sprintf("%d", ent.LocalPort); rows[i][1] != ent.Program || rows[i][4] != ent.LocalIP || rows[i][5] != exp {
+			t.Fatalf("#%d: GetSS order %+v, ConvertSS row %q", i, ent, rows[i])
+		}
+	}
+	if sorted[1].LocalIP != "9.0.0.1" {
+		t.Fatalf("expected 9.0.0.1 before 10.0.0.1, got %+v", sorted)
+	}
+}
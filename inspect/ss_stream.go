@@ -0,0 +1,227 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SSEventType is the type of socket change.
+type SSEventType int
+
+const (
+	// ConnectionOpened is a socket not in the previous scan.
+	ConnectionOpened SSEventType = iota
+	// ConnectionClosed is a socket gone since the previous scan.
+	ConnectionClosed
+	// StateChanged is a socket whose TCP state changed.
+	StateChanged
+)
+
+func (tp SSEventType) String() string {
+	switch tp {
+	case ConnectionOpened:
+		return "opened"
+	case ConnectionClosed:
+		return "closed"
+	case StateChanged:
+		return "state-changed"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(tp))
+	}
+}
+
+// SSEvent is a socket change between two scans.
+type SSEvent struct {
+	Type SSEventType
+	// Entry is the current entry, or the last seen entry if closed.
+	Entry SSEntry
+	// PrevState is the previous state, only set for StateChanged.
+	PrevState string
+	Time      time.Time
+}
+
+// ssKey identifies a socket across scans.
+type ssKey struct {
+	protocol   string
+	pid        int64
+	localIP    string
+	localPort  int64
+	remoteIP   string
+	remotePort int64
+}
+
+func keyOf(ent SSEntry) ssKey {
+	return ssKey{
+		protocol:   ent.Protocol,
+		pid:        ent.PID,
+		localIP:    ent.LocalIP,
+		localPort:  ent.LocalPort,
+		remoteIP:   ent.RemoteIP,
+		remotePort: ent.RemotePort,
+	}
+}
+
+// SSStream periodically re-scans sockets with 'GetSS',
+// and emits the changes between scans.
+type SSStream struct {
+	opts     []OpFunc
+	interval time.Duration
+	op       *EntryOp
+
+	ctx    context.Context
+	cancel func()
+	donec  chan struct{}
+
+	eventc chan SSEvent
+	errc   chan error
+
+	mu   sync.RWMutex
+	prev map[ssKey]SSEntry
+}
+
+// StartSSStream scans the sockets matching the options as the baseline,
+// and re-scans every interval. The baseline emits no event.
+// The clock is set by 'WithClock'.
+func StartSSStream(interval time.Duration, opts ...OpFunc) (*SSStream, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("not-valid interval %v", interval)
+	}
+	op := &EntryOp{}
+	op.applyOpts(opts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	str := &SSStream{
+		opts:     opts,
+		interval: interval,
+		op:       op,
+		ctx:      ctx,
+		cancel:   cancel,
+		donec:    make(chan struct{}),
+		eventc:   make(chan SSEvent, 100),
+		errc:     make(chan error, 1),
+	}
+
+	sss, err := GetSSContext(ctx, opts...)
+	if err != nil && !isPartialError(err) {
+		cancel()
+		return nil, err
+	}
+	str.prev = indexSS(sss)
+
+	go str.run()
+	return str, nil
+}
+
+// Events returns the channel of socket changes. It is closed
+// once the stream stops. Scans block until events are received.
+func (str *SSStream) Events() <-chan SSEvent {
+	return str.eventc
+}
+
+// ErrChan returns the scan errors. Scans continue on error;
+// errors are dropped if not received. On '*PartialError', the
+// scan results are still used.
+func (str *SSStream) ErrChan() <-chan error {
+	return str.errc
+}
+
+// Latest returns the entries of the last scan.
+func (str *SSStream) Latest() []SSEntry {
+	str.mu.RLock()
+	sss := make([]SSEntry, 0, len(str.prev))
+	for _, ent := range str.prev {
+		sss = append(sss, ent)
+	}
+	str.mu.RUnlock()
+	sort.SliceStable(sss, func(i, j int) bool { return defaultSSLess(sss[i], sss[j]) })
+	return sss
+}
+
+// Stop stops scanning, and waits for the stream to close.
+func (str *SSStream) Stop() {
+	str.cancel()
+	<-str.donec
+}
+
+func (str *SSStream) run() {
+	defer func() {
+		close(str.eventc)
+		close(str.donec)
+	}()
+	for {
+		select {
+		case <-str.ctx.Done():
+			return
+		case <-str.op.Clock.After(str.interval):
+		}
+
+		sss, err := GetSSContext(str.ctx, str.opts...)
+		if err != nil {
+			if str.ctx.Err() != nil {
+				return
+			}
+			select {
+			case str.errc <- err:
+			default:
+			}
+			if !isPartialError(err) {
+				continue
+			}
+		}
+
+		cur := indexSS(sss)
+		str.mu.RLock()
+		evs := diffSS(str.prev, cur, str.op.Clock.Now())
+		str.mu.RUnlock()
+
+		str.mu.Lock()
+		str.prev = cur
+		str.mu.Unlock()
+
+		for _, ev := range evs {
+			select {
+			case str.eventc <- ev:
+			case <-str.ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func indexSS(sss []SSEntry) map[ssKey]SSEntry {
+	m := make(map[ssKey]SSEntry, len(sss))
+	for _, ent := range sss {
+		m[keyOf(ent)] = ent
+	}
+	return m
+}
+
+// diffSS returns the changes from prev to cur, closed first,
+// then in 'ConvertSS' order.
+func diffSS(prev, cur map[ssKey]SSEntry, now time.Time) []SSEvent {
+	var evs []SSEvent
+	for k, ent := range prev {
+		if _, ok := cur[k]; !ok {
+			evs = append(evs, SSEvent{Type: ConnectionClosed, Entry: ent, Time: now})
+		}
+	}
+	for k, ent := range cur {
+		old, ok := prev[k]
+		switch {
+		case !ok:
+			evs = append(evs, SSEvent{Type: ConnectionOpened, Entry: ent, Time: now})
+		case old.State != ent.State:
+			evs = append(evs, SSEvent{Type: StateChanged, Entry: ent, PrevState: old.State, Time: now})
+		}
+	}
+	sort.SliceStable(evs, func(i, j int) bool {
+		if (evs[i].Type == ConnectionClosed) != (evs[j].Type == ConnectionClosed) {
+			return evs[i].Type == ConnectionClosed
+		}
+		return defaultSSLess(evs[i].Entry, evs[j].Entry)
+	})
+	return evs
+}
@@ -0,0 +1,94 @@
+package inspect
+
+import (
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gyuho/linux-inspect/pkg/timeutil"
+)
+
+func TestDiffSS(t *testing.T) {
+	a := SSEntry{Protocol: "tcp", PID: 1, LocalIP: "127.0.0.1", LocalPort: 80, State: "LISTEN"}
+	b := SSEntry{Protocol: "tcp", PID: 1, LocalIP: "127.0.0.1", LocalPort: 80, RemoteIP: "127.0.0.1", RemotePort: 5000, State: "ESTABLISHED"}
+	c := SSEntry{Protocol: "tcp", PID: 2, LocalIP: "127.0.0.1", LocalPort: 5000, RemoteIP: "127.0.0.1", RemotePort: 80, State: "ESTABLISHED"}
+
+	b2 := b
+	b2.State = "CLOSE_WAIT"
+
+	now := time.Unix(1, 0)
+	evs := diffSS(indexSS([]SSEntry{a, b, c}), indexSS([]SSEntry{a, b2}), now)
+	if len(evs) != 2 {
+		t.Fatalf("expected 2 events, got %+v", evs)
+	}
+	if evs[0].Type != ConnectionClosed || evs[0].Entry.PID != 2 {
+		t.Fatalf("unexpected %+v", evs[0])
+	}
+	if evs[1].Type != StateChanged || evs[1].PrevState != "ESTABLISHED" || evs[1].Entry.State != "CLOSE_WAIT" {
+		t.Fatalf("unexpected %+v", evs[1])
+	}
+
+	evs = diffSS(indexSS(nil), indexSS([]SSEntry{a}), now)
+	if len(evs) != 1 || evs[0].Type != ConnectionOpened || !evs[0].Time.Equal(now) {
+		t.Fatalf("unexpected %+v", evs)
+	}
+}
+
+func TestSSStream(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	port := int64(ln.Addr().(*net.TCPAddr).Port)
+
+	clock := timeutil.NewFakeClock(time.Unix(0, 0))
+	str, err := StartSSStream(time.Second, WithPID(int64(os.Getpid())), WithTCP(), WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer str.Stop()
+
+	conn, err := net.Dial("tcp4", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	cport := int64(conn.LocalAddr().(*net.TCPAddr).Port)
+	// not-accepted sockets have no inode, thus no owner
+	aconn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer aconn.Close()
+
+	for clock.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(time.Second)
+
+	// accepted and dialed sides of the connection
+	opened := make(map[int64]bool)
+	timeout := time.After(10 * time.Second)
+	for len(opened) < 2 {
+		select {
+		case ev := <-str.Events():
+			if ev.Type == ConnectionOpened && (ev.Entry.LocalPort == port || ev.Entry.LocalPort == cport) {
+				opened[ev.Entry.LocalPort] = true
+			}
+		case err := <-str.ErrChan():
+			t.Fatal(err)
+		case <-timeout:
+			t.Fatalf("timed out, got %v", opened)
+		}
+	}
+	if ss := str.Latest(); len(ss) < 3 {
+		t.Fatalf("expected at least 3 entries, got %+v", ss)
+	}
+
+	str.Stop()
+	if _, ok := <-str.Events(); ok {
+		t.Fatal("expected closed events channel")
+	}
+}
@@ -0,0 +1,105 @@
+package inspect
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SSCount is the number of entries in a group.
+type SSCount struct {
+	Key   string
+	Count int
+}
+
+// SSSummary is the socket counts by group, like 'ss -s'
+// with the top talkers. Each group is sorted in descending
+// order of count, then by key.
+type SSSummary struct {
+	Total int
+
+	ByState   []SSCount
+	ByProgram []SSCount
+	// ByRemoteIP only counts connected sockets
+	// (remote '0.0.0.0' or '::' excluded).
+	ByRemoteIP  []SSCount
+	ByLocalPort []SSCount
+}
+
+// SummarizeSS groups the entries by state, program, remote IP and local port.
+func SummarizeSS(sss []SSEntry) SSSummary {
+	states := make(map[string]int)
+	programs := make(map[string]int)
+	remotes := make(map[string]int)
+	ports := make(map[string]int)
+	for _, elem := range sss {
+		states[elem.State]++
+		programs[elem.Program]++
+		if !isUnspecifiedIP(elem.RemoteIP) {
+			remotes[elem.RemoteIP]++
+		}
+		ports[fmt.Sprintf("%d", elem.LocalPort)]++
+	}
+	return SSSummary{
+		Total:       len(sss),
+		ByState:     sortCounts(states),
+		ByProgram:   sortCounts(programs),
+		ByRemoteIP:  sortCounts(remotes),
+		ByLocalPort: sortCounts(ports),
+	}
+}
+
+func sortCounts(m map[string]int) []SSCount {
+	cs := make([]SSCount, 0, len(m))
+	for k, v := range m {
+		cs = append(cs, SSCount{Key: k, Count: v})
+	}
+	sort.Slice(cs, func(i, j int) bool {
+		if cs[i].Count != cs[j].Count {
+			return cs[i].Count > cs[j].Count
+		}
+		return cs[i].Key < cs[j].Key
+	})
+	return cs
+}
+
+var columnsSSSummary = []string{
+	"GROUP",
+	"KEY",
+	"COUNT",
+}
+
+// ConvertSSSummary converts to rows, grouped in the order
+// of total, state, program, remote IP and local port.
+func ConvertSSSummary(sum SSSummary) (header []string, rows [][]string) {
+	header = columnsSSSummary
+	rows = append(rows, []string{"TOTAL", "", fmt.Sprintf("%d", sum.Total)})
+	for _, g := range []struct {
+		name string
+		cs   []SSCount
+	}{
+		{"STATE", sum.ByState},
+		{"PROGRAM", sum.ByProgram},
+		{"REMOTE-IP", sum.ByRemoteIP},
+		{"LOCAL-PORT", sum.ByLocalPort},
+	} {
+		for _, c := range g.cs {
+			rows = append(rows, []string{g.name, sanitizeUTF8(c.Key), fmt.Sprintf("%d", c.Count)})
+		}
+	}
+	return
+}
+
+// StringSSSummary converts in print-friendly format.
+// topLimit limits the number of rows per group.
+func StringSSSummary(header []string, rows [][]string, topLimit int, opts ...ColumnOption) string {
+	n := make(map[string]int)
+	var shown [][]string
+	for _, row := range rows {
+		n[row[0]]++
+		if topLimit > 0 && n[row[0]] > topLimit {
+			continue
+		}
+		shown = append(shown, row)
+	}
+	return renderTable(header, shown, 0, firstColumns(len(header)), opts)
+}
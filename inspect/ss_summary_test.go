@@ -0,0 +1,39 @@
+package inspect
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSummarizeSS(t *testing.T) {
+	sss := []SSEntry{
+		{Program: "nginx", State: "LISTEN", LocalIP: "0.0.0.0", LocalPort: 80, RemoteIP: "0.0.0.0"},
+		{Program: "nginx", State: "ESTABLISHED", LocalIP: "10.0.0.1", LocalPort: 80, RemoteIP: "10.0.0.9", RemotePort: 5000},
+		{Program: "nginx", State: "ESTABLISHED", LocalIP: "10.0.0.1", LocalPort: 80, RemoteIP: "10.0.0.9", RemotePort: 5001},
+		{Program: "sshd", State: "ESTABLISHED", LocalIP: "10.0.0.1", LocalPort: 22, RemoteIP: "10.0.0.8", RemotePort: 6000},
+	}
+	sum := SummarizeSS(sss)
+	if sum.Total != 4 {
+		t.Fatalf("expected total 4, got %d", sum.Total)
+	}
+	if sum.ByState[0] != (SSCount{Key: "ESTABLISHED", Count: 3}) {
+		t.Fatalf("unexpected %+v", sum.ByState)
+	}
+	if sum.ByProgram[0] != (SSCount{Key: "nginx", Count: 3}) {
+		t.Fatalf("unexpected %+v", sum.ByProgram)
+	}
+	if len(sum.ByRemoteIP) != 2 || sum.ByRemoteIP[0] != (SSCount{Key: "10.0.0.9", Count: 2}) {
+		t.Fatalf("unexpected %+v", sum.ByRemoteIP)
+	}
+	if sum.ByLocalPort[0] != (SSCount{Key: "80", Count: 3}) {
+		t.Fatalf("unexpected %+v", sum.ByLocalPort)
+	}
+
+	hd, rows := ConvertSSSummary(sum)
+	txt := StringSSSummary(hd, rows, 1)
+	if strings.Contains(txt, "sshd") {
+		t.Fatalf("expected one row per group, got %s", txt)
+	}
+	fmt.Println(txt)
+}
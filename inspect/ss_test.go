@@ -2,25 +2,572 @@ package inspect
 
 import (
 	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"strings"
+	"syscall"
 	"testing"
+
+	"github.com/gyuho/linux-inspect/proc"
 )
 
 func TestGetSS(t *testing.T) {
 	ss, err := GetSS(WithTCP(), WithTopLimit(2))
+	checkPartialError(t, err)
+	hd, rows := ConvertSS(ss...)
+	txt := StringSS(hd, rows, -1)
+	fmt.Println(txt)
+}
+
+func TestGetSSWithFilter(t *testing.T) {
+	ss, err := GetSS(WithPID(1))
+	checkPartialError(t, err)
+	hd, rows := ConvertSS(ss...)
+	txt := StringSS(hd, rows, -1)
+	fmt.Println(txt)
+}
+
+func TestGetSSExcludeSelf(t *testing.T) {
+	pid := int64(os.Getpid())
+
+	ss, err := GetSS(WithPID(pid), WithExcludeSelf())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, elem := range ss {
+		if elem.PID == pid {
+			t.Fatalf("unexpected self PID %d in %+v", pid, elem)
+		}
+	}
+}
+
+func TestGetSSWithPIDs(t *testing.T) {
+	ss, err := GetSS(WithPIDs(1, int64(os.Getpid())))
+	checkPartialError(t, err)
+	for _, elem := range ss {
+		if elem.PID != 1 && elem.PID != int64(os.Getpid()) {
+			t.Fatalf("unexpected PID %d", elem.PID)
+		}
+	}
+}
+
+func TestGetSSWithPIDsAndPID(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	GetSS(WithPIDs(1), WithPID(1))
+}
+
+func TestGetSSNetlinkBackend(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	port := int64(ln.Addr().(*net.TCPAddr).Port)
+
+	pid := int64(os.Getpid())
+	ss, err := GetSS(WithPID(pid), WithNetlinkBackend(), WithLocalPort(port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss) != 1 {
+		t.Fatalf("expected 1 entry, got %+v", ss)
+	}
+	if ss[0].PID != pid || ss[0].State != "LISTEN" || ss[0].LocalIP != "127.0.0.1" {
+		t.Fatalf("unexpected %+v", ss[0])
+	}
+}
+
+func TestGetSSWithState(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+
+	ss, err := GetSS(WithPID(int64(os.Getpid())), WithState("listen"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss) == 0 {
+		t.Fatal("expected LISTEN entries")
+	}
+	for _, elem := range ss {
+		if elem.State != "LISTEN" {
+			t.Fatalf("unexpected state %q", elem.State)
+		}
+	}
+}
+
+func TestGetSSWithUnknownState(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	GetSS(WithState("LISTENING"))
+}
+
+func TestGetSSWithCIDR(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	port := int64(ln.Addr().(*net.TCPAddr).Port)
+
+	pid := int64(os.Getpid())
+	ss, err := GetSS(WithPID(pid), WithTCP(), WithLocalCIDR("127.0.0.0/8"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, elem := range ss {
+		if !strings.HasPrefix(elem.LocalIP, "127.") {
+			t.Fatalf("unexpected local IP %q", elem.LocalIP)
+		}
+		found = found || elem.LocalPort == port
+	}
+	if !found {
+		t.Fatalf("listener on port %d not found", port)
+	}
+
+	ss, err = GetSS(WithPID(pid), WithTCP(), WithLocalCIDR("10.0.0.0/8"), WithLocalPort(port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss) != 0 {
+		t.Fatalf("expected no entries, got %+v", ss)
+	}
+}
+
+func TestWithLocalCIDRInvalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	GetSS(WithLocalCIDR("10.0.0.0"))
+}
+
+func TestGetSSWithPortRange(t *testing.T) {
+	var ports []int64
+	for i := 0; i < 2; i++ {
+		ln, err := net.Listen("tcp4", "127.0.0.1:0")
+		if err != nil {
+			t.Skip(err)
+		}
+		defer ln.Close()
+		ports = append(ports, int64(ln.Addr().(*net.TCPAddr).Port))
+	}
+
+	pid := int64(os.Getpid())
+	ss, err := GetSS(WithPID(pid), WithTCP(), WithState("LISTEN"), WithLocalPorts(ports...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", ss)
+	}
+
+	ss, err = GetSS(WithPID(pid), WithTCP(), WithState("LISTEN"), WithLocalPortRange(ports[0], ports[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss) != 1 || ss[0].LocalPort != ports[0] {
+		t.Fatalf("expected port %d, got %+v", ports[0], ss)
+	}
+}
+
+func TestMatchPort(t *testing.T) {
+	tests := []struct {
+		port   int64
+		ranges []PortRange
+		v      int64
+		match  bool
+	}{
+		{0, nil, 80, true},
+		{80, nil, 80, true},
+		{80, nil, 443, false},
+		{0, []PortRange{{Min: 32768, Max: 60999}}, 40000, true},
+		{0, []PortRange{{Min: 32768, Max: 60999}}, 80, false},
+		{80, []PortRange{{Min: 443, Max: 443}}, 443, true},
+	}
+	for i, tt := range tests {
+		if m := matchPort(tt.port, tt.ranges, tt.v); m != tt.match {
+			t.Fatalf("#%d: expected %v, got %v", i, tt.match, m)
+		}
+	}
+}
+
+func TestStringSSListenOnly(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+
+	ss, err := GetSS(WithPID(int64(os.Getpid())), WithListenOnly())
 	if err != nil {
 		t.Fatal(err)
 	}
 	hd, rows := ConvertSS(ss...)
 	txt := StringSS(hd, rows, -1)
+	if strings.Contains(txt, "REMOTE-IP") {
+		t.Fatalf("unexpected remote columns in %s", txt)
+	}
 	fmt.Println(txt)
+
+	ss = append(ss, SSEntry{Protocol: "tcp", State: "ESTABLISHED"})
+	hd, rows = ConvertSS(ss...)
+	if txt = StringSS(hd, rows, -1); !strings.Contains(txt, "REMOTE-IP") {
+		t.Fatalf("expected remote columns in %s", txt)
+	}
 }
 
-func TestGetSSWithFilter(t *testing.T) {
-	ss, err := GetSS(WithPID(1))
+func TestGetSSWithTCPInfo(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	conn, err := net.Dial("tcp4", ln.Addr().String())
+	if err != nil {
+		t.Skip(err)
+	}
+	defer conn.Close()
+	port := int64(conn.LocalAddr().(*net.TCPAddr).Port)
+
+	ss, err := GetSS(WithPID(int64(os.Getpid())), WithTCPInfo(), WithLocalPort(port))
 	if err != nil {
 		t.Fatal(err)
 	}
+	if len(ss) != 1 {
+		t.Fatalf("expected 1 entry, got %+v", ss)
+	}
+	if ss[0].TCPInfo == nil || ss[0].TCPInfo.SndCwnd == 0 {
+		t.Fatalf("unexpected TCPInfo %+v", ss[0].TCPInfo)
+	}
 	hd, rows := ConvertSS(ss...)
 	txt := StringSS(hd, rows, -1)
+	if !strings.Contains(txt, "RTT-MS") {
+		t.Fatalf("expected TCP_INFO columns in %s", txt)
+	}
 	fmt.Println(txt)
 }
+
+func TestGetSSOwnedSockets(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	port := int64(ln.Addr().(*net.TCPAddr).Port)
+
+	ss, err := GetSS(WithPIDs(1), WithLocalPort(port))
+	checkPartialError(t, err)
+	if len(ss) != 0 {
+		t.Fatalf("socket of PID %d attributed to PID 1: %+v", os.Getpid(), ss)
+	}
+
+	ss, err = GetSS(WithLocalPort(port))
+	checkPartialError(t, err)
+	if len(ss) != 1 || ss[0].PID != int64(os.Getpid()) {
+		t.Fatalf("expected 1 entry of PID %d, got %+v", os.Getpid(), ss)
+	}
+}
+
+func TestGetSSTopLimitDeterministic(t *testing.T) {
+	var ports []int64
+	for i := 0; i < 3; i++ {
+		ln, err := net.Listen("tcp4", "127.0.0.1:0")
+		if err != nil {
+			t.Skip(err)
+		}
+		defer ln.Close()
+		ports = append(ports, int64(ln.Addr().(*net.TCPAddr).Port))
+	}
+
+	byPortDesc := func(a, b SSEntry) bool { return a.LocalPort > b.LocalPort }
+	max := ports[0]
+	for _, p := range ports {
+		if p > max {
+			max = p
+		}
+	}
+	for i := 0; i < 3; i++ {
+		ss, err := GetSS(WithPID(int64(os.Getpid())), WithLocalPorts(ports...), WithSSSort(byPortDesc), WithTopLimit(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ss) != 1 || ss[0].LocalPort != max {
+			t.Fatalf("expected port %d, got %+v", max, ss)
+		}
+	}
+}
+
+func TestGetSSNetNS(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	port := int64(ln.Addr().(*net.TCPAddr).Port)
+
+	ss, err := GetSS(WithPID(int64(os.Getpid())), WithLocalPort(port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss) != 1 {
+		t.Fatalf("expected 1 entry, got %+v", ss)
+	}
+	if !strings.HasPrefix(ss[0].NetNS, "net:[") {
+		t.Fatalf("unexpected network namespace %q", ss[0].NetNS)
+	}
+
+	// namespace column is only shown with different namespaces
+	other := ss[0]
+	other.NetNS = "net:[1]"
+	hd, rows := ConvertSS(ss[0], other)
+	if txt := StringSS(hd, rows, -1); !strings.Contains(txt, "NETNS") {
+		t.Fatalf("expected NETNS column in %s", txt)
+	}
+	hd, rows = ConvertSS(ss...)
+	if txt := StringSS(hd, rows, -1); strings.Contains(txt, "NETNS") {
+		t.Fatalf("unexpected NETNS column in %s", txt)
+	}
+}
+
+func TestGetSSWithRaw(t *testing.T) {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_RAW, syscall.IPPROTO_ICMP)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer syscall.Close(fd)
+
+	ss, err := GetSS(WithPID(int64(os.Getpid())), WithRaw())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss) != 1 || ss[0].Protocol != "raw" || ss[0].LocalPort != syscall.IPPROTO_ICMP {
+		t.Fatalf("unexpected %+v", ss)
+	}
+}
+
+func TestGetSSWithUID(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	port := int64(ln.Addr().(*net.TCPAddr).Port)
+	pid, uid := int64(os.Getpid()), uint64(os.Geteuid())
+
+	sss, err := GetSS(WithPID(pid), WithTCP(), WithLocalPort(port), WithUID(uid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sss) != 1 || sss[0].Program == "" {
+		t.Fatalf("expected 1 entry with program, got %+v", sss)
+	}
+	if sss, err = GetSS(WithPID(pid), WithTCP(), WithLocalPort(port), WithUID(uid+1)); err != nil {
+		t.Fatal(err)
+	}
+	if len(sss) != 0 {
+		t.Fatalf("expected no entries, got %+v", sss)
+	}
+
+	u, err := user.LookupId(strconv.FormatUint(uid, 10))
+	if err != nil {
+		t.Skip(err)
+	}
+	if sss, err = GetSS(WithPID(pid), WithTCP(), WithLocalPort(port), WithUsername(u.Username)); err != nil {
+		t.Fatal(err)
+	}
+	if len(sss) != 1 {
+		t.Fatalf("expected 1 entry, got %+v", sss)
+	}
+}
+
+func TestWithUsernameUnknown(t *testing.T) {
+	opt := WithUsername("no-such-user-linux-inspect")
+	if _, err := GetSS(WithPID(int64(os.Getpid())), opt); err == nil {
+		t.Fatal("expected GetSS error on unknown user")
+	}
+	if _, err := GetPS(WithPID(int64(os.Getpid())), opt); err == nil {
+		t.Fatal("expected GetPS error on unknown user")
+	}
+}
+
+func TestGetSSWithCgroupPathPrefix(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	port := int64(ln.Addr().(*net.TCPAddr).Port)
+	pid := int64(os.Getpid())
+
+	cgs, err := proc.GetCgroupsByPID(pid)
+	if err != nil || len(cgs) == 0 {
+		t.Skip(err)
+	}
+
+	sss, err := GetSS(WithPID(pid), WithTCP(), WithLocalPort(port), WithCgroupPathPrefix(cgs[0].Path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sss) != 1 {
+		t.Fatalf("expected 1 entry, got %+v", sss)
+	}
+	if sss, err = GetSS(WithPID(pid), WithTCP(), WithLocalPort(port), WithCgroupPathPrefix("/no-such-cgroup")); err != nil {
+		t.Fatal(err)
+	}
+	if len(sss) != 0 {
+		t.Fatalf("expected no entries, got %+v", sss)
+	}
+}
+
+func TestSSQueues(t *testing.T) {
+	tx, rx, err := parseQueues(proc.NetTCP{TxQueue: "0000001A", RxQueue: "00000100"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx != 26 || rx != 256 {
+		t.Fatalf("unexpected queues %d, %d", tx, rx)
+	}
+
+	hd, rows := ConvertSS(SSEntry{Protocol: "tcp", State: "ESTABLISHED"})
+	if txt := StringSS(hd, rows, -1); strings.Contains(txt, "RECV-Q") {
+		t.Fatalf("RECV-Q expected hidden:\n%s", txt)
+	}
+	hd, rows = ConvertSS(SSEntry{Protocol: "tcp", State: "ESTABLISHED", RxQueueBytes: 4096})
+	if txt := StringSS(hd, rows, -1); !strings.Contains(txt, "RECV-Q") || !strings.Contains(txt, "4096") {
+		t.Fatalf("RECV-Q expected:\n%s", txt)
+	}
+}
+
+func TestGetSSInodeFD(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	port := int64(ln.Addr().(*net.TCPAddr).Port)
+	pid := int64(os.Getpid())
+
+	sss, err := GetSS(WithPID(pid), WithTCP(), WithLocalPort(port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sss) != 1 {
+		t.Fatalf("expected 1 entry, got %+v", sss)
+	}
+	link, err := os.Readlink(fmt.Sprintf("/proc/%d/fd/%d", pid, sss[0].FD))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := fmt.Sprintf("socket:[%d]", sss[0].Inode); link != exp {
+		t.Fatalf("FD %d expected %q, got %q", sss[0].FD, exp, link)
+	}
+}
+
+func TestGetSSWithKernelSockets(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	port := int64(ln.Addr().(*net.TCPAddr).Port)
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sconn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sconn.Close()
+	// the side closing first goes to TIME_WAIT, without an owner
+	conn.Close()
+	sconn.Read(make([]byte, 1))
+	sconn.Close()
+
+	sss, err := GetSS(WithTCP(), WithRemotePort(port), WithState("TIME_WAIT"))
+	checkPartialError(t, err)
+	if len(sss) != 0 {
+		t.Fatalf("expected no entries without kernel sockets, got %+v", sss)
+	}
+
+	sss, err = GetSS(WithTCP(), WithRemotePort(port), WithState("TIME_WAIT"), WithKernelSockets())
+	checkPartialError(t, err)
+	if len(sss) != 1 {
+		t.Fatalf("expected 1 TIME_WAIT entry, got %+v", sss)
+	}
+	if sss[0].PID != -1 || sss[0].Program != "-" || sss[0].FD != -1 {
+		t.Fatalf("unexpected kernel socket %+v", sss[0])
+	}
+}
+
+func TestGetSSWithKernelSocketsExcludeSelf(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	port := int64(ln.Addr().(*net.TCPAddr).Port)
+
+	// the excluded listener must not come back as a kernel socket
+	sss, err := GetSS(WithTCP(), WithLocalPort(port), WithKernelSockets(), WithExcludeSelf())
+	checkPartialError(t, err)
+	if len(sss) != 0 {
+		t.Fatalf("expected no entries, got %+v", sss)
+	}
+}
+
+func TestGetSSSharedSocket(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	port := int64(ln.Addr().(*net.TCPAddr).Port)
+	f, err := ln.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// the child inherits the listener, like a forked worker
+	cmd := exec.Command("sleep", "5")
+	cmd.ExtraFiles = []*os.File{f}
+	if err = cmd.Start(); err != nil {
+		t.Skip(err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	sss, err := GetSS(WithPIDs(int64(os.Getpid()), int64(cmd.Process.Pid)), WithTCP(), WithLocalPort(port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pids := make(map[int64]bool)
+	for _, elem := range sss {
+		pids[elem.PID] = true
+		if elem.Inode != sss[0].Inode {
+			t.Fatalf("expected the same inode, got %+v", sss)
+		}
+	}
+	if !pids[int64(os.Getpid())] || !pids[int64(cmd.Process.Pid)] {
+		t.Fatalf("expected both PIDs, got %+v", sss)
+	}
+}
@@ -0,0 +1,135 @@
+package inspect
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// ColumnOp defines the columns shown by the table renderers
+// (e.g. 'StringSS', 'StringPS').
+type ColumnOp struct {
+	columns []string
+	without []string
+	color   bool
+}
+
+// ColumnOption applies each column option.
+type ColumnOption func(*ColumnOp)
+
+// WithColumns shows the columns in the given order, by header name
+// (e.g. "PROGRAM", "LOCAL-PORT"), instead of the default columns.
+// Columns hidden by default (e.g. "NETNS", "VMRSS-NUM") can be chosen.
+// It panics on unknown column names.
+func WithColumns(names ...string) ColumnOption {
+	return func(op *ColumnOp) { op.columns = names }
+}
+
+// WithoutColumns hides the columns, by header name (e.g. "USER", "PID"),
+// from the default or chosen columns. It panics on unknown column names.
+func WithoutColumns(names ...string) ColumnOption {
+	return func(op *ColumnOp) { op.without = append(op.without, names...) }
+}
+
+func (op *ColumnOp) applyOpts(opts []ColumnOption) {
+	for _, opt := range opts {
+		opt(op)
+	}
+}
+
+// indexes returns the indexes of the header to show.
+// defaults are used unless 'WithColumns' is given.
+func (op *ColumnOp) indexes(header []string, defaults []int) []int {
+	if len(op.columns) == 0 && len(op.without) == 0 {
+		return defaults
+	}
+
+	idx := make(map[string]int, len(header))
+	for i, h := range header {
+		idx[h] = i
+	}
+	lookup := func(name string) int {
+		i, ok := idx[name]
+		if !ok {
+			panic(fmt.Errorf("unknown column %q (expected one of %q)", name, header))
+		}
+		return i
+	}
+
+	idxs := defaults
+	if len(op.columns) > 0 {
+		idxs = make([]int, len(op.columns))
+		for i, name := range op.columns {
+			idxs[i] = lookup(name)
+		}
+	}
+	if len(op.without) > 0 {
+		skip := make(map[int]bool, len(op.without))
+		for _, name := range op.without {
+			skip[lookup(name)] = true
+		}
+		kept := make([]int, 0, len(idxs))
+		for _, i := range idxs {
+			if !skip[i] {
+				kept = append(kept, i)
+			}
+		}
+		idxs = kept
+	}
+	return idxs
+}
+
+// firstColumns returns the indexes of the first n columns.
+func firstColumns(n int) []int {
+	idxs := make([]int, n)
+	for i := range idxs {
+		idxs[i] = i
+	}
+	return idxs
+}
+
+// renderTable renders the columns of the header and rows, truncated
+// to topLimit rows if positive. defaults are the column indexes shown
+// unless the options choose others.
+func renderTable(header []string, rows [][]string, topLimit int, defaults []int, opts []ColumnOption) string {
+	op := &ColumnOp{}
+	op.applyOpts(opts)
+	idxs := op.indexes(header, defaults)
+
+	buf := new(bytes.Buffer)
+	tw := tablewriter.NewWriter(buf)
+
+	tw.SetHeader(selectColumns(header, idxs))
+
+	show := func(row []string) []string {
+		vs := selectColumns(row, idxs)
+		if op.color {
+			for i, idx := range idxs {
+				vs[i] = colorize(header[idx], vs[i])
+			}
+		}
+		return vs
+	}
+
+	if topLimit > 0 && len(rows) > topLimit {
+		rows = rows[:topLimit:topLimit]
+	}
+
+	for _, row := range rows {
+		tw.Append(show(row))
+	}
+	tw.SetAutoFormatHeaders(false)
+	tw.SetAlignment(tablewriter.ALIGN_RIGHT)
+	tw.Render()
+
+	return buf.String()
+}
+
+func selectColumns(row []string, idxs []int) []string {
+	vs := make([]string, len(idxs))
+	for i, idx := range idxs {
+		vs[i] = row[idx]
+	}
+	return vs
+}
@@ -0,0 +1,75 @@
+package inspect
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+// WithColor colors the cells with ANSI escape sequences: socket states
+// (e.g. ESTABLISHED green, TIME_WAIT yellow, CLOSE_WAIT red) and CPU
+// usage by heat.
+func WithColor(enabled bool) ColumnOption {
+	return func(op *ColumnOp) { op.color = enabled }
+}
+
+// WithColorAuto is 'WithColor' if f is a terminal, and the NO_COLOR
+// environment variable is not set (https://no-color.org).
+func WithColorAuto(f *os.File) ColumnOption {
+	return WithColor(isatty.IsTerminal(f.Fd()) && os.Getenv("NO_COLOR") == "")
+}
+
+func newColor(attrs ...color.Attribute) *color.Color {
+	c := color.New(attrs...)
+	// the caller decides, regardless of the process stdout
+	c.EnableColor()
+	return c
+}
+
+var (
+	colorGreen  = newColor(color.FgGreen)
+	colorYellow = newColor(color.FgYellow)
+	colorRed    = newColor(color.FgRed)
+	colorCyan   = newColor(color.FgCyan)
+)
+
+var stateColors = map[string]*color.Color{
+	"ESTABLISHED": colorGreen,
+	"LISTEN":      colorCyan,
+	"SYN_SENT":    colorYellow,
+	"SYN_RECV":    colorYellow,
+	"FIN_WAIT1":   colorYellow,
+	"FIN_WAIT2":   colorYellow,
+	"TIME_WAIT":   colorYellow,
+	"LAST_ACK":    colorYellow,
+	"CLOSING":     colorYellow,
+	"CLOSE_WAIT":  colorRed,
+	"CLOSE":       colorRed,
+}
+
+// colorize colors the cell of the column, if it is a state or CPU usage.
+func colorize(column, v string) string {
+	switch column {
+	case "STATE":
+		if c, ok := stateColors[v]; ok {
+			return c.Sprint(v)
+		}
+	case "CPU", "CPU-NUM":
+		f, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(v, "%")), 64)
+		if err != nil {
+			return v
+		}
+		switch {
+		case f >= 80:
+			return colorRed.Sprint(v)
+		case f >= 50:
+			return colorYellow.Sprint(v)
+		case f >= 10:
+			return colorGreen.Sprint(v)
+		}
+	}
+	return v
+}
@@ -0,0 +1,98 @@
+package inspect
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+func TestColumnIndexes(t *testing.T) {
+	header := []string{"A", "B", "C", "D"}
+	defaults := []int{0, 1, 2}
+
+	tests := []struct {
+		opts []ColumnOption
+		exp  []int
+	}{
+		{nil, []int{0, 1, 2}},
+		{[]ColumnOption{WithColumns("D", "A")}, []int{3, 0}},
+		{[]ColumnOption{WithoutColumns("B")}, []int{0, 2}},
+		{[]ColumnOption{WithColumns("D", "C", "B"), WithoutColumns("C")}, []int{3, 1}},
+	}
+	for i, tt := range tests {
+		op := &ColumnOp{}
+		op.applyOpts(tt.opts)
+		if idxs := op.indexes(header, defaults); !reflect.DeepEqual(idxs, tt.exp) {
+			t.Fatalf("#%d: expected %v, got %v", i, tt.exp, idxs)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic on unknown column")
+		}
+	}()
+	op := &ColumnOp{}
+	op.applyOpts([]ColumnOption{WithColumns("E")})
+	op.indexes(header, defaults)
+}
+
+func TestStringSSColumns(t *testing.T) {
+	hd, rows := ConvertSS(SSEntry{
+		Protocol:   "tcp",
+		Program:    "nginx",
+		State:      "ESTABLISHED",
+		PID:        123,
+		LocalIP:    "10.0.0.1",
+		LocalPort:  80,
+		RemoteIP:   "10.0.0.2",
+		RemotePort: 50000,
+		NetNS:      "net:[4026531992]",
+	})
+
+	txt := StringSS(hd, rows, -1, WithoutColumns("USER", "PID"))
+	if strings.Contains(txt, "USER") || strings.Contains(txt, " PID ") {
+		t.Fatalf("USER, PID expected hidden:\n%s", txt)
+	}
+	if !strings.Contains(txt, "REMOTE-PORT") {
+		t.Fatalf("REMOTE-PORT expected:\n%s", txt)
+	}
+
+	txt = StringSS(hd, rows, -1, WithColumns("LOCAL-PORT", "PROGRAM", "NETNS"))
+	lines := strings.Split(txt, "\n")
+	if !strings.Contains(lines[1], "LOCAL-PORT") || strings.Index(lines[1], "LOCAL-PORT") > strings.Index(lines[1], "PROGRAM") {
+		t.Fatalf("unexpected header order %q", lines[1])
+	}
+	if !strings.Contains(txt, "net:[4026531992]") || strings.Contains(txt, "PROTOCOL") {
+		t.Fatalf("unexpected columns:\n%s", txt)
+	}
+}
+
+func TestStringColor(t *testing.T) {
+	hd, rows := ConvertSS(
+		SSEntry{Protocol: "tcp", Program: "a", State: "ESTABLISHED", RemoteIP: "10.0.0.1"},
+		SSEntry{Protocol: "tcp", Program: "b", State: "CLOSE_WAIT", RemoteIP: "10.0.0.2"},
+	)
+	if txt := StringSS(hd, rows, -1); strings.Contains(txt, "\x1b[") {
+		t.Fatalf("unexpected ANSI sequences:\n%s", txt)
+	}
+	txt := StringSS(hd, rows, -1, WithColor(true))
+	if !strings.Contains(txt, colorGreen.Sprint("ESTABLISHED")) || !strings.Contains(txt, colorRed.Sprint("CLOSE_WAIT")) {
+		t.Fatalf("expected colored states:\n%s", txt)
+	}
+
+	// the widths exclude the ANSI sequences
+	lines := strings.Split(strings.TrimSpace(txt), "\n")
+	if tablewriter.DisplayWidth(lines[0]) != tablewriter.DisplayWidth(lines[3]) {
+		t.Fatalf("misaligned table:\n%s", txt)
+	}
+
+	if v := colorize("CPU", "95.00 %"); v != colorRed.Sprint("95.00 %") {
+		t.Fatalf("unexpected %q", v)
+	}
+	if v := colorize("CPU", "1.00 %"); v != "1.00 %" {
+		t.Fatalf("unexpected %q", v)
+	}
+}
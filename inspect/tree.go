@@ -0,0 +1,131 @@
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/gyuho/linux-inspect/proc"
+)
+
+// ProcessTree is the tree of processes,
+// built from the parent PIDs in '/proc/$PID/stat'.
+type ProcessTree struct {
+	// Nodes maps each PID to its process.
+	Nodes map[int64]*ProcessNode
+	// Roots are the processes whose parent is not in the tree
+	// (e.g. PID 1 and PID 2, whose parent is 0), sorted by PID.
+	Roots []*ProcessNode
+}
+
+// ProcessNode is a process in 'ProcessTree'.
+type ProcessNode struct {
+	PID     int64
+	PPID    int64
+	Program string
+	// Children are sorted by PID.
+	Children []*ProcessNode
+}
+
+// GetProcessTree reads the parent of all processes.
+func GetProcessTree() (*ProcessTree, error) {
+	return GetProcessTreeContext(context.Background())
+}
+
+// GetProcessTreeContext is 'GetProcessTree' with a context.
+func GetProcessTreeContext(ctx context.Context) (*ProcessTree, error) {
+	sm, err := proc.GetStatsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newProcessTree(sm), nil
+}
+
+func newProcessTree(sm map[int64]proc.Stat) *ProcessTree {
+	t := &ProcessTree{Nodes: make(map[int64]*ProcessNode, len(sm))}
+	for pid, st := range sm {
+		t.Nodes[pid] = &ProcessNode{PID: pid, PPID: st.Ppid, Program: st.Comm}
+	}
+	for _, nd := range t.Nodes {
+		parent, ok := t.Nodes[nd.PPID]
+		if !ok || nd.PPID == nd.PID {
+			t.Roots = append(t.Roots, nd)
+			continue
+		}
+		parent.Children = append(parent.Children, nd)
+	}
+	sortProcessNodes(t.Roots)
+	for _, nd := range t.Nodes {
+		sortProcessNodes(nd.Children)
+	}
+	return t
+}
+
+func sortProcessNodes(nds []*ProcessNode) {
+	sort.Slice(nds, func(i, j int) bool { return nds[i].PID < nds[j].PID })
+}
+
+// Descendants returns the PIDs of all processes spawned under the
+// process, depth first. It returns nil if the PID is not in the tree.
+func (t *ProcessTree) Descendants(pid int64) []int64 {
+	nd, ok := t.Nodes[pid]
+	if !ok {
+		return nil
+	}
+	var pids []int64
+	seen := map[int64]bool{pid: true}
+	var walk func(*ProcessNode)
+	walk = func(nd *ProcessNode) {
+		for _, c := range nd.Children {
+			// PIDs may be reused while reading '/proc'
+			if seen[c.PID] {
+				continue
+			}
+			seen[c.PID] = true
+			pids = append(pids, c.PID)
+			walk(c)
+		}
+	}
+	walk(nd)
+	return pids
+}
+
+// String renders the tree like 'pstree -p'.
+func (t *ProcessTree) String() string {
+	buf := new(bytes.Buffer)
+	seen := make(map[int64]bool, len(t.Nodes))
+	for _, nd := range t.Roots {
+		renderProcessNode(buf, nd, "", "", seen)
+	}
+	return buf.String()
+}
+
+// StringPID renders the subtree of the process like 'pstree -p $PID'.
+// It returns an empty string if the PID is not in the tree.
+func (t *ProcessTree) StringPID(pid int64) string {
+	nd, ok := t.Nodes[pid]
+	if !ok {
+		return ""
+	}
+	buf := new(bytes.Buffer)
+	renderProcessNode(buf, nd, "", "", make(map[int64]bool))
+	return buf.String()
+}
+
+// renderProcessNode writes the node after 'prefix', and its
+// children each after 'indent' and a branch.
+func renderProcessNode(buf *bytes.Buffer, nd *ProcessNode, prefix, indent string, seen map[int64]bool) {
+	if seen[nd.PID] {
+		return
+	}
+	seen[nd.PID] = true
+	fmt.Fprintf(buf, "%s%s(%d)\n", prefix, sanitizeUTF8(nd.Program), nd.PID)
+	for i, c := range nd.Children {
+		if i == len(nd.Children)-1 {
+			renderProcessNode(buf, c, indent+"└─", indent+"  ", seen)
+		} else {
+			renderProcessNode(buf, c, indent+"├─", indent+"│ ", seen)
+		}
+	}
+}
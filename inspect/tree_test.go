@@ -0,0 +1,72 @@
+package inspect
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/gyuho/linux-inspect/proc"
+)
+
+func TestProcessTree(t *testing.T) {
+	tr := newProcessTree(map[int64]proc.Stat{
+		1:  {Pid: 1, Ppid: 0, Comm: "systemd"},
+		2:  {Pid: 2, Ppid: 0, Comm: "kthreadd"},
+		10: {Pid: 10, Ppid: 1, Comm: "sshd"},
+		11: {Pid: 11, Ppid: 10, Comm: "bash"},
+		12: {Pid: 12, Ppid: 11, Comm: "vim"},
+		13: {Pid: 13, Ppid: 10, Comm: "bash"},
+		20: {Pid: 20, Ppid: 1, Comm: "cron"},
+		30: {Pid: 30, Ppid: 99, Comm: "orphan"},
+	})
+
+	if pids := tr.Descendants(10); !reflect.DeepEqual(pids, []int64{11, 12, 13}) {
+		t.Fatalf("unexpected descendants %v", pids)
+	}
+	if pids := tr.Descendants(12); len(pids) != 0 {
+		t.Fatalf("unexpected descendants %v", pids)
+	}
+	if pids := tr.Descendants(99); pids != nil {
+		t.Fatalf("unexpected descendants %v", pids)
+	}
+
+	exp := `systemd(1)
+├─sshd(10)
+│ ├─bash(11)
+│ │ └─vim(12)
+│ └─bash(13)
+└─cron(20)
+kthreadd(2)
+orphan(30)
+`
+	if s := tr.String(); s != exp {
+		t.Fatalf("expected\n%s\ngot\n%s", exp, s)
+	}
+	exp = `bash(11)
+└─vim(12)
+`
+	if s := tr.StringPID(11); s != exp {
+		t.Fatalf("expected\n%s\ngot\n%s", exp, s)
+	}
+}
+
+func TestGetProcessTree(t *testing.T) {
+	tr, err := GetProcessTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid := int64(os.Getpid())
+	nd, ok := tr.Nodes[pid]
+	if !ok {
+		t.Fatalf("PID %d not found", pid)
+	}
+	found := false
+	for _, p := range tr.Descendants(nd.PPID) {
+		found = found || p == pid
+	}
+	if !found {
+		t.Fatalf("PID %d not under its parent %d", pid, nd.PPID)
+	}
+	fmt.Print(tr.StringPID(nd.PPID))
+}
@@ -0,0 +1,93 @@
+package inspect
+
+import (
+	"time"
+
+	"github.com/gyuho/linux-inspect/proc"
+)
+
+// timeWaitLen is TCP_TIMEWAIT_LEN, how long a socket stays in TIME_WAIT.
+// It is hard-coded in the kernel; 'tcp_fin_timeout' only applies to FIN_WAIT2.
+const timeWaitLen = 60 * time.Second
+
+// TuningReport is the TIME_WAIT pressure on the host, correlated with
+// the related sysctls in '/proc/sys/net/ipv4'.
+//
+// The kernel does not expose the age of each TIME_WAIT bucket, so
+// the time to drain is estimated as the worst case (TCP_TIMEWAIT_LEN)
+// with no new TIME_WAIT sockets.
+type TuningReport struct {
+	// TimeWait is the number of TIME_WAIT sockets in '/proc/net/tcp(6)'.
+	TimeWait int
+	// SockstatTimeWait is the 'tw' in '/proc/net/sockstat'.
+	SockstatTimeWait int64
+
+	// TCPFinTimeout is 'net.ipv4.tcp_fin_timeout'.
+	TCPFinTimeout time.Duration
+	// TCPTwReuse is 'net.ipv4.tcp_tw_reuse'
+	// (0 disabled, 1 enabled, 2 loopback only).
+	TCPTwReuse int64
+	// TCPMaxTwBuckets is 'net.ipv4.tcp_max_tw_buckets'.
+	TCPMaxTwBuckets int64
+	// LocalPortRangeLow and LocalPortRangeHigh are
+	// 'net.ipv4.ip_local_port_range'.
+	LocalPortRangeLow  int64
+	LocalPortRangeHigh int64
+
+	// EphemeralPorts is the size of the local port range.
+	EphemeralPorts int64
+	// TimeWaitPortsPercent is TIME_WAIT sockets over ephemeral ports,
+	// in percentage, assuming a single remote endpoint.
+	TimeWaitPortsPercent float64
+	// TimeWaitBucketsPercent is TIME_WAIT sockets over
+	// 'tcp_max_tw_buckets', in percentage.
+	TimeWaitBucketsPercent float64
+	// EstimatedDrainTime is the worst-case time until all current
+	// TIME_WAIT sockets expire.
+	EstimatedDrainTime time.Duration
+}
+
+// GetTuningReport reports the TIME_WAIT pressure and related sysctls.
+func GetTuningReport() (TuningReport, error) {
+	var rp TuningReport
+	for _, tp := range []proc.TransportProtocol{proc.TypeTCP, proc.TypeTCP6} {
+		st, err := proc.GetNetTCPStats(tp)
+		if err != nil {
+			return TuningReport{}, err
+		}
+		rp.TimeWait += st.States["TIME_WAIT"].Count
+	}
+
+	ss, err := proc.GetSockstat()
+	if err != nil {
+		return TuningReport{}, err
+	}
+	rp.SockstatTimeWait = ss.TCPTimeWait
+
+	ns, err := proc.GetNetSysctls()
+	if err != nil {
+		return TuningReport{}, err
+	}
+	rp.TCPFinTimeout = time.Duration(ns.TCPFinTimeoutSecond) * time.Second
+	rp.TCPTwReuse = ns.TCPTwReuse
+	rp.LocalPortRangeLow, rp.LocalPortRangeHigh = ns.LocalPortRangeLow, ns.LocalPortRangeHigh
+	if rp.TCPMaxTwBuckets, err = proc.GetSysctlInt("net.ipv4.tcp_max_tw_buckets"); err != nil {
+		return TuningReport{}, err
+	}
+
+	rp.estimate()
+	return rp, nil
+}
+
+func (rp *TuningReport) estimate() {
+	rp.EphemeralPorts = rp.LocalPortRangeHigh - rp.LocalPortRangeLow + 1
+	if rp.EphemeralPorts > 0 {
+		rp.TimeWaitPortsPercent = float64(rp.TimeWait) / float64(rp.EphemeralPorts) * 100
+	}
+	if rp.TCPMaxTwBuckets > 0 {
+		rp.TimeWaitBucketsPercent = float64(rp.TimeWait) / float64(rp.TCPMaxTwBuckets) * 100
+	}
+	if rp.TimeWait > 0 {
+		rp.EstimatedDrainTime = timeWaitLen
+	}
+}
@@ -0,0 +1,34 @@
+package inspect
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTuningReportEstimate(t *testing.T) {
+	rp := TuningReport{
+		TimeWait:           14116,
+		TCPMaxTwBuckets:    28232,
+		LocalPortRangeLow:  32768,
+		LocalPortRangeHigh: 60999,
+	}
+	rp.estimate()
+	if rp.EphemeralPorts != 28232 {
+		t.Fatalf("expected 28232 ephemeral ports, got %d", rp.EphemeralPorts)
+	}
+	if rp.TimeWaitPortsPercent != 50 || rp.TimeWaitBucketsPercent != 50 {
+		t.Fatalf("expected 50%%, got %+v", rp)
+	}
+	if rp.EstimatedDrainTime != time.Minute {
+		t.Fatalf("expected 1m drain time, got %v", rp.EstimatedDrainTime)
+	}
+}
+
+func TestGetTuningReport(t *testing.T) {
+	rp, err := GetTuningReport()
+	if err != nil {
+		t.Skip(err)
+	}
+	fmt.Printf("%+v\n", rp)
+}
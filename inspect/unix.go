@@ -0,0 +1,126 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"os/user"
+	"time"
+
+	"github.com/gyuho/linux-inspect/proc"
+
+	"github.com/gyuho/dataframe"
+)
+
+// UnixSocketEntry is a unix domain socket entry.
+// Simplified from 'proc.NetUnix'.
+type UnixSocketEntry struct {
+	Path  string
+	Type  string
+	State string
+	Inode uint64
+
+	// PID, Program, User are of the owning process; zero if no
+	// visible process holds the socket (e.g. kernel or other namespaces).
+	PID     int64
+	Program string
+	User    user.User
+}
+
+// GetUnixSockets lists the unix domain sockets in '/proc/net/unix',
+// with the owning processes resolved from '/proc/$PID/fd'. A socket
+// shared by several processes is listed once per process.
+//
+// With a PID or program filter, only the sockets owned by the matching
+// processes are returned.
+func GetUnixSockets(opts ...OpFunc) ([]UnixSocketEntry, error) {
+	return GetUnixSocketsContext(context.Background(), opts...)
+}
+
+// GetUnixSocketsContext is 'GetUnixSockets' with a context. Once the
+// context is done, it stops reading '/proc' and returns the context error.
+func GetUnixSocketsContext(ctx context.Context, opts ...OpFunc) (uss []UnixSocketEntry, err error) {
+	ft := &EntryOp{}
+	ft.applyOpts(opts)
+	defer func(start time.Time) { ft.Instrument.Observe("GetUnixSockets", start, err) }(ft.Instrument.Now())
+
+	nus, err := proc.GetNetUnix()
+	ft.Instrument.procRead(err)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := &pidErrors{op: ft}
+	owners, filtered, err := getSocketOwners(ctx, ft, errs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, nu := range nus {
+		entry := UnixSocketEntry{
+			Path:  nu.Path,
+			Type:  nu.Type,
+			State: nu.State,
+			Inode: nu.Inode,
+		}
+		ows, ok := owners[nu.Inode]
+		if !ok {
+			if !filtered {
+				uss = append(uss, entry)
+			}
+			continue
+		}
+		for _, o := range ows {
+			entry.PID, entry.Program, entry.User = o.pid, o.program, o.user
+			uss = append(uss, entry)
+		}
+	}
+
+	if ft.TopLimit > 0 && len(uss) > ft.TopLimit {
+		uss = uss[:ft.TopLimit:ft.TopLimit]
+	}
+	return uss, errs.err()
+}
+
+const columnsUnixSocketsToShow = 7
+
+var columnsUnixSocketEntry = []string{
+	"PROGRAM",
+	"PID",
+	"TYPE",
+	"STATE",
+	"PATH",
+	"INODE",
+	"USER",
+}
+
+// ConvertUnixSockets converts to rows.
+func ConvertUnixSockets(uss ...UnixSocketEntry) (header []string, rows [][]string) {
+	header = columnsUnixSocketEntry
+	rows = make([][]string, len(uss))
+	for i, elem := range uss {
+		row := make([]string, len(columnsUnixSocketEntry))
+		row[0] = sanitizeUTF8(elem.Program)
+		row[1] = fmt.Sprintf("%d", elem.PID)
+		row[2] = elem.Type
+		row[3] = elem.State
+		row[4] = sanitizeUTF8(elem.Path)
+		row[5] = fmt.Sprintf("%d", elem.Inode)
+		row[6] = sanitizeUTF8(elem.User.Username)
+
+		rows[i] = row
+	}
+	dataframe.SortBy(
+		rows,
+		dataframe.StringAscendingFunc(0), // Program
+		dataframe.StringAscendingFunc(3), // State
+		dataframe.StringAscendingFunc(4), // Path
+		dataframe.StringAscendingFunc(1), // PID
+	).Sort(rows)
+
+	return
+}
+
+// StringUnixSockets converts in print-friendly format.
+func StringUnixSockets(header []string, rows [][]string, topLimit int, opts ...ColumnOption) string {
+	return renderTable(header, rows, topLimit, firstColumns(columnsUnixSocketsToShow), opts)
+}
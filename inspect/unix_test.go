@@ -0,0 +1,66 @@
+package inspect
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gyuho/linux-inspect/proc"
+)
+
+func TestGetUnixSockets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "linux-inspect-unix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+
+	pid := int64(os.Getpid())
+	uss, err := GetUnixSockets(WithPID(pid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, elem := range uss {
+		if elem.PID != pid {
+			t.Fatalf("unexpected PID %d", elem.PID)
+		}
+		if elem.Path == path {
+			found = true
+			if elem.State != "LISTEN" || elem.Type != "stream" {
+				t.Fatalf("unexpected %+v", elem)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("%q not found in %+v", path, uss)
+	}
+
+	hd, rows := ConvertUnixSockets(uss...)
+	fmt.Println(StringUnixSockets(hd, rows, -1))
+}
+
+func TestGetUnixSocketsPartialErrors(t *testing.T) {
+	if _, err := proc.GetSocketFDsByPID(1); err == nil {
+		t.Skip("PID 1 is readable")
+	}
+	_, err := GetUnixSockets(WithPIDs(1, int64(os.Getpid())))
+	if perr, ok := err.(*PartialError); !ok || perr.Errors[0].PID != 1 {
+		t.Fatalf("expected partial error for PID 1, got %v", err)
+	}
+	var pidErr *PIDError
+	if _, err = GetUnixSockets(WithPIDs(1), WithStrictErrors()); !errors.As(err, &pidErr) {
+		t.Fatalf("expected PID error, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package inspect
+
+import (
+	"os/user"
+	"strconv"
+)
+
+// userCache caches 'user.LookupId' results, including failures,
+// for the duration of one call (e.g. 'GetSS'), and records the
+// lookups to the Instrument. Not safe for concurrent use.
+type userCache struct {
+	in *Instrument
+	m  map[uint64]userLookup
+}
+
+func newUserCache(in *Instrument) *userCache {
+	return &userCache{in: in, m: make(map[uint64]userLookup)}
+}
+
+type userLookup struct {
+	u   *user.User
+	err error
+}
+
+func (c *userCache) lookup(uid uint64) (*user.User, error) {
+	if l, ok := c.m[uid]; ok {
+		c.in.userLookup(true)
+		return l.u, l.err
+	}
+	c.in.userLookup(false)
+	u, err := user.LookupId(strconv.FormatUint(uid, 10))
+	c.m[uid] = userLookup{u: u, err: err}
+	return u, err
+}
+
+// lookupOrUID returns the user, or the user with the numeric UID as
+// the username if it cannot be looked up (e.g. container UIDs remapped
+// by user namespaces, without a host passwd entry), like 'GetPS'.
+func (c *userCache) lookupOrUID(uid uint64) user.User {
+	if u, err := c.lookup(uid); err == nil {
+		return *u
+	}
+	id := strconv.FormatUint(uid, 10)
+	return user.User{Uid: id, Username: id}
+}
@@ -0,0 +1,58 @@
+package inspect
+
+import (
+	"net"
+	"os"
+	"testing"
+)
+
+func TestUserCache(t *testing.T) {
+	in := NewInstrument()
+	c := newUserCache(in)
+	uid := uint64(os.Getuid())
+	u1, err := c.lookup(uid)
+	if err != nil {
+		t.Skip(err)
+	}
+	u2, err := c.lookup(uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u1 != u2 {
+		t.Fatalf("expected cached user, got %p and %p", u1, u2)
+	}
+	if st := in.Stats(); st.UserLookups != 2 || st.UserCacheHits != 1 {
+		t.Fatalf("expected 2 lookups with 1 cache hit, got %+v", st)
+	}
+}
+
+func TestUserCacheLookupOrUID(t *testing.T) {
+	c := newUserCache(nil)
+	// no passwd entry, as for user namespace remapped UIDs
+	if _, err := c.lookup(166536); err == nil {
+		t.Skip("UID 166536 exists")
+	}
+	if u := c.lookupOrUID(166536); u.Uid != "166536" || u.Username != "166536" {
+		t.Fatalf("unexpected user %+v", u)
+	}
+}
+
+func TestGetSSWithoutUserLookup(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	port := int64(ln.Addr().(*net.TCPAddr).Port)
+
+	ss, err := GetSS(WithPID(int64(os.Getpid())), WithLocalPort(port), WithoutUserLookup())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss) != 1 {
+		t.Fatalf("expected 1 entry, got %+v", ss)
+	}
+	if ss[0].User.Uid != "" || ss[0].User.Username != "" {
+		t.Fatalf("expected empty user, got %+v", ss[0].User)
+	}
+}
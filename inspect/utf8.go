@@ -0,0 +1,16 @@
+package inspect
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// sanitizeUTF8 replaces invalid UTF-8 sequences with U+FFFD, since
+// program names and command lines in '/proc' are raw bytes that can
+// corrupt table, CSV, or logfmt output.
+func sanitizeUTF8(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+	return strings.ToValidUTF8(s, string(utf8.RuneError))
+}
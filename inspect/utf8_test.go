@@ -0,0 +1,32 @@
+package inspect
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitizeUTF8(t *testing.T) {
+	comm := "bad\xff\xfename"
+	ent := SSEntry{Protocol: "tcp", Program: comm, State: "LISTEN", PID: 1}
+
+	_, rows := ConvertSS(ent)
+	if !utf8.ValidString(rows[0][1]) || rows[0][1] != "bad�name" {
+		t.Fatalf("expected sanitized program, got %q", rows[0][1])
+	}
+	if s := StringSS(columnsSSEntry, rows, 0); !utf8.ValidString(s) {
+		t.Fatalf("invalid UTF-8 in table %q", s)
+	}
+	if s := StringSSLogfmt(ent); !utf8.ValidString(s) || !strings.Contains(s, "program=bad�name") {
+		t.Fatalf("invalid UTF-8 in logfmt %q", s)
+	}
+
+	_, rows = ConvertPS(PSEntry{Program: comm})
+	if !utf8.ValidString(rows[0][0]) {
+		t.Fatalf("expected sanitized program, got %q", rows[0][0])
+	}
+
+	if s := sanitizeUTF8("日本語"); s != "日本語" {
+		t.Fatalf("valid UTF-8 changed to %q", s)
+	}
+}
@@ -0,0 +1,85 @@
+package timeutil
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock abstracts time, so that time-dependent code can be tested
+// with a fake clock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After waits for the duration to elapse and then sends
+	// the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+// RealClock is the Clock backed by package time.
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// FakeClock is a Clock that only moves on Advance.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	until time.Time
+	c     chan time.Time
+}
+
+// NewFakeClock returns a FakeClock set to the given time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the fake current time.
+func (fc *FakeClock) Now() time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.now
+}
+
+// After returns a channel that fires once the clock is advanced
+// by the duration.
+func (fc *FakeClock) After(d time.Duration) <-chan time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	c := make(chan time.Time, 1)
+	if d <= 0 {
+		c <- fc.now
+		return c
+	}
+	fc.waiters = append(fc.waiters, fakeWaiter{until: fc.now.Add(d), c: c})
+	return c
+}
+
+// Advance moves the clock forward, and fires all expired waiters.
+func (fc *FakeClock) Advance(d time.Duration) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.now = fc.now.Add(d)
+	ws := fc.waiters[:0]
+	for _, w := range fc.waiters {
+		if w.until.After(fc.now) {
+			ws = append(ws, w)
+			continue
+		}
+		w.c <- fc.now
+	}
+	fc.waiters = ws
+}
+
+// Waiters returns the number of pending After calls.
+func (fc *FakeClock) Waiters() int {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return len(fc.waiters)
+}
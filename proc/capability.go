@@ -0,0 +1,151 @@
+package proc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Capabilities is the decoded capability sets of a process,
+// from 'CapInh', 'CapPrm', 'CapEff', 'CapBnd', 'CapAmb' in '/proc/$PID/status'.
+// Reference http://man7.org/linux/man-pages/man7/capabilities.7.html.
+type Capabilities struct {
+	Inheritable []string
+	Permitted   []string
+	Effective   []string
+	Bounding    []string
+	Ambient     []string
+}
+
+// capabilityNames maps each capability bit to its name.
+// https://github.com/torvalds/linux/blob/master/include/uapi/linux/capability.h
+var capabilityNames = []string{
+	"CAP_CHOWN",
+	"CAP_DAC_OVERRIDE",
+	"CAP_DAC_READ_SEARCH",
+	"CAP_FOWNER",
+	"CAP_FSETID",
+	"CAP_KILL",
+	"CAP_SETGID",
+	"CAP_SETUID",
+	"CAP_SETPCAP",
+	"CAP_LINUX_IMMUTABLE",
+	"CAP_NET_BIND_SERVICE",
+	"CAP_NET_BROADCAST",
+	"CAP_NET_ADMIN",
+	"CAP_NET_RAW",
+	"CAP_IPC_LOCK",
+	"CAP_IPC_OWNER",
+	"CAP_SYS_MODULE",
+	"CAP_SYS_RAWIO",
+	"CAP_SYS_CHROOT",
+	"CAP_SYS_PTRACE",
+	"CAP_SYS_PACCT",
+	"CAP_SYS_ADMIN",
+	"CAP_SYS_BOOT",
+	"CAP_SYS_NICE",
+	"CAP_SYS_RESOURCE",
+	"CAP_SYS_TIME",
+	"CAP_SYS_TTY_CONFIG",
+	"CAP_MKNOD",
+	"CAP_LEASE",
+	"CAP_AUDIT_WRITE",
+	"CAP_AUDIT_CONTROL",
+	"CAP_SETFCAP",
+	"CAP_MAC_OVERRIDE",
+	"CAP_MAC_ADMIN",
+	"CAP_SYSLOG",
+	"CAP_WAKE_ALARM",
+	"CAP_BLOCK_SUSPEND",
+	"CAP_AUDIT_READ",
+	"CAP_PERFMON",
+	"CAP_BPF",
+	"CAP_CHECKPOINT_RESTORE",
+}
+
+// DangerousCapabilities lists the capabilities that effectively
+// grant root-equivalent power, or allow escaping a sandbox.
+var DangerousCapabilities = map[string]struct{}{
+	"CAP_SYS_ADMIN":       {},
+	"CAP_NET_ADMIN":       {},
+	"CAP_SYS_MODULE":      {},
+	"CAP_SYS_PTRACE":      {},
+	"CAP_SYS_RAWIO":       {},
+	"CAP_DAC_OVERRIDE":    {},
+	"CAP_DAC_READ_SEARCH": {},
+	"CAP_SETUID":          {},
+	"CAP_SETGID":          {},
+	"CAP_SETFCAP":         {},
+	"CAP_BPF":             {},
+}
+
+// GetCapabilitiesByPID reads '/proc/$PID/status' and
+// decodes the capability hex masks into capability names.
+func GetCapabilitiesByPID(pid int64) (Capabilities, error) {
+	s, err := GetStatusByPID(pid)
+	if err != nil {
+		return Capabilities{}, err
+	}
+
+	var c Capabilities
+	if c.Inheritable, err = parseCapabilityMask(s.CapInh); err != nil {
+		return Capabilities{}, err
+	}
+	if c.Permitted, err = parseCapabilityMask(s.CapPrm); err != nil {
+		return Capabilities{}, err
+	}
+	if c.Effective, err = parseCapabilityMask(s.CapEff); err != nil {
+		return Capabilities{}, err
+	}
+	if c.Bounding, err = parseCapabilityMask(s.CapBnd); err != nil {
+		return Capabilities{}, err
+	}
+	if c.Ambient, err = parseCapabilityMask(s.CapAmb); err != nil {
+		return Capabilities{}, err
+	}
+	return c, nil
+}
+
+// Dangerous returns the dangerous capabilities in the effective set.
+// It returns nil if none is found.
+func (c Capabilities) Dangerous() (caps []string) {
+	for _, name := range c.Effective {
+		if _, ok := DangerousCapabilities[name]; ok {
+			caps = append(caps, name)
+		}
+	}
+	return caps
+}
+
+// HasDangerous returns true if the effective set
+// has any of 'DangerousCapabilities'.
+func (c Capabilities) HasDangerous() bool {
+	return len(c.Dangerous()) > 0
+}
+
+// parseCapabilityMask converts hex mask (e.g. '0000003fffffffff')
+// to capability names. Unknown bits are named 'CAP_$BIT'.
+func parseCapabilityMask(s string) ([]string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		// not available in old kernels (e.g. 'CapAmb')
+		return nil, nil
+	}
+	mask, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse capability mask %q (%v)", s, err)
+	}
+
+	var names []string
+	for bit := uint(0); bit < 64; bit++ {
+		if mask&(1<<bit) == 0 {
+			continue
+		}
+		if int(bit) < len(capabilityNames) {
+			names = append(names, capabilityNames[bit])
+		} else {
+			names = append(names, fmt.Sprintf("CAP_%d", bit))
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,41 @@
+package proc
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseCapabilityMask(t *testing.T) {
+	names, err := parseCapabilityMask("0000000000201000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []string{"CAP_NET_ADMIN", "CAP_SYS_ADMIN"}
+	if !reflect.DeepEqual(names, exp) {
+		t.Fatalf("expected %v, got %v", exp, names)
+	}
+
+	c := Capabilities{Effective: names}
+	if !c.HasDangerous() {
+		t.Fatalf("expected dangerous capabilities in %v", names)
+	}
+
+	names, err = parseCapabilityMask("0000000000000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 0 {
+		t.Fatalf("expected no capability, got %v", names)
+	}
+}
+
+func TestGetCapabilitiesByPID(t *testing.T) {
+	c, err := GetCapabilitiesByPID(int64(os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Printf("GetCapabilitiesByPID: %+v\n", c)
+	fmt.Println("Dangerous:", c.Dangerous())
+}
@@ -0,0 +1,133 @@
+package proc
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gyuho/linux-inspect/pkg/fileutil"
+)
+
+// cgroupRoot is the cgroup file system mount point.
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cgroupHierarchies is the lookup order of cgroup hierarchies for a
+// relative cgroup path. "" is the cgroup v2 unified hierarchy mounted
+// at the root, "unified" is the v2 hierarchy in hybrid mode, and the
+// rest are cgroup v1 controllers.
+var cgroupHierarchies = []string{"", "unified", "systemd", "pids", "memory", "cpu,cpuacct", "cpu"}
+
+// GetCgroupPIDs reads the member PIDs of a cgroup in 'cgroup.procs'.
+// The path is either a directory under '/sys/fs/cgroup'
+// (e.g. '/sys/fs/cgroup/memory/docker/<id>'), or a cgroup path as in
+// '/proc/$PID/cgroup' (e.g. '/system.slice/sshd.service'), which is
+// looked up in the cgroup v2 unified hierarchy first and then in
+// cgroup v1 controllers.
+//
+// The returned PIDs are sorted and unique. Processes may join or leave
+// the cgroup during or after the read, so callers should skip PIDs
+// that no longer exist.
+func GetCgroupPIDs(cgroupPath string) ([]int64, error) {
+	fpath, err := cgroupProcsPath(cgroupPath)
+	if err != nil {
+		return nil, err
+	}
+	f, err := fileutil.OpenToRead(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// cgroup v1 'cgroup.procs' is not guaranteed to be sorted or unique
+	seen := make(map[int64]struct{})
+	var pids []int64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		txt := strings.TrimSpace(scanner.Text())
+		if len(txt) == 0 {
+			continue
+		}
+		pid, err := strconv.ParseInt(txt, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%v when parsing %q in %q", err, txt, fpath)
+		}
+		if _, ok := seen[pid]; ok {
+			continue
+		}
+		seen[pid] = struct{}{}
+		pids = append(pids, pid)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+	return pids, nil
+}
+
+// ProcCgroup is a line in '/proc/$PID/cgroup'.
+type ProcCgroup struct {
+	// HierarchyID is 0 for cgroup v2.
+	HierarchyID int64
+	// Controllers is the comma-separated controllers for cgroup v1
+	// (e.g. "cpu,cpuacct"), and empty for cgroup v2.
+	Controllers string
+	// Path is the cgroup path relative to the hierarchy root.
+	Path string
+}
+
+// GetCgroupsByPID reads the cgroups of the process in '/proc/$PID/cgroup'.
+func GetCgroupsByPID(pid int64) ([]ProcCgroup, error) {
+	fpath := fmt.Sprintf("/proc/%d/cgroup", pid)
+	f, err := fileutil.OpenToRead(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cs []ProcCgroup
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		txt := strings.TrimSpace(scanner.Text())
+		if len(txt) == 0 {
+			continue
+		}
+		// 'hierarchy-ID:controller-list:cgroup-path'
+		fs := strings.SplitN(txt, ":", 3)
+		if len(fs) != 3 {
+			return nil, fmt.Errorf("unexpected line %q in %q", txt, fpath)
+		}
+		id, err := strconv.ParseInt(fs[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%v when parsing %q in %q", err, txt, fpath)
+		}
+		cs = append(cs, ProcCgroup{HierarchyID: id, Controllers: fs[1], Path: fs[2]})
+	}
+	return cs, scanner.Err()
+}
+
+func cgroupProcsPath(cgroupPath string) (string, error) {
+	if strings.HasPrefix(cgroupPath, cgroupRoot+"/") || cgroupPath == cgroupRoot {
+		fpath := filepath.Join(cgroupPath, "cgroup.procs")
+		if !fileutil.Exist(fpath) {
+			return "", fmt.Errorf("%q does not exist", fpath)
+		}
+		return fpath, nil
+	}
+	for _, h := range cgroupHierarchies {
+		fpath := filepath.Join(cgroupRoot, h, cgroupPath, "cgroup.procs")
+		if h == "" {
+			// only cgroup v2 has 'cgroup.controllers' at the root
+			if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err != nil {
+				continue
+			}
+		}
+		if fileutil.Exist(fpath) {
+			return fpath, nil
+		}
+	}
+	return "", fmt.Errorf("cgroup %q not found in %q", cgroupPath, cgroupRoot)
+}
@@ -0,0 +1,60 @@
+package proc
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGetCgroupPIDs(t *testing.T) {
+	f, err := os.Open("/proc/self/cgroup")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer f.Close()
+
+	// '0::/path' for cgroup v2, or the v1 'pids' controller
+	var cgroupPath string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fs := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fs) == 3 && (fs[1] == "" || fs[1] == "pids") {
+			cgroupPath = fs[2]
+			break
+		}
+	}
+
+	pids, err := GetCgroupPIDs(cgroupPath)
+	if err != nil {
+		t.Skip(err)
+	}
+	self := int64(os.Getpid())
+	found := false
+	for i, pid := range pids {
+		if i > 0 && pids[i-1] >= pid {
+			t.Fatalf("PIDs not sorted or unique %v", pids)
+		}
+		found = found || pid == self
+	}
+	if !found {
+		t.Logf("PID %d not found in cgroup %q (%v)", self, cgroupPath, pids)
+	}
+	fmt.Println("GetCgroupPIDs:", cgroupPath, len(pids))
+
+	if _, err = GetCgroupPIDs("/linux-inspect-does-not-exist"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestGetCgroupsByPID(t *testing.T) {
+	cs, err := GetCgroupsByPID(int64(os.Getpid()))
+	if err != nil {
+		t.Skip(err)
+	}
+	if len(cs) == 0 {
+		t.Fatal("expected at least one cgroup")
+	}
+	fmt.Printf("GetCgroupsByPID: %+v\n", cs)
+}
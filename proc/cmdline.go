@@ -0,0 +1,62 @@
+package proc
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/gyuho/linux-inspect/pkg/fileutil"
+)
+
+// GetCmdlineByPID reads the command line arguments in '/proc/$PID/cmdline'.
+// It returns an empty slice for kernel threads and zombies.
+func GetCmdlineByPID(pid int64) ([]string, error) {
+	fpath := fmt.Sprintf("/proc/%d/cmdline", pid)
+	f, err := fileutil.OpenToRead(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	d, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return splitNUL(d), nil
+}
+
+// GetCwdByPID returns the current working directory of the process,
+// from the '/proc/$PID/cwd' link.
+func GetCwdByPID(pid int64) (string, error) {
+	return os.Readlink(fmt.Sprintf("/proc/%d/cwd", pid))
+}
+
+// GetExeByPID returns the path of the executable of the process, from
+// the '/proc/$PID/exe' link. Unlike 'GetProgram', it tells apart the
+// processes with the same name (e.g. "java"). The path has a " (deleted)"
+// suffix if the executable was replaced or removed since exec.
+func GetExeByPID(pid int64) (string, error) {
+	return os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+}
+
+// GetRootByPID returns the root directory of the process, from the
+// '/proc/$PID/root' link; "/" unless the process is in a chroot.
+// Container processes in another mount namespace also return "/".
+func GetRootByPID(pid int64) (string, error) {
+	return os.Readlink(fmt.Sprintf("/proc/%d/root", pid))
+}
+
+// splitNUL splits NUL-separated (and optionally NUL-terminated) data.
+func splitNUL(d []byte) []string {
+	d = bytes.TrimRight(d, "\x00")
+	if len(d) == 0 {
+		return []string{}
+	}
+	bs := bytes.Split(d, []byte{0})
+	ss := make([]string, len(bs))
+	for i, b := range bs {
+		ss[i] = string(b)
+	}
+	return ss
+}
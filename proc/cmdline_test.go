@@ -0,0 +1,52 @@
+package proc
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetCmdlineByPID(t *testing.T) {
+	args, err := GetCmdlineByPID(int64(os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(args, os.Args) {
+		t.Fatalf("expected %q, got %q", os.Args, args)
+	}
+	if ss := splitNUL([]byte("a\x00\x00b\x00")); !reflect.DeepEqual(ss, []string{"a", "", "b"}) {
+		t.Fatalf("unexpected split %q", ss)
+	}
+	if ss := splitNUL(nil); len(ss) != 0 {
+		t.Fatalf("unexpected split %q", ss)
+	}
+}
+
+func TestGetProcessLinksByPID(t *testing.T) {
+	pid := int64(os.Getpid())
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the link is resolved, while Getwd may return $PWD
+	if wd, err = filepath.EvalSymlinks(wd); err != nil {
+		t.Fatal(err)
+	}
+	if cwd, err := GetCwdByPID(pid); err != nil || cwd != wd {
+		t.Fatalf("cwd expected %q, got %q (%v)", wd, cwd, err)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, err := GetExeByPID(pid); err != nil || p != exe {
+		t.Fatalf("exe expected %q, got %q (%v)", exe, p, err)
+	}
+
+	if root, err := GetRootByPID(pid); err != nil || root != "/" {
+		t.Fatalf("root expected \"/\", got %q (%v)", root, err)
+	}
+}
@@ -0,0 +1,82 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/gyuho/linux-inspect/pkg/fileutil"
+)
+
+// CPUStat is a 'cpu' line in '/proc/stat', the amount of time
+// in clock ticks (USER_HZ) the CPU has spent in each mode.
+// Reference http://man7.org/linux/man-pages/man5/proc.5.html.
+type CPUStat struct {
+	// CPU is "cpu" for the aggregate of all CPUs,
+	// or "cpuN" for the N-th core.
+	CPU string
+
+	User      uint64
+	Nice      uint64
+	System    uint64
+	Idle      uint64
+	Iowait    uint64
+	Irq       uint64
+	Softirq   uint64
+	Steal     uint64
+	Guest     uint64
+	GuestNice uint64
+}
+
+// Total returns the total time, excluding guest time which is
+// already accounted in user and nice.
+func (c CPUStat) Total() uint64 {
+	return c.User + c.Nice + c.System + c.Idle + c.Iowait + c.Irq + c.Softirq + c.Steal
+}
+
+// GetCPUStats reads the 'cpu' lines in '/proc/stat'.
+// The first entry is the aggregate "cpu", followed by each core.
+func GetCPUStats() ([]CPUStat, error) {
+	f, err := fileutil.OpenToRead("/proc/stat")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	d, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return parseCPUStats(d)
+}
+
+func parseCPUStats(d []byte) ([]CPUStat, error) {
+	var cs []CPUStat
+
+	scanner := bufio.NewScanner(bytes.NewReader(d))
+	for scanner.Scan() {
+		fs := strings.Fields(scanner.Text())
+		if len(fs) < 5 || !strings.HasPrefix(fs[0], "cpu") {
+			continue
+		}
+
+		c := CPUStat{CPU: fs[0]}
+		vs := []*uint64{&c.User, &c.Nice, &c.System, &c.Idle, &c.Iowait, &c.Irq, &c.Softirq, &c.Steal, &c.Guest, &c.GuestNice}
+		// older kernels have fewer columns
+		for i, fv := range fs[1:] {
+			if i >= len(vs) {
+				break
+			}
+			v, err := strconv.ParseUint(fv, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%v when parsing %s %v", err, c.CPU, fv)
+			}
+			*vs[i] = v
+		}
+		cs = append(cs, c)
+	}
+	return cs, scanner.Err()
+}
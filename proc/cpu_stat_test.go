@@ -0,0 +1,40 @@
+package proc
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseCPUStats(t *testing.T) {
+	cs, err := parseCPUStats([]byte(`cpu  200 0 40 1600 10 0 2 0 0 0
+cpu0 150 0 30 700 10 0 2 0 0 0
+cpu1 50 0 10 900 0 0 0 0
+intr 293660 0 0 0
+ctxt 1234
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs) != 3 {
+		t.Fatalf("expected 3 cpu lines, got %d", len(cs))
+	}
+	if cs[0].CPU != "cpu" || cs[1].CPU != "cpu0" || cs[2].CPU != "cpu1" {
+		t.Fatalf("unexpected cpu names %+v", cs)
+	}
+	if cs[1].User != 150 || cs[1].Iowait != 10 || cs[1].Softirq != 2 {
+		t.Fatalf("unexpected cpu0 %+v", cs[1])
+	}
+	if cs[0].Total() != 1852 {
+		t.Fatalf("expected total 1852, got %d", cs[0].Total())
+	}
+}
+
+func TestGetCPUStats(t *testing.T) {
+	cs, err := GetCPUStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cs {
+		fmt.Printf("%+v\n", c)
+	}
+}
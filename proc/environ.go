@@ -0,0 +1,75 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/gyuho/linux-inspect/pkg/fileutil"
+)
+
+// GetEnvironByPID reads '/proc/$PID/environ' (the environment at
+// process start) into a map. Unreadable environ returns the
+// *os.PathError, so that 'os.IsPermission' can be used to check
+// permission errors.
+func GetEnvironByPID(pid int64) (map[string]string, error) {
+	fpath := fmt.Sprintf("/proc/%d/environ", pid)
+	f, err := fileutil.OpenToRead(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	d, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[string]string)
+	for _, kv := range splitNUL(d) {
+		if i := strings.IndexByte(kv, '='); i > 0 {
+			env[kv[:i]] = kv[i+1:]
+		}
+	}
+	return env, nil
+}
+
+// GetEnvironValueByPID reads the value of the environment variable
+// in '/proc/$PID/environ' (the environment at process start).
+// It stops reading as soon as the key is found, and returns false if
+// the key is absent. Unreadable environ returns the *os.PathError,
+// so that 'os.IsPermission' can be used to check permission errors.
+func GetEnvironValueByPID(pid int64, key string) (string, bool, error) {
+	fpath := fmt.Sprintf("/proc/%d/environ", pid)
+	f, err := fileutil.OpenToRead(fpath)
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+
+	prefix := key + "="
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 4096), 1<<20)
+	scanner.Split(scanNUL)
+	for scanner.Scan() {
+		if kv := scanner.Text(); strings.HasPrefix(kv, prefix) {
+			return kv[len(prefix):], true, nil
+		}
+	}
+	return "", false, scanner.Err()
+}
+
+// scanNUL is a bufio.SplitFunc for NUL-separated data.
+func scanNUL(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,37 @@
+package proc
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGetEnvironValueByPID(t *testing.T) {
+	pid := int64(os.Getpid())
+	for _, key := range []string{"PATH", "HOME"} {
+		expected, ok := os.LookupEnv(key)
+		if !ok {
+			continue
+		}
+		v, found, err := GetEnvironValueByPID(pid, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !found || v != expected {
+			t.Fatalf("%s expected %q, got %q (%v)", key, expected, v, found)
+		}
+	}
+
+	if _, found, err := GetEnvironValueByPID(pid, "LINUX_INSPECT_DOES_NOT_EXIST"); err != nil || found {
+		t.Fatalf("expected not found, got %v, %v", found, err)
+	}
+}
+
+func TestGetEnvironByPID(t *testing.T) {
+	env, err := GetEnvironByPID(int64(os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := os.LookupEnv("PATH"); ok && env["PATH"] != v {
+		t.Fatalf("PATH expected %q, got %q", v, env["PATH"])
+	}
+}
@@ -0,0 +1,220 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// GetFDCountByPID returns the number of open file descriptors
+// in '/proc/$PID/fd'.
+func GetFDCountByPID(pid int64) (int, error) {
+	names, err := readFDNames(pid)
+	if err != nil {
+		return 0, err
+	}
+	return len(names), nil
+}
+
+// readFDNames lists '/proc/$PID/fd'. For the calling process, the
+// descriptor opened for the listing itself is left out, so that it
+// is not counted as the process' own.
+func readFDNames(pid int64) ([]string, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/fd", pid))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	names, err := f.Readdirnames(-1)
+	if err != nil {
+		return nil, err
+	}
+	if pid == int64(os.Getpid()) {
+		self := strconv.FormatUint(uint64(f.Fd()), 10)
+		for i, name := range names {
+			if name == self {
+				names = append(names[:i], names[i+1:]...)
+				break
+			}
+		}
+	}
+	return names, nil
+}
+
+// FDType is the type of an open file descriptor.
+type FDType string
+
+const (
+	FDTypeFile    FDType = "file"
+	FDTypeSocket  FDType = "socket"
+	FDTypePipe    FDType = "pipe"
+	FDTypeEventFD FDType = "eventfd"
+	FDTypeInotify FDType = "inotify"
+	// FDTypeAnonInode is any other anonymous inode
+	// (e.g. "anon_inode:[eventpoll]", "anon_inode:[timerfd]").
+	FDTypeAnonInode FDType = "anon_inode"
+)
+
+// FD is an open file descriptor in '/proc/$PID/fd',
+// with its '/proc/$PID/fdinfo'.
+type FD struct {
+	FD   int64
+	Type FDType
+	// Target is the link target (e.g. "/var/log/syslog", "socket:[12345]",
+	// "anon_inode:[eventfd]").
+	Target string
+	// Inode is the inode of the file, socket or pipe.
+	Inode uint64
+
+	// Pos is the file offset.
+	Pos int64
+	// Flags are the open flags (e.g. 'syscall.O_RDWR'), from octal.
+	Flags uint64
+	// MntID is the mount ID (see '/proc/$PID/mountinfo').
+	MntID int64
+}
+
+// Mode returns the access mode like 'lsof': "r" for read,
+// "w" for write, and "u" for read and write.
+func (fd FD) Mode() string {
+	switch fd.Flags & syscall.O_ACCMODE {
+	case syscall.O_WRONLY:
+		return "w"
+	case syscall.O_RDWR:
+		return "u"
+	}
+	return "r"
+}
+
+// GetFDsByPID lists the open file descriptors in '/proc/$PID/fd',
+// sorted by FD. File descriptors closed while reading are skipped.
+func GetFDsByPID(pid int64) ([]FD, error) {
+	dir := fmt.Sprintf("/proc/%d/fd", pid)
+	names, err := readFDNames(pid)
+	if err != nil {
+		return nil, err
+	}
+	fds := make([]FD, 0, len(names))
+	for _, name := range names {
+		n, perr := strconv.ParseInt(name, 10, 64)
+		if perr != nil {
+			continue
+		}
+		fpath := filepath.Join(dir, name)
+		link, lerr := os.Readlink(fpath)
+		if lerr != nil {
+			// fd closed since Readdirnames
+			continue
+		}
+		fd := FD{FD: n}
+		fd.Type, fd.Inode = parseFDLink(link)
+		fd.Target = link
+		if fd.Type == FDTypeFile {
+			if fi, serr := os.Stat(fpath); serr == nil {
+				if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+					fd.Inode = st.Ino
+				}
+			}
+		}
+
+		d, rerr := ioutil.ReadFile(fmt.Sprintf("/proc/%d/fdinfo/%d", pid, n))
+		if rerr != nil {
+			continue
+		}
+		if err = parseFDInfo(&fd, d); err != nil {
+			return nil, err
+		}
+		fds = append(fds, fd)
+	}
+	sort.Slice(fds, func(i, j int) bool { return fds[i].FD < fds[j].FD })
+	return fds, nil
+}
+
+// parseFDLink classifies the link target of the file descriptor.
+func parseFDLink(link string) (FDType, uint64) {
+	if inode, ok := parseSocketLink(link); ok {
+		return FDTypeSocket, inode
+	}
+	if strings.HasPrefix(link, "pipe:[") && strings.HasSuffix(link, "]") {
+		inode, _ := strconv.ParseUint(link[len("pipe:["):len(link)-1], 10, 64)
+		return FDTypePipe, inode
+	}
+	if strings.HasPrefix(link, "anon_inode:") {
+		// "anon_inode:[eventfd]", "anon_inode:inotify"
+		switch strings.Trim(strings.TrimPrefix(link, "anon_inode:"), "[]") {
+		case "eventfd":
+			return FDTypeEventFD, 0
+		case "inotify":
+			return FDTypeInotify, 0
+		}
+		return FDTypeAnonInode, 0
+	}
+	return FDTypeFile, 0
+}
+
+// parseFDInfo parses the lines like 'pos:	0' and 'flags:	02004002'.
+// Type-specific lines (e.g. 'eventfd-count', 'inotify wd') are ignored.
+func parseFDInfo(fd *FD, d []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(d))
+	for scanner.Scan() {
+		kv := strings.SplitN(scanner.Text(), ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		v := strings.TrimSpace(kv[1])
+		var err error
+		switch kv[0] {
+		case "pos":
+			fd.Pos, err = strconv.ParseInt(v, 10, 64)
+		case "flags":
+			fd.Flags, err = strconv.ParseUint(v, 8, 64)
+		case "mnt_id":
+			fd.MntID, err = strconv.ParseInt(v, 10, 64)
+		}
+		if err != nil {
+			return fmt.Errorf("%v when parsing %q", err, scanner.Text())
+		}
+	}
+	return scanner.Err()
+}
+
+// FDUsage is the number of open file descriptors of a process
+// against its RLIMIT_NOFILE.
+type FDUsage struct {
+	Open int
+	// Soft, Hard are the limits from '/proc/$PID/limits',
+	// -1 if unlimited.
+	Soft int64
+	Hard int64
+}
+
+// Percent returns the open file descriptors in percent of the soft limit,
+// 0 if unlimited.
+func (u FDUsage) Percent() float64 {
+	if u.Soft <= 0 {
+		return 0
+	}
+	return float64(u.Open) / float64(u.Soft) * 100
+}
+
+// GetFDUsageByPID returns the open file descriptor count and
+// the "Max open files" limits of the process.
+func GetFDUsageByPID(pid int64) (FDUsage, error) {
+	l, err := GetLimitsByPID(pid)
+	if err != nil {
+		return FDUsage{}, err
+	}
+	u := FDUsage{Soft: l.OpenFiles.Soft, Hard: l.OpenFiles.Hard}
+	if u.Open, err = GetFDCountByPID(pid); err != nil {
+		return FDUsage{}, err
+	}
+	return u, nil
+}
@@ -0,0 +1,114 @@
+package proc
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestParseFDLink(t *testing.T) {
+	tests := []struct {
+		link  string
+		tp    FDType
+		inode uint64
+	}{
+		{"/var/log/syslog", FDTypeFile, 0},
+		{"socket:[74176]", FDTypeSocket, 74176},
+		{"pipe:[1234]", FDTypePipe, 1234},
+		{"anon_inode:[eventfd]", FDTypeEventFD, 0},
+		{"anon_inode:inotify", FDTypeInotify, 0},
+		{"anon_inode:[eventpoll]", FDTypeAnonInode, 0},
+	}
+	for i, tt := range tests {
+		tp, inode := parseFDLink(tt.link)
+		if tp != tt.tp || inode != tt.inode {
+			t.Fatalf("#%d: expected %q %d, got %q %d", i, tt.tp, tt.inode, tp, inode)
+		}
+	}
+}
+
+func TestParseFDInfo(t *testing.T) {
+	var fd FD
+	if err := parseFDInfo(&fd, []byte(`pos:	42
+flags:	02004002
+mnt_id:	15
+ino:	1234
+eventfd-count:                0
+`)); err != nil {
+		t.Fatal(err)
+	}
+	if fd.Pos != 42 || fd.Flags != 02004002 || fd.MntID != 15 || fd.Mode() != "u" {
+		t.Fatalf("unexpected fd %+v", fd)
+	}
+	if err := parseFDInfo(&fd, []byte("flags:	9\n")); err == nil {
+		t.Fatal("expected error on non-octal flags")
+	}
+}
+
+func TestGetFDsByPID(t *testing.T) {
+	rd, wr, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rd.Close()
+	defer wr.Close()
+
+	fds, err := GetFDsByPID(int64(os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := 0
+	for _, fd := range fds {
+		switch fd.FD {
+		case int64(rd.Fd()):
+			if fd.Type != FDTypePipe || fd.Inode == 0 || fd.Mode() != "r" {
+				t.Fatalf("unexpected read end %+v", fd)
+			}
+			found++
+		case int64(wr.Fd()):
+			if fd.Type != FDTypePipe || fd.Mode() != "w" || fd.Flags&syscall.O_CLOEXEC == 0 {
+				t.Fatalf("unexpected write end %+v", fd)
+			}
+			found++
+		}
+	}
+	if found != 2 {
+		t.Fatalf("pipe not found in %+v", fds)
+	}
+}
+
+func TestGetFDsByPIDSelf(t *testing.T) {
+	pid := int64(os.Getpid())
+	fds, err := GetFDsByPID(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := fmt.Sprintf("/proc/%d/fd", pid)
+	for _, fd := range fds {
+		if fd.Target == dir {
+			t.Fatalf("unexpected listing fd %+v", fd)
+		}
+	}
+	n, err := GetFDCountByPID(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(fds) {
+		t.Fatalf("expected %d fds, got %d", len(fds), n)
+	}
+}
+
+func TestGetFDUsageByPID(t *testing.T) {
+	u, err := GetFDUsageByPID(int64(os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rlim syscall.Rlimit
+	if err = syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlim); err != nil {
+		t.Fatal(err)
+	}
+	if u.Open == 0 || u.Soft != int64(rlim.Cur) || u.Percent() <= 0 {
+		t.Fatalf("unexpected usage %+v (expected soft limit %d)", u, rlim.Cur)
+	}
+}
@@ -168,6 +168,122 @@ type IO struct {
 	CancelledWriteBytesParsedBytes string `yaml:"cancelled_write_bytes_parsed_bytes"`
 }
 
+// Meminfo is '/proc/meminfo' in Linux.
+type Meminfo struct {
+	// MemTotal is total usable RAM (physical RAM minus a few reserved bits and the kernel binary code).
+	MemTotal            string `yaml:"MemTotal"`
+	MemTotalBytesN      uint64 `yaml:"MemTotal_bytes_n"`
+	MemTotalParsedBytes string `yaml:"MemTotal_parsed_bytes"`
+	// MemFree is sum of LowFree+HighFree.
+	MemFree            string `yaml:"MemFree"`
+	MemFreeBytesN      uint64 `yaml:"MemFree_bytes_n"`
+	MemFreeParsedBytes string `yaml:"MemFree_parsed_bytes"`
+	// MemAvailable is estimate of how much memory is available for starting new applications, without swapping.
+	MemAvailable            string `yaml:"MemAvailable"`
+	MemAvailableBytesN      uint64 `yaml:"MemAvailable_bytes_n"`
+	MemAvailableParsedBytes string `yaml:"MemAvailable_parsed_bytes"`
+	// Buffers is relatively temporary storage for raw disk blocks.
+	Buffers            string `yaml:"Buffers"`
+	BuffersBytesN      uint64 `yaml:"Buffers_bytes_n"`
+	BuffersParsedBytes string `yaml:"Buffers_parsed_bytes"`
+	// Cached is in-memory cache for files read from the disk (the page cache), not including SwapCached.
+	Cached            string `yaml:"Cached"`
+	CachedBytesN      uint64 `yaml:"Cached_bytes_n"`
+	CachedParsedBytes string `yaml:"Cached_parsed_bytes"`
+	// SwapCached is memory that once was swapped out, is swapped back in but still also is in the swap file.
+	SwapCached            string `yaml:"SwapCached"`
+	SwapCachedBytesN      uint64 `yaml:"SwapCached_bytes_n"`
+	SwapCachedParsedBytes string `yaml:"SwapCached_parsed_bytes"`
+	// Active is memory that has been used more recently and usually not reclaimed unless absolutely necessary.
+	Active            string `yaml:"Active"`
+	ActiveBytesN      uint64 `yaml:"Active_bytes_n"`
+	ActiveParsedBytes string `yaml:"Active_parsed_bytes"`
+	// Inactive is memory which has been less recently used, more eligible to be reclaimed for other purposes.
+	Inactive            string `yaml:"Inactive"`
+	InactiveBytesN      uint64 `yaml:"Inactive_bytes_n"`
+	InactiveParsedBytes string `yaml:"Inactive_parsed_bytes"`
+	// SwapTotal is total amount of swap space available.
+	SwapTotal            string `yaml:"SwapTotal"`
+	SwapTotalBytesN      uint64 `yaml:"SwapTotal_bytes_n"`
+	SwapTotalParsedBytes string `yaml:"SwapTotal_parsed_bytes"`
+	// SwapFree is amount of swap space that is currently unused.
+	SwapFree            string `yaml:"SwapFree"`
+	SwapFreeBytesN      uint64 `yaml:"SwapFree_bytes_n"`
+	SwapFreeParsedBytes string `yaml:"SwapFree_parsed_bytes"`
+	// Dirty is memory which is waiting to get written back to the disk.
+	Dirty            string `yaml:"Dirty"`
+	DirtyBytesN      uint64 `yaml:"Dirty_bytes_n"`
+	DirtyParsedBytes string `yaml:"Dirty_parsed_bytes"`
+	// Writeback is memory which is actively being written back to the disk.
+	Writeback            string `yaml:"Writeback"`
+	WritebackBytesN      uint64 `yaml:"Writeback_bytes_n"`
+	WritebackParsedBytes string `yaml:"Writeback_parsed_bytes"`
+	// AnonPages is non-file backed pages mapped into user-space page tables.
+	AnonPages            string `yaml:"AnonPages"`
+	AnonPagesBytesN      uint64 `yaml:"AnonPages_bytes_n"`
+	AnonPagesParsedBytes string `yaml:"AnonPages_parsed_bytes"`
+	// Mapped is files which have been mapped into memory (with mmap), such as libraries.
+	Mapped            string `yaml:"Mapped"`
+	MappedBytesN      uint64 `yaml:"Mapped_bytes_n"`
+	MappedParsedBytes string `yaml:"Mapped_parsed_bytes"`
+	// Shmem is amount of memory consumed in tmpfs filesystems.
+	Shmem            string `yaml:"Shmem"`
+	ShmemBytesN      uint64 `yaml:"Shmem_bytes_n"`
+	ShmemParsedBytes string `yaml:"Shmem_parsed_bytes"`
+	// Slab is in-kernel data structures cache.
+	Slab            string `yaml:"Slab"`
+	SlabBytesN      uint64 `yaml:"Slab_bytes_n"`
+	SlabParsedBytes string `yaml:"Slab_parsed_bytes"`
+	// SReclaimable is part of Slab, that might be reclaimed, such as caches.
+	SReclaimable            string `yaml:"SReclaimable"`
+	SReclaimableBytesN      uint64 `yaml:"SReclaimable_bytes_n"`
+	SReclaimableParsedBytes string `yaml:"SReclaimable_parsed_bytes"`
+	// SUnreclaim is part of Slab, that cannot be reclaimed on memory pressure.
+	SUnreclaim            string `yaml:"SUnreclaim"`
+	SUnreclaimBytesN      uint64 `yaml:"SUnreclaim_bytes_n"`
+	SUnreclaimParsedBytes string `yaml:"SUnreclaim_parsed_bytes"`
+	// KernelStack is amount of memory allocated to kernel stacks.
+	KernelStack            string `yaml:"KernelStack"`
+	KernelStackBytesN      uint64 `yaml:"KernelStack_bytes_n"`
+	KernelStackParsedBytes string `yaml:"KernelStack_parsed_bytes"`
+	// PageTables is amount of memory dedicated to the lowest level of page tables.
+	PageTables            string `yaml:"PageTables"`
+	PageTablesBytesN      uint64 `yaml:"PageTables_bytes_n"`
+	PageTablesParsedBytes string `yaml:"PageTables_parsed_bytes"`
+	// CommitLimit is total amount of memory currently available to be allocated on the system, based on the overcommit ratio.
+	CommitLimit            string `yaml:"CommitLimit"`
+	CommitLimitBytesN      uint64 `yaml:"CommitLimit_bytes_n"`
+	CommitLimitParsedBytes string `yaml:"CommitLimit_parsed_bytes"`
+	// CommittedAS is amount of memory presently allocated on the system.
+	CommittedAS            string `yaml:"Committed_AS"`
+	CommittedASBytesN      uint64 `yaml:"Committed_AS_bytes_n"`
+	CommittedASParsedBytes string `yaml:"Committed_AS_parsed_bytes"`
+	// VmallocTotal is total size of vmalloc memory area.
+	VmallocTotal            string `yaml:"VmallocTotal"`
+	VmallocTotalBytesN      uint64 `yaml:"VmallocTotal_bytes_n"`
+	VmallocTotalParsedBytes string `yaml:"VmallocTotal_parsed_bytes"`
+	// VmallocUsed is amount of vmalloc area which is used.
+	VmallocUsed            string `yaml:"VmallocUsed"`
+	VmallocUsedBytesN      uint64 `yaml:"VmallocUsed_bytes_n"`
+	VmallocUsedParsedBytes string `yaml:"VmallocUsed_parsed_bytes"`
+	// AnonHugePages is non-file backed huge pages mapped into user-space page tables.
+	AnonHugePages            string `yaml:"AnonHugePages"`
+	AnonHugePagesBytesN      uint64 `yaml:"AnonHugePages_bytes_n"`
+	AnonHugePagesParsedBytes string `yaml:"AnonHugePages_parsed_bytes"`
+	// Hugepagesize is size of huge pages.
+	Hugepagesize            string `yaml:"Hugepagesize"`
+	HugepagesizeBytesN      uint64 `yaml:"Hugepagesize_bytes_n"`
+	HugepagesizeParsedBytes string `yaml:"Hugepagesize_parsed_bytes"`
+	// HugePagesTotal is size of the pool of huge pages.
+	HugePagesTotal uint64 `yaml:"HugePages_Total"`
+	// HugePagesFree is number of huge pages in the pool that are not yet allocated.
+	HugePagesFree uint64 `yaml:"HugePages_Free"`
+	// HugePagesRsvd is number of huge pages for which a commitment to allocate from the pool has been made, but no allocation has yet been made.
+	HugePagesRsvd uint64 `yaml:"HugePages_Rsvd"`
+	// HugePagesSurp is number of huge pages in the pool above the value in /proc/sys/vm/nr_hugepages.
+	HugePagesSurp uint64 `yaml:"HugePages_Surp"`
+}
+
 // Stat is '/proc/$PID/stat' in Linux.
 type Stat struct {
 	// Pid is process ID.
@@ -318,6 +434,8 @@ type Status struct {
 	NSpgid string `yaml:"NSpgid"`
 	// NSsid is descendant namespace session ID hierarchy Session ID in each of the PID namespaces of which [pid] is a member.
 	NSsid string `yaml:"NSsid"`
+	// Kthread is 1 if the process is a kernel thread, 0 otherwise (since Linux 6.4).
+	Kthread uint64 `yaml:"Kthread"`
 	// VmPeak is peak virtual memory usage. Vm includes physical memory and swap.
 	VmPeak            string `yaml:"VmPeak"`
 	VmPeakBytesN      uint64 `yaml:"VmPeak_bytes_n"`
@@ -342,6 +460,18 @@ type Status struct {
 	VmRSS            string `yaml:"VmRSS"`
 	VmRSSBytesN      uint64 `yaml:"VmRSS_bytes_n"`
 	VmRSSParsedBytes string `yaml:"VmRSS_parsed_bytes"`
+	// RssAnon is size of resident anonymous memory.
+	RssAnon            string `yaml:"RssAnon"`
+	RssAnonBytesN      uint64 `yaml:"RssAnon_bytes_n"`
+	RssAnonParsedBytes string `yaml:"RssAnon_parsed_bytes"`
+	// RssFile is size of resident file mappings.
+	RssFile            string `yaml:"RssFile"`
+	RssFileBytesN      uint64 `yaml:"RssFile_bytes_n"`
+	RssFileParsedBytes string `yaml:"RssFile_parsed_bytes"`
+	// RssShmem is size of resident shared memory (includes System V shared memory, mappings from tmpfs, and shared anonymous mappings).
+	RssShmem            string `yaml:"RssShmem"`
+	RssShmemBytesN      uint64 `yaml:"RssShmem_bytes_n"`
+	RssShmemParsedBytes string `yaml:"RssShmem_parsed_bytes"`
 	// VmData is size of data segment.
 	VmData            string `yaml:"VmData"`
 	VmDataBytesN      uint64 `yaml:"VmData_bytes_n"`
@@ -374,6 +504,12 @@ type Status struct {
 	HugetlbPages            string `yaml:"HugetlbPages"`
 	HugetlbPagesBytesN      uint64 `yaml:"HugetlbPages_bytes_n"`
 	HugetlbPagesParsedBytes string `yaml:"HugetlbPages_parsed_bytes"`
+	// CoreDumping is 1 if the process is currently dumping core.
+	CoreDumping uint64 `yaml:"CoreDumping"`
+	// THPEnabled is 1 if transparent huge pages are enabled for the process (not disabled by prctl).
+	THPEnabled uint64 `yaml:"THP_enabled"`
+	// UntagMask is mask of the address bits that are not tagged (e.g. by Intel LAM or ARM TBI).
+	UntagMask string `yaml:"untag_mask"`
 	// Threads is number of threads in process containing this thread (process).
 	Threads uint64 `yaml:"Threads"`
 	// SigQ is queued signals for the real user ID of this process (queued signals / limits).
@@ -398,8 +534,16 @@ type Status struct {
 	CapBnd string `yaml:"CapBnd"`
 	// CapAmb is ambient capability set.
 	CapAmb string `yaml:"CapAmb"`
+	// NoNewPrivs is value of the no_new_privs bit.
+	NoNewPrivs uint64 `yaml:"NoNewPrivs"`
 	// Seccomp is seccomp mode of the process (0 means SECCOMP_MODE_DISABLED; 1 means SECCOMP_MODE_STRICT; 2 means SECCOMP_MODE_FILTER).
 	Seccomp uint64 `yaml:"Seccomp"`
+	// SeccompFilters is number of seccomp filters attached to the process.
+	SeccompFilters uint64 `yaml:"Seccomp_filters"`
+	// SpeculationStoreBypass is speculation flaw mitigation state (e.g. thread vulnerable).
+	SpeculationStoreBypass string `yaml:"Speculation_Store_Bypass"`
+	// SpeculationIndirectBranch is indirect branch speculation mode (e.g. conditional enabled).
+	SpeculationIndirectBranch string `yaml:"SpeculationIndirectBranch"`
 	// CpusAllowed is mask of CPUs on which this process may run.
 	CpusAllowed string `yaml:"Cpus_allowed"`
 	// CpusAllowedList is list of CPUs on which this process may run.
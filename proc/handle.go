@@ -0,0 +1,131 @@
+package proc
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// Syscall numbers are the same on all architectures since Linux 5.1/5.3.
+// The vendored 'golang.org/x/sys/unix' predates them.
+const (
+	sysPidfdSendSignal = 424
+	sysPidfdOpen       = 434
+)
+
+// ErrProcessChanged is returned when the process of a 'ProcHandle'
+// has exited, or its PID has been reused by another process.
+var ErrProcessChanged = errors.New("process exited or PID reused")
+
+// ProcHandle is a stable handle to a process instance, so that
+// a sequence of reads refers to the same process even if the PID
+// gets reused in between.
+//
+// It holds a pidfd (Linux 5.3+, 'pidfd_open') to detect that the
+// process has exited. On older kernels, it falls back to comparing
+// the process start time in '/proc/$PID/stat'. The start time is
+// checked on every read in both cases, since '/proc/$PID' itself
+// cannot be opened relative to a pidfd.
+type ProcHandle struct {
+	PID int64
+
+	// Starttime is the process start time in clock ticks after boot,
+	// which identifies the process instance together with PID.
+	Starttime uint64
+
+	// pidfd is -1 when 'pidfd_open' is not supported.
+	pidfd int
+}
+
+// OpenProcHandle opens a handle to the current process of the PID.
+// The handle must be closed with 'Close'.
+func OpenProcHandle(pid int64) (*ProcHandle, error) {
+	fd, _, errno := unix.Syscall(sysPidfdOpen, uintptr(pid), 0, 0)
+	pidfd := int(fd)
+	switch errno {
+	case 0:
+	case unix.ENOSYS, unix.EPERM:
+		// older kernel or blocked by seccomp
+		pidfd = -1
+	default:
+		return nil, fmt.Errorf("pidfd_open %d: %v", pid, errno)
+	}
+
+	// read start time after pidfd_open, so that the pidfd and
+	// start time refer to the same process
+	st, err := GetStatByPID(pid)
+	if err != nil {
+		if pidfd >= 0 {
+			unix.Close(pidfd)
+		}
+		return nil, err
+	}
+	return &ProcHandle{PID: pid, Starttime: st.Starttime, pidfd: pidfd}, nil
+}
+
+// HasPidfd returns true if the handle is backed by a pidfd.
+func (h *ProcHandle) HasPidfd() bool { return h.pidfd >= 0 }
+
+// Close releases the pidfd.
+func (h *ProcHandle) Close() error {
+	if h.pidfd < 0 {
+		return nil
+	}
+	err := unix.Close(h.pidfd)
+	h.pidfd = -1
+	return err
+}
+
+// Alive returns nil if the process of the handle is still running.
+// Otherwise, it returns 'ErrProcessChanged'.
+func (h *ProcHandle) Alive() error {
+	if h.pidfd >= 0 {
+		// signal 0 only checks the process existence
+		_, _, errno := unix.Syscall6(sysPidfdSendSignal, uintptr(h.pidfd), 0, 0, 0, 0, 0)
+		if errno == unix.ESRCH {
+			return ErrProcessChanged
+		}
+	}
+	st, err := GetStatByPID(h.PID)
+	if err != nil {
+		return ErrProcessChanged
+	}
+	if st.Starttime != h.Starttime {
+		return ErrProcessChanged
+	}
+	return nil
+}
+
+// Stat reads '/proc/$PID/stat' of the handle's process.
+func (h *ProcHandle) Stat() (Stat, error) {
+	st, err := GetStatByPID(h.PID)
+	if err != nil {
+		if h.Alive() != nil {
+			return Stat{}, ErrProcessChanged
+		}
+		return Stat{}, err
+	}
+	if st.Starttime != h.Starttime {
+		return Stat{}, ErrProcessChanged
+	}
+	return st, nil
+}
+
+// Status reads '/proc/$PID/status' of the handle's process.
+func (h *ProcHandle) Status() (Status, error) {
+	s, err := GetStatusByPID(h.PID)
+	if aerr := h.Alive(); aerr != nil {
+		return Status{}, aerr
+	}
+	return s, err
+}
+
+// IO reads '/proc/$PID/io' of the handle's process.
+func (h *ProcHandle) IO() (IO, error) {
+	s, err := GetIOByPID(h.PID)
+	if aerr := h.Alive(); aerr != nil {
+		return IO{}, aerr
+	}
+	return s, err
+}
@@ -0,0 +1,56 @@
+package proc
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestProcHandle(t *testing.T) {
+	h, err := OpenProcHandle(int64(os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	fmt.Println("HasPidfd:", h.HasPidfd())
+
+	if err = h.Alive(); err != nil {
+		t.Fatal(err)
+	}
+	st, err := h.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Starttime != h.Starttime {
+		t.Fatalf("starttime expected %d, got %d", h.Starttime, st.Starttime)
+	}
+	if _, err = h.Status(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = h.IO(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcHandleExited(t *testing.T) {
+	cmd := exec.Command("sleep", "10")
+	if err := cmd.Start(); err != nil {
+		t.Skip(err)
+	}
+	h, err := OpenProcHandle(int64(cmd.Process.Pid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	cmd.Process.Kill()
+	cmd.Wait()
+
+	if err = h.Alive(); err != ErrProcessChanged {
+		t.Fatalf("expected %v, got %v", ErrProcessChanged, err)
+	}
+	if _, err = h.Stat(); err != ErrProcessChanged {
+		t.Fatalf("expected %v, got %v", ErrProcessChanged, err)
+	}
+}
@@ -3,8 +3,10 @@ package proc
 import (
 	"fmt"
 	"io/ioutil"
+	"time"
 
 	"github.com/gyuho/linux-inspect/pkg/fileutil"
+	"github.com/gyuho/linux-inspect/pkg/timeutil"
 
 	humanize "github.com/dustin/go-humanize"
 	yaml "gopkg.in/yaml.v2"
@@ -46,3 +48,83 @@ func GetIOByPID(pid int64) (s IO, err error) {
 
 	return rs, nil
 }
+
+// IORate is the change of '/proc/$PID/io' counters between two reads,
+// to find the processes doing the most I/O. 'ReadBytes' and 'WriteBytes'
+// are what reached the storage layer, while 'Rchar' and 'Wchar' include
+// reads and writes served by the page cache, sockets and pipes.
+type IORate struct {
+	Interval time.Duration
+
+	// Deltas are in bytes, or in operations for 'Syscr' and 'Syscw'.
+	RcharDelta               uint64
+	WcharDelta               uint64
+	SyscrDelta               uint64
+	SyscwDelta               uint64
+	ReadBytesDelta           uint64
+	WriteBytesDelta          uint64
+	CancelledWriteBytesDelta uint64
+
+	// Rates are deltas per second.
+	RcharPerSecond               float64
+	WcharPerSecond               float64
+	SyscrPerSecond               float64
+	SyscwPerSecond               float64
+	ReadBytesPerSecond           float64
+	WriteBytesPerSecond          float64
+	CancelledWriteBytesPerSecond float64
+}
+
+// GetIORateByPID reads '/proc/$PID/io' twice, 'interval' apart,
+// and returns the change rates.
+func GetIORateByPID(pid int64, interval time.Duration) (IORate, error) {
+	return GetIORateByPIDWithClock(pid, interval, timeutil.RealClock)
+}
+
+// GetIORateByPIDWithClock is 'GetIORateByPID' waiting and timing
+// the interval with the clock.
+func GetIORateByPIDWithClock(pid int64, interval time.Duration, clock timeutil.Clock) (IORate, error) {
+	s1, err := GetIOByPID(pid)
+	if err != nil {
+		return IORate{}, err
+	}
+	now := clock.Now()
+	<-clock.After(interval)
+	s2, err := GetIOByPID(pid)
+	if err != nil {
+		return IORate{}, err
+	}
+	return ioRate(s1, s2, clock.Now().Sub(now)), nil
+}
+
+func ioRate(s1, s2 IO, took time.Duration) IORate {
+	r := IORate{
+		Interval:                 took,
+		RcharDelta:               counterDelta(s1.Rchar, s2.Rchar),
+		WcharDelta:               counterDelta(s1.Wchar, s2.Wchar),
+		SyscrDelta:               counterDelta(s1.Syscr, s2.Syscr),
+		SyscwDelta:               counterDelta(s1.Syscw, s2.Syscw),
+		ReadBytesDelta:           counterDelta(s1.ReadBytes, s2.ReadBytes),
+		WriteBytesDelta:          counterDelta(s1.WriteBytes, s2.WriteBytes),
+		CancelledWriteBytesDelta: counterDelta(s1.CancelledWriteBytes, s2.CancelledWriteBytes),
+	}
+	if sec := took.Seconds(); sec > 0 {
+		r.RcharPerSecond = float64(r.RcharDelta) / sec
+		r.WcharPerSecond = float64(r.WcharDelta) / sec
+		r.SyscrPerSecond = float64(r.SyscrDelta) / sec
+		r.SyscwPerSecond = float64(r.SyscwDelta) / sec
+		r.ReadBytesPerSecond = float64(r.ReadBytesDelta) / sec
+		r.WriteBytesPerSecond = float64(r.WriteBytesDelta) / sec
+		r.CancelledWriteBytesPerSecond = float64(r.CancelledWriteBytesDelta) / sec
+	}
+	return r
+}
+
+// counterDelta returns the increase of the counter,
+// 0 if it went backwards (e.g. the PID was reused).
+func counterDelta(v1, v2 uint64) uint64 {
+	if v2 < v1 {
+		return 0
+	}
+	return v2 - v1
+}
@@ -3,6 +3,7 @@ package proc
 import (
 	"fmt"
 	"testing"
+	"time"
 )
 
 func TestGetIOByPID(t *testing.T) {
@@ -36,3 +37,25 @@ func TestGetIOByPID(t *testing.T) {
 		t.Fatalf("expected same, got %d, %d", ns.WriteBytes, ns.WriteBytesBytesN)
 	}
 }
+
+func Test_ioRate(t *testing.T) {
+	s1 := IO{Rchar: 1000, Wchar: 500, Syscr: 10, Syscw: 4, ReadBytes: 4096, WriteBytes: 8192}
+	s2 := IO{Rchar: 3000, Wchar: 500, Syscr: 30, Syscw: 8, ReadBytes: 0, WriteBytes: 16384, CancelledWriteBytes: 4096}
+	r := ioRate(s1, s2, 2*time.Second)
+	if r.RcharDelta != 2000 || r.RcharPerSecond != 1000 {
+		t.Fatalf("unexpected rchar %+v", r)
+	}
+	if r.WcharDelta != 0 || r.SyscrPerSecond != 10 || r.SyscwPerSecond != 2 {
+		t.Fatalf("unexpected rate %+v", r)
+	}
+	// counters going backwards are not negative rates
+	if r.ReadBytesDelta != 0 || r.ReadBytesPerSecond != 0 {
+		t.Fatalf("unexpected read_bytes %+v", r)
+	}
+	if r.WriteBytesPerSecond != 4096 || r.CancelledWriteBytesPerSecond != 2048 {
+		t.Fatalf("unexpected write_bytes %+v", r)
+	}
+	if r = ioRate(s1, s2, 0); r.RcharDelta != 2000 || r.RcharPerSecond != 0 {
+		t.Fatalf("unexpected zero-interval rate %+v", r)
+	}
+}
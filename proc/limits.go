@@ -0,0 +1,127 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// Limit is the soft and hard limit of a resource, -1 if unlimited.
+type Limit struct {
+	Soft int64
+	Hard int64
+}
+
+// Limits is '/proc/$PID/limits', the resource limits of a process
+// (see 'GetRlimitByPID' for the resource units).
+// Reference http://man7.org/linux/man-pages/man5/proc.5.html.
+type Limits struct {
+	CPUTime          Limit // RLIMIT_CPU, in seconds
+	FileSize         Limit // RLIMIT_FSIZE, in bytes
+	DataSize         Limit // RLIMIT_DATA, in bytes
+	StackSize        Limit // RLIMIT_STACK, in bytes
+	CoreFileSize     Limit // RLIMIT_CORE, in bytes
+	ResidentSet      Limit // RLIMIT_RSS, in bytes
+	Processes        Limit // RLIMIT_NPROC
+	OpenFiles        Limit // RLIMIT_NOFILE
+	LockedMemory     Limit // RLIMIT_MEMLOCK, in bytes
+	AddressSpace     Limit // RLIMIT_AS, in bytes
+	FileLocks        Limit // RLIMIT_LOCKS
+	PendingSignals   Limit // RLIMIT_SIGPENDING
+	MsgqueueSize     Limit // RLIMIT_MSGQUEUE, in bytes
+	NicePriority     Limit // RLIMIT_NICE
+	RealtimePriority Limit // RLIMIT_RTPRIO
+	RealtimeTimeout  Limit // RLIMIT_RTTIME, in microseconds
+}
+
+// fields maps the limit names in '/proc/$PID/limits' to the fields.
+func (l *Limits) fields() map[string]*Limit {
+	return map[string]*Limit{
+		"Max cpu time":          &l.CPUTime,
+		"Max file size":         &l.FileSize,
+		"Max data size":         &l.DataSize,
+		"Max stack size":        &l.StackSize,
+		"Max core file size":    &l.CoreFileSize,
+		"Max resident set":      &l.ResidentSet,
+		"Max processes":         &l.Processes,
+		"Max open files":        &l.OpenFiles,
+		"Max locked memory":     &l.LockedMemory,
+		"Max address space":     &l.AddressSpace,
+		"Max file locks":        &l.FileLocks,
+		"Max pending signals":   &l.PendingSignals,
+		"Max msgqueue size":     &l.MsgqueueSize,
+		"Max nice priority":     &l.NicePriority,
+		"Max realtime priority": &l.RealtimePriority,
+		"Max realtime timeout":  &l.RealtimeTimeout,
+	}
+}
+
+// limitsNameWidth is the width of the 'Limit' column in '/proc/$PID/limits',
+// whose names contain spaces (e.g. "Max open files").
+const limitsNameWidth = 25
+
+// GetLimitsByPID reads '/proc/$PID/limits', which is readable
+// by all users unlike 'GetRlimitByPID' on other users' processes.
+func GetLimitsByPID(pid int64) (Limits, error) {
+	d, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/limits", pid))
+	if err != nil {
+		return Limits{}, err
+	}
+	return parseLimits(d)
+}
+
+// parseLimits parses the limits table. Unknown limits are ignored.
+func parseLimits(d []byte) (Limits, error) {
+	var l Limits
+	fields := l.fields()
+	found := 0
+	scanner := bufio.NewScanner(bytes.NewReader(d))
+	for scanner.Scan() {
+		name, soft, hard, err := parseLimitLine(scanner.Text())
+		if err != nil {
+			return Limits{}, err
+		}
+		if p, ok := fields[name]; ok {
+			*p = Limit{Soft: soft, Hard: hard}
+			found++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Limits{}, err
+	}
+	if found == 0 {
+		return Limits{}, fmt.Errorf("no limits found in %q", d)
+	}
+	return l, nil
+}
+
+// parseLimitLine parses the line like
+// 'Max open files            1024                 524288               files'.
+// The header line is returned with an empty name.
+func parseLimitLine(line string) (name string, soft, hard int64, err error) {
+	if len(line) <= limitsNameWidth || strings.HasPrefix(line, "Limit ") {
+		return "", 0, 0, nil
+	}
+	name = strings.TrimSpace(line[:limitsNameWidth])
+	fs := strings.Fields(line[limitsNameWidth:])
+	if len(fs) < 2 {
+		return "", 0, 0, fmt.Errorf("unexpected limits line %q", line)
+	}
+	if soft, err = parseLimitValue(fs[0]); err != nil {
+		return "", 0, 0, fmt.Errorf("%v when parsing %q", err, line)
+	}
+	if hard, err = parseLimitValue(fs[1]); err != nil {
+		return "", 0, 0, fmt.Errorf("%v when parsing %q", err, line)
+	}
+	return name, soft, hard, nil
+}
+
+func parseLimitValue(s string) (int64, error) {
+	if s == "unlimited" {
+		return -1, nil
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
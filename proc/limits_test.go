@@ -0,0 +1,56 @@
+package proc
+
+import (
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestParseLimits(t *testing.T) {
+	l, err := parseLimits([]byte(`Limit                     Soft Limit           Hard Limit           Units     
+Max cpu time              unlimited            unlimited            seconds   
+Max stack size            8388608              unlimited            bytes     
+Max open files            1024                 524288               files     
+Max realtime timeout      unlimited            unlimited            us        
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.CPUTime != (Limit{Soft: -1, Hard: -1}) {
+		t.Fatalf("unexpected cpu time %+v", l.CPUTime)
+	}
+	if l.StackSize != (Limit{Soft: 8388608, Hard: -1}) {
+		t.Fatalf("unexpected stack size %+v", l.StackSize)
+	}
+	if l.OpenFiles != (Limit{Soft: 1024, Hard: 524288}) {
+		t.Fatalf("unexpected open files %+v", l.OpenFiles)
+	}
+	if l.Processes != (Limit{}) {
+		t.Fatalf("unexpected processes %+v", l.Processes)
+	}
+
+	if _, err = parseLimits([]byte("Max open files            x                    524288               files\n")); err == nil {
+		t.Fatal("expected error on malformed limit")
+	}
+	if _, err = parseLimits(nil); err == nil {
+		t.Fatal("expected error on empty limits")
+	}
+}
+
+func TestGetLimitsByPID(t *testing.T) {
+	l, err := GetLimitsByPID(int64(os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rlim syscall.Rlimit
+	if err = syscall.Getrlimit(syscall.RLIMIT_STACK, &rlim); err != nil {
+		t.Fatal(err)
+	}
+	exp := int64(rlim.Cur)
+	if rlim.Cur == RlimInfinity {
+		exp = -1
+	}
+	if l.StackSize.Soft != exp {
+		t.Fatalf("expected stack size %d, got %+v", exp, l.StackSize)
+	}
+}
@@ -1,6 +1,8 @@
 package proc
 
 import (
+	"context"
+	"fmt"
 	"io/ioutil"
 	"path/filepath"
 	"strconv"
@@ -9,10 +11,21 @@ import (
 
 // ListPIDs reads all PIDs in '/proc'.
 func ListPIDs() ([]int64, error) {
+	return ListPIDsContext(context.Background())
+}
+
+// ListPIDsContext is 'ListPIDs' with a context.
+func ListPIDsContext(ctx context.Context) ([]int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	ds, err := ioutil.ReadDir("/proc")
 	if err != nil {
 		return nil, err
 	}
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	pids := make([]int64, 0, len(ds))
 	for _, f := range ds {
@@ -27,6 +40,26 @@ func ListPIDs() ([]int64, error) {
 	return pids, nil
 }
 
+// ListThreads reads all thread IDs in '/proc/$PID/task'.
+func ListThreads(pid int64) ([]int64, error) {
+	ds, err := ioutil.ReadDir(fmt.Sprintf("/proc/%d/task", pid))
+	if err != nil {
+		return nil, err
+	}
+
+	tids := make([]int64, 0, len(ds))
+	for _, f := range ds {
+		if f.IsDir() && isInt(f.Name()) {
+			id, err := strconv.ParseInt(f.Name(), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			tids = append(tids, id)
+		}
+	}
+	return tids, nil
+}
+
 // ListFds reads '/proc/*/fd/*' to grab process IDs.
 func ListFds() ([]string, error) {
 	// returns the names of all files matching pattern
@@ -0,0 +1,103 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gyuho/linux-inspect/pkg/fileutil"
+	"github.com/gyuho/linux-inspect/schema"
+
+	humanize "github.com/dustin/go-humanize"
+)
+
+// GetMeminfo reads '/proc/meminfo'.
+func GetMeminfo() (Meminfo, error) {
+	f, err := fileutil.OpenToRead("/proc/meminfo")
+	if err != nil {
+		return Meminfo{}, err
+	}
+	defer f.Close()
+
+	d, err := ioutil.ReadAll(f)
+	if err != nil {
+		return Meminfo{}, err
+	}
+	return parseMeminfo(d)
+}
+
+var meminfoColumns = make(map[string]schema.Column)
+
+func init() {
+	for _, col := range MeminfoSchema.Columns {
+		meminfoColumns[col.Name] = col
+	}
+}
+
+// parseMeminfo parses lines like 'MemTotal:  16318472 kB'.
+// Unlisted keys in 'MeminfoSchema' are ignored.
+func parseMeminfo(d []byte) (m Meminfo, err error) {
+	val := reflect.ValueOf(&m).Elem()
+
+	scanner := bufio.NewScanner(bytes.NewReader(d))
+	for scanner.Scan() {
+		txt := strings.TrimSpace(scanner.Text())
+		if len(txt) == 0 {
+			continue
+		}
+		kv := strings.SplitN(txt, ":", 2)
+		if len(kv) != 2 {
+			return Meminfo{}, fmt.Errorf("unexpected line %q", txt)
+		}
+		key, fv := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+
+		col, ok := meminfoColumns[key]
+		if !ok {
+			continue
+		}
+		column := schema.ToField(col.Name)
+
+		switch col.Kind {
+		case reflect.Uint64:
+			uv, uerr := strconv.ParseUint(fv, 10, 64)
+			if uerr != nil {
+				return Meminfo{}, fmt.Errorf("%v when parsing %s %v", uerr, column, fv)
+			}
+			val.FieldByName(column).SetUint(uv)
+
+		case reflect.String:
+			val.FieldByName(column).SetString(fv)
+
+			if MeminfoSchema.ColumnsToParse[col.Name] == schema.TypeBytes {
+				bts, berr := parseKibibytes(fv)
+				if berr != nil {
+					return Meminfo{}, fmt.Errorf("%v when parsing %s %v", berr, column, fv)
+				}
+				val.FieldByName(column + "BytesN").SetUint(bts)
+				val.FieldByName(column + "ParsedBytes").SetString(humanize.Bytes(bts))
+			}
+		}
+	}
+	return m, scanner.Err()
+}
+
+// parseKibibytes parses '16318472 kB' in '/proc' files,
+// where 'kB' actually means KiB (1024 bytes).
+func parseKibibytes(s string) (uint64, error) {
+	fs := strings.Fields(s)
+	if len(fs) == 0 {
+		return 0, fmt.Errorf("empty size %q", s)
+	}
+	n, err := strconv.ParseUint(fs[0], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if len(fs) > 1 && strings.ToLower(fs[1]) == "kb" {
+		n *= 1024
+	}
+	return n, nil
+}
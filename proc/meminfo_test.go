@@ -0,0 +1,37 @@
+package proc
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGetMeminfo(t *testing.T) {
+	m, err := GetMeminfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.MemTotalBytesN == 0 {
+		t.Fatalf("expected non-zero MemTotal, got %q", m.MemTotal)
+	}
+	fmt.Printf("GetMeminfo: %+v\n", m)
+}
+
+func TestParseMeminfo(t *testing.T) {
+	m, err := parseMeminfo([]byte(`MemTotal:       16318472 kB
+MemAvailable:    5645032 kB
+Active(anon):         20 kB
+HugePages_Total:       2
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.MemTotalBytesN != 16318472*1024 {
+		t.Fatalf("MemTotalBytesN expected %d, got %d", 16318472*1024, m.MemTotalBytesN)
+	}
+	if m.MemAvailable != "5645032 kB" {
+		t.Fatalf("MemAvailable expected '5645032 kB', got %q", m.MemAvailable)
+	}
+	if m.HugePagesTotal != 2 {
+		t.Fatalf("HugePagesTotal expected 2, got %d", m.HugePagesTotal)
+	}
+}
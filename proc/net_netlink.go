@@ -0,0 +1,132 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// NetNetlink is a netlink socket entry in '/proc/net/netlink'.
+type NetNetlink struct {
+	// Protocol is the netlink family (e.g. 0 for NETLINK_ROUTE).
+	Protocol int
+	// ProtocolName is the family name without the "NETLINK_" prefix
+	// (e.g. "ROUTE", "AUDIT", "KOBJECT_UEVENT").
+	ProtocolName string
+	// PortID is the netlink port ID, often the PID of the
+	// process that bound it; 0 for the kernel.
+	PortID uint32
+	// Groups is the bitmask of the multicast groups.
+	Groups uint32
+	Rmem   uint64
+	Wmem   uint64
+	Drops  uint64
+	Inode  uint64
+}
+
+// NetlinkProtocols maps the netlink family to its name.
+// https://github.com/torvalds/linux/blob/master/include/uapi/linux/netlink.h
+var NetlinkProtocols = map[int]string{
+	0:  "ROUTE",
+	1:  "UNUSED",
+	2:  "USERSOCK",
+	3:  "FIREWALL",
+	4:  "SOCK_DIAG",
+	5:  "NFLOG",
+	6:  "XFRM",
+	7:  "SELINUX",
+	8:  "ISCSI",
+	9:  "AUDIT",
+	10: "FIB_LOOKUP",
+	11: "CONNECTOR",
+	12: "NETFILTER",
+	13: "IP6_FW",
+	14: "DNRTMSG",
+	15: "KOBJECT_UEVENT",
+	16: "GENERIC",
+	18: "SCSITRANSPORT",
+	19: "ECRYPTFS",
+	20: "RDMA",
+	21: "CRYPTO",
+	22: "SMC",
+}
+
+type netNetlinkColumnIndex int
+
+const (
+	net_netlink_idx_sk netNetlinkColumnIndex = iota
+	net_netlink_idx_eth
+	net_netlink_idx_pid
+	net_netlink_idx_groups
+	net_netlink_idx_rmem
+	net_netlink_idx_wmem
+	net_netlink_idx_dump
+	net_netlink_idx_locks
+	net_netlink_idx_drops
+	net_netlink_idx_inode
+)
+
+// GetNetNetlink reads '/proc/net/netlink'.
+func GetNetNetlink() ([]NetNetlink, error) {
+	d, err := ioutil.ReadFile("/proc/net/netlink")
+	if err != nil {
+		return nil, err
+	}
+	return parseNetNetlink(d)
+}
+
+func parseNetNetlink(d []byte) ([]NetNetlink, error) {
+	var ns []NetNetlink
+	scanner := bufio.NewScanner(bytes.NewReader(d))
+	first := true
+	for scanner.Scan() {
+		fs := strings.Fields(scanner.Text())
+		if first {
+			first = false
+			continue
+		}
+		if len(fs) == 0 {
+			continue
+		}
+		if len(fs) < int(net_netlink_idx_inode)+1 {
+			return nil, fmt.Errorf("not enough columns at %v", fs)
+		}
+
+		var n NetNetlink
+		var err error
+		if n.Protocol, err = strconv.Atoi(fs[net_netlink_idx_eth]); err != nil {
+			return nil, err
+		}
+		if n.ProtocolName = NetlinkProtocols[n.Protocol]; n.ProtocolName == "" {
+			n.ProtocolName = fmt.Sprintf("%d", n.Protocol)
+		}
+		pid, err := strconv.ParseUint(fs[net_netlink_idx_pid], 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		n.PortID = uint32(pid)
+		groups, err := strconv.ParseUint(fs[net_netlink_idx_groups], 16, 32)
+		if err != nil {
+			return nil, err
+		}
+		n.Groups = uint32(groups)
+		for _, v := range []struct {
+			idx netNetlinkColumnIndex
+			p   *uint64
+		}{
+			{net_netlink_idx_rmem, &n.Rmem},
+			{net_netlink_idx_wmem, &n.Wmem},
+			{net_netlink_idx_drops, &n.Drops},
+			{net_netlink_idx_inode, &n.Inode},
+		} {
+			if *v.p, err = strconv.ParseUint(fs[v.idx], 10, 64); err != nil {
+				return nil, err
+			}
+		}
+		ns = append(ns, n)
+	}
+	return ns, scanner.Err()
+}
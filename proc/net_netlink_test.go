@@ -0,0 +1,37 @@
+package proc
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseNetNetlink(t *testing.T) {
+	ns, err := parseNetNetlink([]byte(`sk               Eth Pid        Groups   Rmem     Wmem     Dump  Locks    Drops    Inode
+00000000043a43e9 0   0          00000000 0        0        0     2        0        4
+000000007db59e49 9   1234       00000001 0        0        0     2        3        589
+00000000ee27badc 15  4294966301 00000001 0        0        0     2        0        591
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ns) != 3 {
+		t.Fatalf("expected 3 sockets, got %d", len(ns))
+	}
+	if ns[0].ProtocolName != "ROUTE" || ns[0].PortID != 0 || ns[0].Inode != 4 {
+		t.Fatalf("unexpected %+v", ns[0])
+	}
+	if ns[1].ProtocolName != "AUDIT" || ns[1].PortID != 1234 || ns[1].Groups != 1 || ns[1].Drops != 3 {
+		t.Fatalf("unexpected %+v", ns[1])
+	}
+	if ns[2].ProtocolName != "KOBJECT_UEVENT" || ns[2].PortID != 4294966301 {
+		t.Fatalf("unexpected %+v", ns[2])
+	}
+}
+
+func TestGetNetNetlink(t *testing.T) {
+	ns, err := GetNetNetlink()
+	if err != nil {
+		t.Skip(err)
+	}
+	fmt.Println("netlink sockets:", len(ns))
+}
@@ -0,0 +1,124 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// NetPacket is an AF_PACKET socket entry in '/proc/net/packet'.
+type NetPacket struct {
+	// Type is the socket type ("RAW" or "DGRAM").
+	Type string
+	// Protocol is the ethertype the socket is bound to (e.g. 0x0003 for
+	// ETH_P_ALL, 0x0800 for ETH_P_IP).
+	Protocol uint16
+	// ProtocolName is the name of Protocol (e.g. "ALL", "IP"),
+	// or its hex value if unknown.
+	ProtocolName string
+	// Ifindex is the bound interface index; 0 for all interfaces.
+	Ifindex int
+	// Running is true if the socket is receiving packets.
+	Running bool
+	Rmem    uint64
+	UID     uint64
+	Inode   uint64
+}
+
+// PacketProtocols maps the ethertype to its name.
+// https://github.com/torvalds/linux/blob/master/include/uapi/linux/if_ether.h
+var PacketProtocols = map[uint16]string{
+	0x0003: "ALL",
+	0x0800: "IP",
+	0x0806: "ARP",
+	0x8035: "RARP",
+	0x8100: "8021Q",
+	0x86DD: "IPV6",
+	0x888E: "PAE",
+	0x88CC: "LLDP",
+}
+
+var packetTypes = map[int]string{
+	2: "DGRAM",
+	3: "RAW",
+}
+
+type netPacketColumnIndex int
+
+const (
+	net_packet_idx_sk netPacketColumnIndex = iota
+	net_packet_idx_refcnt
+	net_packet_idx_type
+	net_packet_idx_proto
+	net_packet_idx_iface
+	net_packet_idx_r
+	net_packet_idx_rmem
+	net_packet_idx_user
+	net_packet_idx_inode
+)
+
+// GetNetPacket reads '/proc/net/packet'.
+func GetNetPacket() ([]NetPacket, error) {
+	d, err := ioutil.ReadFile("/proc/net/packet")
+	if err != nil {
+		return nil, err
+	}
+	return parseNetPacket(d)
+}
+
+func parseNetPacket(d []byte) ([]NetPacket, error) {
+	var ps []NetPacket
+	scanner := bufio.NewScanner(bytes.NewReader(d))
+	first := true
+	for scanner.Scan() {
+		fs := strings.Fields(scanner.Text())
+		if first {
+			first = false
+			continue
+		}
+		if len(fs) == 0 {
+			continue
+		}
+		if len(fs) < int(net_packet_idx_inode)+1 {
+			return nil, fmt.Errorf("not enough columns at %v", fs)
+		}
+
+		var p NetPacket
+		tp, err := strconv.Atoi(fs[net_packet_idx_type])
+		if err != nil {
+			return nil, err
+		}
+		if p.Type = packetTypes[tp]; p.Type == "" {
+			p.Type = fmt.Sprintf("%d", tp)
+		}
+		proto, err := strconv.ParseUint(fs[net_packet_idx_proto], 16, 16)
+		if err != nil {
+			return nil, err
+		}
+		p.Protocol = uint16(proto)
+		if p.ProtocolName = PacketProtocols[p.Protocol]; p.ProtocolName == "" {
+			p.ProtocolName = fmt.Sprintf("0x%04x", p.Protocol)
+		}
+		if p.Ifindex, err = strconv.Atoi(fs[net_packet_idx_iface]); err != nil {
+			return nil, err
+		}
+		p.Running = fs[net_packet_idx_r] == "1"
+		for _, v := range []struct {
+			idx netPacketColumnIndex
+			p   *uint64
+		}{
+			{net_packet_idx_rmem, &p.Rmem},
+			{net_packet_idx_user, &p.UID},
+			{net_packet_idx_inode, &p.Inode},
+		} {
+			if *v.p, err = strconv.ParseUint(fs[v.idx], 10, 64); err != nil {
+				return nil, err
+			}
+		}
+		ps = append(ps, p)
+	}
+	return ps, scanner.Err()
+}
@@ -0,0 +1,26 @@
+package proc
+
+import "testing"
+
+func TestParseNetPacket(t *testing.T) {
+	ps, err := parseNetPacket([]byte(`sk               RefCnt Type Proto  Iface R Rmem   User   Inode
+0000000012345678 3      3    0003   0     1 0      0      24651
+00000000abcdef01 3      2    0806   2     0 2304   1000   24700
+00000000abcdef02 3      3    88b5   3     1 0      0      24800
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 3 {
+		t.Fatalf("expected 3 sockets, got %d", len(ps))
+	}
+	if ps[0].Type != "RAW" || ps[0].ProtocolName != "ALL" || ps[0].Ifindex != 0 || !ps[0].Running || ps[0].Inode != 24651 {
+		t.Fatalf("unexpected %+v", ps[0])
+	}
+	if ps[1].Type != "DGRAM" || ps[1].ProtocolName != "ARP" || ps[1].Ifindex != 2 || ps[1].Running || ps[1].Rmem != 2304 || ps[1].UID != 1000 {
+		t.Fatalf("unexpected %+v", ps[1])
+	}
+	if ps[2].ProtocolName != "0x88b5" {
+		t.Fatalf("unexpected %+v", ps[2])
+	}
+}
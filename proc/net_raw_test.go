@@ -0,0 +1,41 @@
+package proc
+
+import (
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestParseNetRaw(t *testing.T) {
+	nss, err := parseNetTCP([]byte(`  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+   1: 00000000:0001 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 81234 2 0000000000000000 0
+`), parseLittleEndianIpv4, TypeRaw.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nss) != 1 {
+		t.Fatalf("expected 1 socket, got %d", len(nss))
+	}
+	if nss[0].Type != "raw" || nss[0].LocalAddressParsedIPPort != 1 || nss[0].StParsedStatus != "CLOSE" || nss[0].Inode != "81234" {
+		t.Fatalf("unexpected %+v", nss[0])
+	}
+}
+
+func TestGetNetRawByPID(t *testing.T) {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_RAW, syscall.IPPROTO_ICMP)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer syscall.Close(fd)
+
+	nss, err := GetNetTCPByPID(int64(os.Getpid()), TypeRaw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, np := range nss {
+		if np.LocalAddressParsedIPPort == syscall.IPPROTO_ICMP {
+			return
+		}
+	}
+	t.Fatalf("raw ICMP socket not found in %+v", nss)
+}
@@ -0,0 +1,264 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/gyuho/linux-inspect/pkg/fileutil"
+)
+
+// Route is a routing table entry in '/proc/net/route' or '/proc/net/ipv6_route'.
+// Reference http://man7.org/linux/man-pages/man5/proc.5.html.
+type Route struct {
+	// IPv6 is true if parsed from '/proc/net/ipv6_route'.
+	IPv6 bool
+
+	Interface   string
+	Destination *net.IPNet
+	Gateway     net.IP
+
+	// Flags is the route flags (e.g. RTF_UP 0x1, RTF_GATEWAY 0x2, RTF_HOST 0x4).
+	Flags  uint64
+	RefCnt uint64
+	Use    uint64
+	Metric uint64
+
+	// MTU, Window, IRTT are only available in IPv4 routes.
+	MTU    uint64
+	Window uint64
+	IRTT   uint64
+}
+
+// Route flags.
+// https://github.com/torvalds/linux/blob/master/include/uapi/linux/route.h
+const (
+	RouteFlagUp      = 0x0001
+	RouteFlagGateway = 0x0002
+	RouteFlagHost    = 0x0004
+)
+
+type netRouteColumnIndex int
+
+const (
+	net_route_idx_iface netRouteColumnIndex = iota
+	net_route_idx_destination
+	net_route_idx_gateway
+	net_route_idx_flags
+	net_route_idx_refcnt
+	net_route_idx_use
+	net_route_idx_metric
+	net_route_idx_mask
+	net_route_idx_mtu
+	net_route_idx_window
+	net_route_idx_irtt
+)
+
+type netIPv6RouteColumnIndex int
+
+const (
+	net_ipv6_route_idx_destination netIPv6RouteColumnIndex = iota
+	net_ipv6_route_idx_destination_prefix_length
+	net_ipv6_route_idx_source
+	net_ipv6_route_idx_source_prefix_length
+	net_ipv6_route_idx_next_hop
+	net_ipv6_route_idx_metric
+	net_ipv6_route_idx_refcnt
+	net_ipv6_route_idx_use
+	net_ipv6_route_idx_flags
+	net_ipv6_route_idx_iface
+)
+
+// GetRoutes reads '/proc/net/route'.
+func GetRoutes() ([]Route, error) {
+	d, err := readNetRoute("/proc/net/route")
+	if err != nil {
+		return nil, err
+	}
+	return parseNetRoute(d)
+}
+
+// GetIPv6Routes reads '/proc/net/ipv6_route'.
+func GetIPv6Routes() ([]Route, error) {
+	d, err := readNetRoute("/proc/net/ipv6_route")
+	if err != nil {
+		return nil, err
+	}
+	return parseNetIPv6Route(d)
+}
+
+// DefaultGateway returns the IPv4 default route (destination '0.0.0.0/0')
+// with the lowest metric.
+func DefaultGateway() (Route, error) {
+	rs, err := GetRoutes()
+	if err != nil {
+		return Route{}, err
+	}
+	found, rt := false, Route{}
+	for _, r := range rs {
+		if ones, _ := r.Destination.Mask.Size(); ones != 0 || !r.Destination.IP.Equal(net.IPv4zero) {
+			continue
+		}
+		if !found || r.Metric < rt.Metric {
+			found, rt = true, r
+		}
+	}
+	if !found {
+		return Route{}, fmt.Errorf("default gateway not found in %d routes", len(rs))
+	}
+	return rt, nil
+}
+
+func readNetRoute(fpath string) ([]byte, error) {
+	f, err := fileutil.OpenToRead(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+func parseNetRoute(d []byte) ([]Route, error) {
+	var rs []Route
+
+	first := true
+	scanner := bufio.NewScanner(bytes.NewReader(d))
+	for scanner.Scan() {
+		txt := strings.TrimSpace(scanner.Text())
+		if len(txt) == 0 {
+			continue
+		}
+		fs := strings.Fields(txt)
+		if first {
+			if fs[0] != "Iface" { // header
+				return nil, fmt.Errorf("first line must be columns but got = %#q", fs)
+			}
+			first = false
+			continue
+		}
+		if len(fs) < int(net_route_idx_irtt+1) {
+			return nil, fmt.Errorf("not enough columns at %v", fs)
+		}
+
+		r := Route{Interface: fs[net_route_idx_iface]}
+
+		dst, err := parseLittleEndianHexIPv4(fs[net_route_idx_destination])
+		if err != nil {
+			return nil, err
+		}
+		mask, err := parseLittleEndianHexIPv4(fs[net_route_idx_mask])
+		if err != nil {
+			return nil, err
+		}
+		r.Destination = &net.IPNet{IP: dst, Mask: net.IPMask(mask.To4())}
+
+		if r.Gateway, err = parseLittleEndianHexIPv4(fs[net_route_idx_gateway]); err != nil {
+			return nil, err
+		}
+
+		nums := []*uint64{&r.Flags, &r.RefCnt, &r.Use, &r.Metric, &r.MTU, &r.Window, &r.IRTT}
+		idxs := []netRouteColumnIndex{
+			net_route_idx_flags,
+			net_route_idx_refcnt,
+			net_route_idx_use,
+			net_route_idx_metric,
+			net_route_idx_mtu,
+			net_route_idx_window,
+			net_route_idx_irtt,
+		}
+		for i, idx := range idxs {
+			base := 10
+			if idx == net_route_idx_flags {
+				base = 16
+			}
+			if *nums[i], err = strconv.ParseUint(fs[idx], base, 64); err != nil {
+				return nil, err
+			}
+		}
+
+		rs = append(rs, r)
+	}
+	return rs, scanner.Err()
+}
+
+func parseNetIPv6Route(d []byte) ([]Route, error) {
+	var rs []Route
+
+	scanner := bufio.NewScanner(bytes.NewReader(d))
+	for scanner.Scan() {
+		txt := strings.TrimSpace(scanner.Text())
+		if len(txt) == 0 {
+			continue
+		}
+		fs := strings.Fields(txt)
+		if len(fs) < int(net_ipv6_route_idx_iface+1) {
+			return nil, fmt.Errorf("not enough columns at %v", fs)
+		}
+
+		r := Route{IPv6: true, Interface: fs[net_ipv6_route_idx_iface]}
+
+		dst, err := parseHexIPv6(fs[net_ipv6_route_idx_destination])
+		if err != nil {
+			return nil, err
+		}
+		plen, err := strconv.ParseUint(fs[net_ipv6_route_idx_destination_prefix_length], 16, 8)
+		if err != nil {
+			return nil, err
+		}
+		r.Destination = &net.IPNet{IP: dst, Mask: net.CIDRMask(int(plen), 128)}
+
+		if r.Gateway, err = parseHexIPv6(fs[net_ipv6_route_idx_next_hop]); err != nil {
+			return nil, err
+		}
+
+		nums := []*uint64{&r.Metric, &r.RefCnt, &r.Use, &r.Flags}
+		idxs := []netIPv6RouteColumnIndex{
+			net_ipv6_route_idx_metric,
+			net_ipv6_route_idx_refcnt,
+			net_ipv6_route_idx_use,
+			net_ipv6_route_idx_flags,
+		}
+		for i, idx := range idxs {
+			if *nums[i], err = strconv.ParseUint(fs[idx], 16, 64); err != nil {
+				return nil, err
+			}
+		}
+
+		rs = append(rs, r)
+	}
+	return rs, scanner.Err()
+}
+
+// parseLittleEndianHexIPv4 parses hexadecimal IPv4 address in
+// host byte order (e.g. '0101A8C0' into '192.168.1.1').
+// It assumes that the system has little endian order.
+func parseLittleEndianHexIPv4(s string) (net.IP, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != net.IPv4len {
+		return nil, fmt.Errorf("cannot parse ipv4 %s", s)
+	}
+	v := binary.LittleEndian.Uint32(b)
+	return net.IPv4(byte(v>>24), byte(v>>16), byte(v>>8), byte(v)).To4(), nil
+}
+
+// parseHexIPv6 parses hexadecimal IPv6 address in network byte order,
+// as in '/proc/net/ipv6_route'.
+func parseHexIPv6(s string) (net.IP, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != net.IPv6len {
+		return nil, fmt.Errorf("cannot parse ipv6 %s", s)
+	}
+	return net.IP(b), nil
+}
@@ -0,0 +1,68 @@
+package proc
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseNetRoute(t *testing.T) {
+	rs, err := parseNetRoute([]byte(`Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
+eth0	00000000	0101A8C0	0003	0	0	100	00000000	0	0	0
+eth0	0001A8C0	00000000	0001	0	0	100	00FFFFFF	0	0	0
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 2 {
+		t.Fatalf("expected 2 routes, got %d", len(rs))
+	}
+	if rs[0].Gateway.String() != "192.168.1.1" {
+		t.Fatalf("gateway expected '192.168.1.1', got %q", rs[0].Gateway)
+	}
+	if rs[0].Flags != RouteFlagUp|RouteFlagGateway {
+		t.Fatalf("flags expected %d, got %d", RouteFlagUp|RouteFlagGateway, rs[0].Flags)
+	}
+	if rs[1].Destination.String() != "192.168.1.0/24" {
+		t.Fatalf("destination expected '192.168.1.0/24', got %q", rs[1].Destination)
+	}
+	if rs[1].Metric != 100 {
+		t.Fatalf("metric expected 100, got %d", rs[1].Metric)
+	}
+}
+
+func TestParseNetIPv6Route(t *testing.T) {
+	rs, err := parseNetIPv6Route([]byte(`fe800000000000000000000000000000 40 00000000000000000000000000000000 00 00000000000000000000000000000000 00000100 00000002 00000000 00000001     eth0
+00000000000000000000000000000000 00 00000000000000000000000000000000 00 fd000000000000000000000000000001 00000400 00000001 00000000 00000003     eth0
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 2 {
+		t.Fatalf("expected 2 routes, got %d", len(rs))
+	}
+	if rs[0].Destination.String() != "fe80::/64" {
+		t.Fatalf("destination expected 'fe80::/64', got %q", rs[0].Destination)
+	}
+	if rs[0].Metric != 256 {
+		t.Fatalf("metric expected 256, got %d", rs[0].Metric)
+	}
+	if rs[1].Gateway.String() != "fd00::1" {
+		t.Fatalf("gateway expected 'fd00::1', got %q", rs[1].Gateway)
+	}
+}
+
+func TestGetRoutes(t *testing.T) {
+	rs, err := GetRoutes()
+	if err != nil {
+		t.Skip(err)
+	}
+	for _, r := range rs {
+		fmt.Printf("%s %s via %s\n", r.Interface, r.Destination, r.Gateway)
+	}
+
+	gw, err := DefaultGateway()
+	if err != nil {
+		t.Skip(err)
+	}
+	fmt.Printf("DefaultGateway: %+v\n", gw)
+}
@@ -0,0 +1,263 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// NetSCTP is an SCTP association in '/proc/net/sctp/assocs',
+// or a listening endpoint in '/proc/net/sctp/eps'.
+// Reference https://github.com/torvalds/linux/blob/master/net/sctp/proc.c.
+type NetSCTP struct {
+	// Endpoint is true for listening endpoints, with no remote address.
+	Endpoint bool
+
+	// State is the association state (e.g. "ESTABLISHED"),
+	// or "LISTEN" for endpoints.
+	State string
+	// StateCode is the kernel 'enum sctp_state' of associations,
+	// or the socket state of endpoints (e.g. 0x0A for LISTEN).
+	StateCode int
+
+	// LocalAddrs, RemoteAddrs are the multi-homed addresses,
+	// with the primary address first.
+	LocalAddrs  []string
+	LocalPort   int64
+	RemoteAddrs []string
+	RemotePort  int64
+
+	TxQueue uint64
+	RxQueue uint64
+	Uid     uint64
+	Inode   uint64
+}
+
+// SCTPStates maps the kernel 'enum sctp_state' to its name.
+// https://github.com/torvalds/linux/blob/master/include/net/sctp/constants.h
+var SCTPStates = map[int]string{
+	0: "CLOSED",
+	1: "COOKIE_WAIT",
+	2: "COOKIE_ECHOED",
+	3: "ESTABLISHED",
+	4: "SHUTDOWN_PENDING",
+	5: "SHUTDOWN_SENT",
+	6: "SHUTDOWN_RECEIVED",
+	7: "SHUTDOWN_ACK_SENT",
+}
+
+// sctpSocketListening is the 'SST' of listening sockets (TCP_LISTEN).
+const sctpSocketListening = 10
+
+// GetNetSCTPByPID reads '/proc/$PID/net/sctp/assocs' and the listening
+// endpoints in '/proc/$PID/net/sctp/eps'. It returns an error satisfying
+// 'os.IsNotExist' if the SCTP module is not loaded.
+func GetNetSCTPByPID(pid int64) ([]NetSCTP, error) {
+	d, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/net/sctp/eps", pid))
+	if err != nil {
+		return nil, err
+	}
+	eps, err := parseNetSCTPEndpoints(d)
+	if err != nil {
+		return nil, err
+	}
+	d, err = ioutil.ReadFile(fmt.Sprintf("/proc/%d/net/sctp/assocs", pid))
+	if err != nil {
+		return nil, err
+	}
+	as, err := parseNetSCTPAssocs(d)
+	if err != nil {
+		return nil, err
+	}
+	return append(eps, as...), nil
+}
+
+type netSCTPEndpointColumnIndex int
+
+const (
+	net_sctp_eps_idx_endpt netSCTPEndpointColumnIndex = iota
+	net_sctp_eps_idx_sock
+	net_sctp_eps_idx_sty
+	net_sctp_eps_idx_sst
+	net_sctp_eps_idx_hbkt
+	net_sctp_eps_idx_lport
+	net_sctp_eps_idx_uid
+	net_sctp_eps_idx_inode
+	net_sctp_eps_idx_laddrs
+)
+
+// parseNetSCTPEndpoints parses the listening endpoints; other endpoints
+// are listed with their associations.
+func parseNetSCTPEndpoints(d []byte) ([]NetSCTP, error) {
+	var ss []NetSCTP
+	scanner := bufio.NewScanner(bytes.NewReader(d))
+	first := true
+	for scanner.Scan() {
+		fs := strings.Fields(scanner.Text())
+		if first {
+			first = false
+			continue
+		}
+		if len(fs) == 0 {
+			continue
+		}
+		if len(fs) < int(net_sctp_eps_idx_laddrs) {
+			return nil, fmt.Errorf("not enough columns at %v", fs)
+		}
+		sst, err := strconv.Atoi(fs[net_sctp_eps_idx_sst])
+		if err != nil {
+			return nil, err
+		}
+		if sst != sctpSocketListening {
+			continue
+		}
+		s := NetSCTP{Endpoint: true, State: "LISTEN", StateCode: sst}
+		if s.LocalPort, err = strconv.ParseInt(fs[net_sctp_eps_idx_lport], 10, 64); err != nil {
+			return nil, err
+		}
+		if s.Uid, err = strconv.ParseUint(fs[net_sctp_eps_idx_uid], 10, 64); err != nil {
+			return nil, err
+		}
+		if s.Inode, err = strconv.ParseUint(fs[net_sctp_eps_idx_inode], 10, 64); err != nil {
+			return nil, err
+		}
+		s.LocalAddrs = parseSCTPAddrs(fs[net_sctp_eps_idx_laddrs:])
+		ss = append(ss, s)
+	}
+	return ss, scanner.Err()
+}
+
+type netSCTPAssocColumnIndex int
+
+const (
+	net_sctp_assocs_idx_assoc netSCTPAssocColumnIndex = iota
+	net_sctp_assocs_idx_sock
+	net_sctp_assocs_idx_sty
+	net_sctp_assocs_idx_sst
+	net_sctp_assocs_idx_st
+	net_sctp_assocs_idx_hbkt
+	net_sctp_assocs_idx_assoc_id
+	net_sctp_assocs_idx_tx_queue
+	net_sctp_assocs_idx_rx_queue
+	net_sctp_assocs_idx_uid
+	net_sctp_assocs_idx_inode
+	net_sctp_assocs_idx_lport
+	net_sctp_assocs_idx_rport
+	net_sctp_assocs_idx_laddrs
+)
+
+func parseNetSCTPAssocs(d []byte) ([]NetSCTP, error) {
+	var ss []NetSCTP
+	scanner := bufio.NewScanner(bytes.NewReader(d))
+	first := true
+	for scanner.Scan() {
+		fs := strings.Fields(scanner.Text())
+		if first {
+			first = false
+			continue
+		}
+		if len(fs) == 0 {
+			continue
+		}
+		if len(fs) < int(net_sctp_assocs_idx_laddrs) {
+			return nil, fmt.Errorf("not enough columns at %v", fs)
+		}
+		var s NetSCTP
+		st, err := strconv.Atoi(fs[net_sctp_assocs_idx_st])
+		if err != nil {
+			return nil, err
+		}
+		s.StateCode = st
+		if s.State = SCTPStates[st]; s.State == "" {
+			s.State = fmt.Sprintf("UNKNOWN(%d)", st)
+		}
+		for _, v := range []struct {
+			idx netSCTPAssocColumnIndex
+			p   *uint64
+		}{
+			{net_sctp_assocs_idx_tx_queue, &s.TxQueue},
+			{net_sctp_assocs_idx_rx_queue, &s.RxQueue},
+			{net_sctp_assocs_idx_uid, &s.Uid},
+			{net_sctp_assocs_idx_inode, &s.Inode},
+		} {
+			if *v.p, err = strconv.ParseUint(fs[v.idx], 10, 64); err != nil {
+				return nil, err
+			}
+		}
+		if s.LocalPort, err = strconv.ParseInt(fs[net_sctp_assocs_idx_lport], 10, 64); err != nil {
+			return nil, err
+		}
+		if s.RemotePort, err = strconv.ParseInt(fs[net_sctp_assocs_idx_rport], 10, 64); err != nil {
+			return nil, err
+		}
+
+		// LADDRS <-> RADDRS, followed by the numeric columns
+		addrs := fs[net_sctp_assocs_idx_laddrs:]
+		sep := -1
+		for i, f := range addrs {
+			if f == "<->" {
+				sep = i
+				break
+			}
+		}
+		if sep < 0 {
+			return nil, fmt.Errorf("no address separator at %v", fs)
+		}
+		s.LocalAddrs = parseSCTPAddrs(addrs[:sep])
+		s.RemoteAddrs = parseSCTPAddrs(addrs[sep+1:])
+		ss = append(ss, s)
+	}
+	return ss, scanner.Err()
+}
+
+// parseSCTPAddrs parses the addresses until the first non-address
+// column, moving the primary address (marked with '*') first.
+func parseSCTPAddrs(fs []string) []string {
+	var addrs []string
+	for _, f := range fs {
+		primary := strings.HasPrefix(f, "*")
+		f = strings.TrimPrefix(f, "*")
+		if !strings.ContainsAny(f, ".:") {
+			break
+		}
+		if primary {
+			addrs = append([]string{f}, addrs...)
+		} else {
+			addrs = append(addrs, f)
+		}
+	}
+	return addrs
+}
+
+// netSCTPToNetTCP converts to 'NetTCP' with the primary addresses,
+// so SCTP sockets can be listed with TCP sockets.
+func netSCTPToNetTCP(ss []NetSCTP) []NetTCP {
+	nss := make([]NetTCP, len(ss))
+	for i, s := range ss {
+		np := NetTCP{
+			Type:                     TypeSCTP.String(),
+			Sl:                       uint64(i),
+			LocalAddressParsedIPPort: s.LocalPort,
+			RemAddressParsedIPPort:   s.RemotePort,
+			St:                       fmt.Sprintf("%02X", s.StateCode),
+			StParsedStatus:           s.State,
+			TxQueue:                  fmt.Sprintf("%08X", s.TxQueue),
+			RxQueue:                  fmt.Sprintf("%08X", s.RxQueue),
+			Uid:                      s.Uid,
+			Inode:                    strconv.FormatUint(s.Inode, 10),
+		}
+		if len(s.LocalAddrs) > 0 {
+			np.LocalAddressParsedIPHost = s.LocalAddrs[0]
+		}
+		if len(s.RemoteAddrs) > 0 {
+			np.RemAddressParsedIPHost = s.RemoteAddrs[0]
+		} else {
+			np.RemAddressParsedIPHost = "0.0.0.0"
+		}
+		nss[i] = np
+	}
+	return nss
+}
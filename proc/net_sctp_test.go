@@ -0,0 +1,63 @@
+package proc
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseNetSCTP(t *testing.T) {
+	eps, err := parseNetSCTPEndpoints([]byte(` ENDPT     SOCK   STY SST HBKT LPORT   UID INODE LADDRS
+ffff88017e0a0200 ffff880299f7fa00 2   10  29   3868     0   262972 10.0.0.1 10.0.0.2
+ffff88017e0a0400 ffff880299f7fc00 1   1   30   3868     0   262975 10.0.0.1
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(eps) != 1 {
+		t.Fatalf("expected 1 listening endpoint, got %+v", eps)
+	}
+	if !eps[0].Endpoint || eps[0].State != "LISTEN" || eps[0].LocalPort != 3868 || eps[0].Inode != 262972 {
+		t.Fatalf("unexpected %+v", eps[0])
+	}
+	if !reflect.DeepEqual(eps[0].LocalAddrs, []string{"10.0.0.1", "10.0.0.2"}) {
+		t.Fatalf("unexpected local addresses %v", eps[0].LocalAddrs)
+	}
+
+	as, err := parseNetSCTPAssocs([]byte(` ASSOC     SOCK   STY SST ST HBKT ASSOC-ID TX_QUEUE RX_QUEUE UID INODE LPORT RPORT LADDRS <-> RADDRS HBINT INS OUTS MAXRT T1X T2X RTXC wmema wmemq sndbuf rcvbuf
+ffff88017db26000 ffff88017e0a0400 1   1   3  1460     8        0        5   1000 262975  3868 40000  10.0.0.1 *10.0.0.2 <-> *10.0.1.1 10.0.1.2      7500    10    10   10    0    0        0        1        0   212992   212992
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(as) != 1 {
+		t.Fatalf("expected 1 association, got %+v", as)
+	}
+	a := as[0]
+	if a.State != "ESTABLISHED" || a.RxQueue != 5 || a.Uid != 1000 || a.Inode != 262975 || a.LocalPort != 3868 || a.RemotePort != 40000 {
+		t.Fatalf("unexpected %+v", a)
+	}
+	if !reflect.DeepEqual(a.LocalAddrs, []string{"10.0.0.2", "10.0.0.1"}) {
+		t.Fatalf("unexpected local addresses %v", a.LocalAddrs)
+	}
+	if !reflect.DeepEqual(a.RemoteAddrs, []string{"10.0.1.1", "10.0.1.2"}) {
+		t.Fatalf("unexpected remote addresses %v", a.RemoteAddrs)
+	}
+
+	nss := netSCTPToNetTCP(append(eps, as...))
+	if nss[0].Type != "sctp" || nss[0].RemAddressParsedIPHost != "0.0.0.0" || nss[0].StParsedStatus != "LISTEN" {
+		t.Fatalf("unexpected %+v", nss[0])
+	}
+	if nss[1].LocalAddressParsedIPHost != "10.0.0.2" || nss[1].RemAddressParsedIPHost != "10.0.1.1" || nss[1].Inode != "262975" {
+		t.Fatalf("unexpected %+v", nss[1])
+	}
+}
+
+func TestGetNetSCTPByPID(t *testing.T) {
+	ss, err := GetNetSCTPByPID(int64(os.Getpid()))
+	if err != nil {
+		t.Skip(err)
+	}
+	fmt.Println("sctp sockets:", len(ss))
+}
@@ -0,0 +1,80 @@
+package proc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NetSysctls is the networking kernel parameters in '/proc/sys/net'
+// relevant to sockets.
+type NetSysctls struct {
+	// LocalPortRangeLow and LocalPortRangeHigh are
+	// 'net.ipv4.ip_local_port_range', the ephemeral port range.
+	LocalPortRangeLow  int64
+	LocalPortRangeHigh int64
+
+	// TCPFinTimeoutSecond is 'net.ipv4.tcp_fin_timeout'.
+	TCPFinTimeoutSecond int64
+	// TCPTwReuse is 'net.ipv4.tcp_tw_reuse'
+	// (0 disabled, 1 enabled, 2 loopback only).
+	TCPTwReuse int64
+	// TCPMaxSynBacklog is 'net.ipv4.tcp_max_syn_backlog'.
+	TCPMaxSynBacklog int64
+
+	// Somaxconn is 'net.core.somaxconn', the listen backlog limit.
+	Somaxconn int64
+	// NetdevMaxBacklog is 'net.core.netdev_max_backlog'.
+	NetdevMaxBacklog int64
+}
+
+// EphemeralPorts returns the number of ports in the local port range.
+// Compare with the number of ESTABLISHED and TIME_WAIT sockets to
+// a remote endpoint to detect ephemeral port exhaustion.
+func (ns NetSysctls) EphemeralPorts() int64 {
+	return ns.LocalPortRangeHigh - ns.LocalPortRangeLow + 1
+}
+
+// GetNetSysctls reads '/proc/sys/net'.
+func GetNetSysctls() (NetSysctls, error) {
+	var ns NetSysctls
+
+	pr, err := GetSysctl("net.ipv4.ip_local_port_range")
+	if err != nil {
+		return NetSysctls{}, err
+	}
+	if ns.LocalPortRangeLow, ns.LocalPortRangeHigh, err = parsePortRange(pr); err != nil {
+		return NetSysctls{}, err
+	}
+
+	for name, p := range map[string]*int64{
+		"net.ipv4.tcp_fin_timeout":     &ns.TCPFinTimeoutSecond,
+		"net.ipv4.tcp_tw_reuse":        &ns.TCPTwReuse,
+		"net.ipv4.tcp_max_syn_backlog": &ns.TCPMaxSynBacklog,
+		"net.core.somaxconn":           &ns.Somaxconn,
+		"net.core.netdev_max_backlog":  &ns.NetdevMaxBacklog,
+	} {
+		if *p, err = GetSysctlInt(name); err != nil {
+			return NetSysctls{}, err
+		}
+	}
+	return ns, nil
+}
+
+// parsePortRange parses 'ip_local_port_range' (e.g. "32768\t60999").
+func parsePortRange(s string) (low, high int64, err error) {
+	fs := strings.Fields(s)
+	if len(fs) != 2 {
+		return 0, 0, fmt.Errorf("unexpected port range %q", s)
+	}
+	if low, err = strconv.ParseInt(fs[0], 10, 64); err != nil {
+		return 0, 0, err
+	}
+	if high, err = strconv.ParseInt(fs[1], 10, 64); err != nil {
+		return 0, 0, err
+	}
+	if low > high {
+		return 0, 0, fmt.Errorf("unexpected port range %q", s)
+	}
+	return low, high, nil
+}
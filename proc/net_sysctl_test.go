@@ -0,0 +1,30 @@
+package proc
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParsePortRange(t *testing.T) {
+	low, high, err := parsePortRange("32768\t60999\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ns := NetSysctls{LocalPortRangeLow: low, LocalPortRangeHigh: high}
+	if low != 32768 || high != 60999 || ns.EphemeralPorts() != 28232 {
+		t.Fatalf("unexpected port range %d-%d (%d ports)", low, high, ns.EphemeralPorts())
+	}
+	for _, s := range []string{"32768", "60999 32768", "a b"} {
+		if _, _, err = parsePortRange(s); err == nil {
+			t.Fatalf("expected error for %q", s)
+		}
+	}
+}
+
+func TestGetNetSysctls(t *testing.T) {
+	ns, err := GetNetSysctls()
+	if err != nil {
+		t.Skip(err)
+	}
+	fmt.Printf("%+v\n", ns)
+}
@@ -12,29 +12,45 @@ import (
 	"bytes"
 )
 
-// GetNetTCPByPID reads '/proc/$PID/net/tcp(6)' data.
+// GetNetTCPByPID reads '/proc/$PID/net/tcp(6)' data, or the raw and
+// ICMP tables of the same format. With 'TypeSCTP', it converts 'GetNetSCTPByPID' results,
+// with the primary addresses.
 func GetNetTCPByPID(pid int64, tp TransportProtocol) ([]NetTCP, error) {
+	if tp == TypeSCTP {
+		ss, err := GetNetSCTPByPID(pid)
+		if err != nil {
+			return nil, err
+		}
+		return netSCTPToNetTCP(ss), nil
+	}
 	d, err := readNetTCP(pid, tp)
 	if err != nil {
 		return nil, err
 	}
 
 	var ipParse func(string) (string, int64, error)
-	switch tp {
-	case TypeTCP:
-		ipParse = parseLittleEndianIpv4
-	case TypeTCP6:
+	if tp.IPv6() {
 		ipParse = parseLittleEndianIpv6
+	} else {
+		ipParse = parseLittleEndianIpv4
 	}
 	return parseNetTCP(d, ipParse, tp.String())
 }
 
-// TransportProtocol is tcp, tcp6.
+// TransportProtocol is tcp, tcp6, sctp, raw, raw6, icmp, icmp6.
 type TransportProtocol int
 
 const (
 	TypeTCP TransportProtocol = iota
 	TypeTCP6
+	TypeSCTP
+	// TypeRaw, TypeRaw6 are raw sockets, whose local port
+	// is the IP protocol number (e.g. 1 for ICMP).
+	TypeRaw
+	TypeRaw6
+	// TypeICMP, TypeICMP6 are ICMP datagram ("ping") sockets.
+	TypeICMP
+	TypeICMP6
 )
 
 func (tp TransportProtocol) String() string {
@@ -43,11 +59,26 @@ func (tp TransportProtocol) String() string {
 		return "tcp"
 	case TypeTCP6:
 		return "tcp6"
+	case TypeSCTP:
+		return "sctp"
+	case TypeRaw:
+		return "raw"
+	case TypeRaw6:
+		return "raw6"
+	case TypeICMP:
+		return "icmp"
+	case TypeICMP6:
+		return "icmp6"
 	default:
 		panic(fmt.Errorf("unknown transport protocol %d", tp))
 	}
 }
 
+// IPv6 returns true if the protocol table has IPv6 addresses.
+func (tp TransportProtocol) IPv6() bool {
+	return tp == TypeTCP6 || tp == TypeRaw6 || tp == TypeICMP6
+}
+
 type netColumnIndex int
 
 const (
@@ -63,24 +94,50 @@ const (
 	net_tcp_idx_inode
 )
 
+// TCPStates maps the numeric TCP state in '/proc/net/tcp' 'st' column
+// (e.g. 0x01, 0x0A) to its name.
+// https://github.com/torvalds/linux/blob/master/include/net/tcp_states.h
+var TCPStates = map[int]string{
+	0x01: "ESTABLISHED",
+	0x02: "SYN_SENT",
+	0x03: "SYN_RECV",
+	0x04: "FIN_WAIT1",
+	0x05: "FIN_WAIT2",
+	0x06: "TIME_WAIT",
+	0x07: "CLOSE",
+	0x08: "CLOSE_WAIT",
+	0x09: "LAST_ACK",
+	0x0A: "LISTEN",
+	0x0B: "CLOSING",
+}
+
 var (
-	// RPC_SHOW_SOCK
-	// https://github.com/torvalds/linux/blob/master/include/trace/events/sunrpc.h
-	netTCPStatus = map[string]string{
-		"01": "ESTABLISHED",
-		"02": "SYN_SENT",
-		"03": "SYN_RECV",
-		"04": "FIN_WAIT1",
-		"05": "FIN_WAIT2",
-		"06": "TIME_WAIT",
-		"07": "CLOSE",
-		"08": "CLOSE_WAIT",
-		"09": "LAST_ACK",
-		"0A": "LISTEN",
-		"0B": "CLOSING",
-	}
+	// netTCPStatus maps the hex 'st' column (e.g. "0A") to the state name.
+	netTCPStatus = make(map[string]string, len(TCPStates))
+	// tcpStateCodes is the reverse of 'TCPStates'.
+	tcpStateCodes = make(map[string]int, len(TCPStates))
 )
 
+func init() {
+	for code, name := range TCPStates {
+		netTCPStatus[fmt.Sprintf("%02X", code)] = name
+		tcpStateCodes[name] = code
+	}
+}
+
+// TCPStateName returns the name of the numeric TCP state,
+// or an empty string if unknown.
+func TCPStateName(code int) string {
+	return TCPStates[code]
+}
+
+// TCPStateCode returns the numeric TCP state of the name
+// (e.g. "LISTEN" returns 0x0A).
+func TCPStateCode(name string) (int, bool) {
+	code, ok := tcpStateCodes[name]
+	return code, ok
+}
+
 func parseNetTCP(d []byte, ipParse func(string) (string, int64, error), ipType string) ([]NetTCP, error) {
 	rows := [][]string{}
 
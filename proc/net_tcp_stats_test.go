@@ -0,0 +1,41 @@
+package proc
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseNetTCPStats(t *testing.T) {
+	d := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:0016 00000000:0000 0A 00000000:00000002 00:00000000 00000000     0        0 12345 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:1F90 0100007F:D2F0 01 00000010:00000000 00:00000000 00000000  1000        0 23456 1 0000000000000000 20 4 30 10 -1
+   2: 0100007F:D2F0 0100007F:1F90 01 00000000:00000020 00:00000000 00000000  1000        0 34567 1 0000000000000000 20 4 30 10 -1
+   3: 0100007F:D2F2 0100007F:1F90 06 00000000:00000000 03:00001770 00000000     0        0 0 3 0000000000000000
+`
+	st, err := parseNetTCPStats(bufio.NewScanner(strings.NewReader(d)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Total != 4 {
+		t.Fatalf("expected 4 sockets, got %d", st.Total)
+	}
+	est := st.States["ESTABLISHED"]
+	if est.Count != 2 || est.TxQueueBytes != 0x10 || est.RxQueueBytes != 0x20 {
+		t.Fatalf("unexpected ESTABLISHED %+v", est)
+	}
+	if st.States["LISTEN"].Count != 1 || st.States["TIME_WAIT"].Count != 1 {
+		t.Fatalf("unexpected states %+v", st.States)
+	}
+}
+
+func TestGetNetTCPStats(t *testing.T) {
+	for _, tp := range []TransportProtocol{TypeTCP, TypeTCP6} {
+		st, err := GetNetTCPStats(tp)
+		if err != nil {
+			t.Skip(err)
+		}
+		fmt.Printf("%+v\n", st)
+	}
+}
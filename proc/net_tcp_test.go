@@ -100,3 +100,25 @@ func searchInode(fds []string, inode string) (pid int64) {
 	}
 	return
 }
+
+func TestTCPStateName(t *testing.T) {
+	if len(netTCPStatus) != len(TCPStates) || netTCPStatus["0A"] != "LISTEN" {
+		t.Fatalf("unexpected hex states %v", netTCPStatus)
+	}
+	for hexCode, name := range netTCPStatus {
+		var code int
+		if _, err := fmt.Sscanf(hexCode, "%X", &code); err != nil {
+			t.Fatal(err)
+		}
+		if TCPStateName(code) != name {
+			t.Fatalf("%s expected %q, got %q", hexCode, name, TCPStateName(code))
+		}
+		c, ok := TCPStateCode(name)
+		if !ok || c != code {
+			t.Fatalf("%q expected %d, got %d (%v)", name, code, c, ok)
+		}
+	}
+	if _, ok := TCPStateCode("UNKNOWN"); ok {
+		t.Fatal("expected unknown state")
+	}
+}
@@ -0,0 +1,192 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NetUnix is a unix domain socket entry in '/proc/net/unix'.
+// Reference http://man7.org/linux/man-pages/man5/proc.5.html.
+type NetUnix struct {
+	RefCount uint64
+	// Flags is the socket flags (e.g. __SO_ACCEPTCON 0x10000 for listening sockets).
+	Flags uint64
+	// Type is "stream", "dgram" or "seqpacket".
+	Type string
+	// State is "LISTEN", "UNCONNECTED", "CONNECTING", "CONNECTED" or "DISCONNECTING".
+	State string
+	Inode uint64
+	// Path is the bound path, empty for unnamed sockets.
+	// Abstract socket names start with '@'.
+	Path string
+}
+
+// netUnixFlagAcceptCon is set on listening sockets.
+const netUnixFlagAcceptCon = 0x10000
+
+var (
+	// https://github.com/torvalds/linux/blob/master/include/linux/net.h
+	netUnixType = map[string]string{
+		"0001": "stream",
+		"0002": "dgram",
+		"0005": "seqpacket",
+	}
+	netUnixState = map[string]string{
+		"00": "FREE",
+		"01": "UNCONNECTED",
+		"02": "CONNECTING",
+		"03": "CONNECTED",
+		"04": "DISCONNECTING",
+	}
+)
+
+type netUnixColumnIndex int
+
+const (
+	net_unix_idx_num netUnixColumnIndex = iota
+	net_unix_idx_refcount
+	net_unix_idx_protocol
+	net_unix_idx_flags
+	net_unix_idx_type
+	net_unix_idx_st
+	net_unix_idx_inode
+	net_unix_idx_path
+)
+
+// GetNetUnix reads '/proc/net/unix'.
+func GetNetUnix() ([]NetUnix, error) {
+	d, err := ioutil.ReadFile("/proc/net/unix")
+	if err != nil {
+		return nil, err
+	}
+	return parseNetUnix(d)
+}
+
+func parseNetUnix(d []byte) ([]NetUnix, error) {
+	var us []NetUnix
+	scanner := bufio.NewScanner(bytes.NewReader(d))
+	first := true
+	for scanner.Scan() {
+		txt := scanner.Text()
+		if first {
+			first = false
+			continue
+		}
+		fields := strings.Fields(txt)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < int(net_unix_idx_inode)+1 {
+			return nil, fmt.Errorf("not enough columns at %v", fields)
+		}
+
+		refcount, err := strconv.ParseUint(fields[net_unix_idx_refcount], 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		flags, err := strconv.ParseUint(fields[net_unix_idx_flags], 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		inode, err := strconv.ParseUint(fields[net_unix_idx_inode], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		tp, ok := netUnixType[fields[net_unix_idx_type]]
+		if !ok {
+			tp = fields[net_unix_idx_type]
+		}
+		st, ok := netUnixState[fields[net_unix_idx_st]]
+		if !ok {
+			st = fields[net_unix_idx_st]
+		}
+		if flags&netUnixFlagAcceptCon != 0 {
+			st = "LISTEN"
+		}
+
+		u := NetUnix{
+			RefCount: refcount,
+			Flags:    flags,
+			Type:     tp,
+			State:    st,
+			Inode:    inode,
+		}
+		if len(fields) > int(net_unix_idx_path) {
+			// path may contain spaces
+			u.Path = strings.Join(fields[net_unix_idx_path:], " ")
+		}
+		us = append(us, u)
+	}
+	return us, scanner.Err()
+}
+
+// SocketFD is an open file descriptor of a socket.
+type SocketFD struct {
+	FD    int64
+	Inode uint64
+}
+
+// GetSocketFDsByPID returns the socket file descriptors in '/proc/$PID/fd'
+// (links of the form "socket:[12345]"), sorted by FD.
+func GetSocketFDsByPID(pid int64) ([]SocketFD, error) {
+	dir := fmt.Sprintf("/proc/%d/fd", pid)
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	names, err := f.Readdirnames(-1)
+	if err != nil {
+		return nil, err
+	}
+	var sfds []SocketFD
+	for _, name := range names {
+		fd, perr := strconv.ParseInt(name, 10, 64)
+		if perr != nil {
+			continue
+		}
+		link, lerr := os.Readlink(filepath.Join(dir, name))
+		if lerr != nil {
+			// fd closed since Readdirnames
+			continue
+		}
+		if inode, ok := parseSocketLink(link); ok {
+			sfds = append(sfds, SocketFD{FD: fd, Inode: inode})
+		}
+	}
+	sort.Slice(sfds, func(i, j int) bool { return sfds[i].FD < sfds[j].FD })
+	return sfds, nil
+}
+
+// GetSocketInodesByPID returns the socket inodes of the open file
+// descriptors in '/proc/$PID/fd' (links of the form "socket:[12345]").
+func GetSocketInodesByPID(pid int64) ([]uint64, error) {
+	sfds, err := GetSocketFDsByPID(pid)
+	if err != nil {
+		return nil, err
+	}
+	inodes := make([]uint64, len(sfds))
+	for i, sfd := range sfds {
+		inodes[i] = sfd.Inode
+	}
+	return inodes, nil
+}
+
+func parseSocketLink(link string) (uint64, bool) {
+	if !strings.HasPrefix(link, "socket:[") || !strings.HasSuffix(link, "]") {
+		return 0, false
+	}
+	inode, err := strconv.ParseUint(link[len("socket:["):len(link)-1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return inode, true
+}
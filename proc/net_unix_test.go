@@ -0,0 +1,80 @@
+package proc
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"testing"
+)
+
+func TestParseNetUnix(t *testing.T) {
+	us, err := parseNetUnix([]byte(`Num       RefCount Protocol Flags    Type St Inode Path
+000000003b2f23db: 00000003 00000000 00000000 0001 03   658
+00000000bbb4666d: 00000002 00000000 00010000 0001 01 74176 /run/docker.sock
+00000000f241dc85: 00000002 00000000 00000000 0002 01 75374 @/org/my app
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(us) != 3 {
+		t.Fatalf("expected 3 sockets, got %d", len(us))
+	}
+	if us[0].State != "CONNECTED" || us[0].Type != "stream" || us[0].Path != "" || us[0].Inode != 658 {
+		t.Fatalf("unexpected %+v", us[0])
+	}
+	if us[1].State != "LISTEN" || us[1].Path != "/run/docker.sock" || us[1].Inode != 74176 {
+		t.Fatalf("unexpected %+v", us[1])
+	}
+	if us[2].Type != "dgram" || us[2].State != "UNCONNECTED" || us[2].Path != "@/org/my app" {
+		t.Fatalf("unexpected %+v", us[2])
+	}
+}
+
+func TestParseSocketLink(t *testing.T) {
+	if inode, ok := parseSocketLink("socket:[74176]"); !ok || inode != 74176 {
+		t.Fatalf("unexpected %d, %v", inode, ok)
+	}
+	if _, ok := parseSocketLink("pipe:[74176]"); ok {
+		t.Fatal("expected not ok")
+	}
+}
+
+func TestGetNetUnix(t *testing.T) {
+	us, err := GetNetUnix()
+	if err != nil {
+		t.Skip(err)
+	}
+	inodes, err := GetSocketInodesByPID(int64(os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Printf("%d unix sockets, %d socket inodes for self\n", len(us), len(inodes))
+}
+
+func TestGetSocketFDsByPID(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	f, err := ln.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	sfds, err := GetSocketFDsByPID(int64(os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for i, sfd := range sfds {
+		if i > 0 && sfds[i-1].FD >= sfd.FD {
+			t.Fatalf("not sorted by FD %+v", sfds)
+		}
+		found = found || sfd.FD == int64(f.Fd())
+	}
+	if !found {
+		t.Fatalf("FD %d not found in %+v", f.Fd(), sfds)
+	}
+}
@@ -0,0 +1,34 @@
+package proc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// GetNamespacesByPID reads the namespaces in '/proc/$PID/ns', mapping
+// the namespace type to its identifier (e.g. "net" to "net:[4026531992]").
+// Processes in the same namespace have the same identifier.
+func GetNamespacesByPID(pid int64) (map[string]string, error) {
+	dir := fmt.Sprintf("/proc/%d/ns", pid)
+	fs, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	nss := make(map[string]string, len(fs))
+	for _, f := range fs {
+		id, err := os.Readlink(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		nss[f.Name()] = id
+	}
+	return nss, nil
+}
+
+// GetNamespaceByPID returns the identifier of the namespace type
+// (e.g. "net" returns "net:[4026531992]").
+func GetNamespaceByPID(pid int64, tp string) (string, error) {
+	return os.Readlink(fmt.Sprintf("/proc/%d/ns/%s", pid, tp))
+}
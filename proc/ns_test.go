@@ -0,0 +1,40 @@
+package proc
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGetNamespacesByPID(t *testing.T) {
+	nss, err := GetNamespacesByPID(int64(os.Getpid()))
+	if err != nil {
+		t.Skip(err)
+	}
+	if id, ok := nss["net"]; ok && !strings.HasPrefix(id, "net:[") {
+		t.Fatalf("unexpected net namespace %q", id)
+	}
+	fmt.Println("GetNamespacesByPID:", nss)
+
+	if id, ok := nss["net"]; ok {
+		nid, err := GetNamespaceByPID(int64(os.Getpid()), "net")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nid != id {
+			t.Fatalf("expected %q, got %q", id, nid)
+		}
+	}
+}
+
+func TestGetFDCountByPID(t *testing.T) {
+	n, err := GetFDCountByPID(int64(os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// at least stdin, stdout, stderr
+	if n < 3 {
+		t.Fatalf("expected at least 3 FDs, got %d", n)
+	}
+}
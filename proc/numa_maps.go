@@ -0,0 +1,123 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/gyuho/linux-inspect/pkg/fileutil"
+)
+
+// NumaStats is the summary of '/proc/$PID/numa_maps', the memory
+// placement of a process across NUMA nodes.
+// Reference http://man7.org/linux/man-pages/man7/numa.7.html.
+type NumaStats struct {
+	// Mappings is the number of memory mappings.
+	Mappings int
+
+	// NodePages maps the NUMA node to the number of pages on the node.
+	NodePages map[int]uint64
+	// NodeBytes maps the NUMA node to the bytes on the node,
+	// accounting for huge pages.
+	NodeBytes map[int]uint64
+
+	// Anon is the number of anonymous pages.
+	Anon uint64
+	// File is the number of pages in file-backed mappings.
+	File uint64
+	// Mapped is the number of pages mapped from files.
+	Mapped uint64
+	// Dirty is the number of dirty pages.
+	Dirty uint64
+	// Swapcache is the number of pages in swap cache.
+	Swapcache uint64
+}
+
+// GetNumaStatsByPID reads '/proc/$PID/numa_maps' (requires CONFIG_NUMA).
+func GetNumaStatsByPID(pid int64) (NumaStats, error) {
+	fpath := fmt.Sprintf("/proc/%d/numa_maps", pid)
+	f, err := fileutil.OpenToRead(fpath)
+	if err != nil {
+		return NumaStats{}, err
+	}
+	defer f.Close()
+
+	d, err := ioutil.ReadAll(f)
+	if err != nil {
+		return NumaStats{}, err
+	}
+	return parseNumaMaps(d)
+}
+
+// parseNumaMaps aggregates lines like
+// '7f1c0a000000 default file=/usr/lib/libc.so.6 mapped=5 N0=3 N1=2 kernelpagesize_kB=4'.
+func parseNumaMaps(d []byte) (NumaStats, error) {
+	ns := NumaStats{
+		NodePages: make(map[int]uint64),
+		NodeBytes: make(map[int]uint64),
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(d))
+	for scanner.Scan() {
+		fs := strings.Fields(scanner.Text())
+		if len(fs) < 2 {
+			continue
+		}
+		ns.Mappings++
+
+		pageSize := uint64(4096)
+		isFile := false
+		var pages uint64
+		nodes := make(map[int]uint64)
+		for _, kv := range fs[2:] {
+			i := strings.IndexByte(kv, '=')
+			if i < 0 {
+				// e.g. 'heap', 'stack', 'huge'
+				continue
+			}
+			k, v := kv[:i], kv[i+1:]
+			if k == "file" {
+				isFile = true
+				continue
+			}
+			if k == "policy" || strings.HasPrefix(k, "bind") {
+				continue
+			}
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				// non-numeric values (e.g. 'interleave:0-1')
+				continue
+			}
+			switch {
+			case k == "kernelpagesize_kB":
+				pageSize = n * 1024
+			case k == "anon":
+				ns.Anon += n
+			case k == "mapped":
+				ns.Mapped += n
+			case k == "dirty":
+				ns.Dirty += n
+			case k == "swapcache":
+				ns.Swapcache += n
+			case len(k) > 1 && k[0] == 'N':
+				node, err := strconv.Atoi(k[1:])
+				if err != nil {
+					continue
+				}
+				nodes[node] += n
+				pages += n
+			}
+		}
+		for node, n := range nodes {
+			ns.NodePages[node] += n
+			ns.NodeBytes[node] += n * pageSize
+		}
+		if isFile {
+			ns.File += pages
+		}
+	}
+	return ns, scanner.Err()
+}
@@ -0,0 +1,39 @@
+package proc
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestParseNumaMaps(t *testing.T) {
+	ns, err := parseNumaMaps([]byte(`55807df98000 default file=/usr/bin/cat mapped=2 N0=2 kernelpagesize_kB=4
+55807dfa2000 default file=/usr/bin/cat anon=1 dirty=1 active=0 N0=1 kernelpagesize_kB=4
+7f0000000000 interleave:0-1 anon=6 dirty=6 N0=2 N1=4 kernelpagesize_kB=4
+7f2000000000 default heap anon=3 dirty=3 swapcache=1 N1=3 kernelpagesize_kB=4
+7f4000000000 default huge anon=2 dirty=2 N1=2 kernelpagesize_kB=2048
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ns.Mappings != 5 {
+		t.Fatalf("expected 5 mappings, got %d", ns.Mappings)
+	}
+	if ns.NodePages[0] != 5 || ns.NodePages[1] != 9 {
+		t.Fatalf("unexpected node pages %v", ns.NodePages)
+	}
+	if ns.NodeBytes[1] != 7*4096+2*2048*1024 {
+		t.Fatalf("unexpected node bytes %v", ns.NodeBytes)
+	}
+	if ns.Anon != 12 || ns.File != 3 || ns.Mapped != 2 || ns.Dirty != 12 || ns.Swapcache != 1 {
+		t.Fatalf("unexpected stats %+v", ns)
+	}
+}
+
+func TestGetNumaStatsByPID(t *testing.T) {
+	ns, err := GetNumaStatsByPID(int64(os.Getpid()))
+	if err != nil {
+		t.Skip(err)
+	}
+	fmt.Printf("GetNumaStatsByPID: %+v\n", ns)
+}
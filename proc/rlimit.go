@@ -0,0 +1,73 @@
+package proc
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Resource limits for 'GetRlimitByPID'.
+// Reference http://man7.org/linux/man-pages/man2/prlimit.2.html.
+const (
+	RlimitCPU        = 0  // RLIMIT_CPU, CPU time in seconds
+	RlimitFsize      = 1  // RLIMIT_FSIZE, maximum file size in bytes
+	RlimitData       = 2  // RLIMIT_DATA, maximum data segment size in bytes
+	RlimitStack      = 3  // RLIMIT_STACK, maximum stack size in bytes
+	RlimitCore       = 4  // RLIMIT_CORE, maximum core file size in bytes
+	RlimitRSS        = 5  // RLIMIT_RSS, maximum resident set size in bytes
+	RlimitNproc      = 6  // RLIMIT_NPROC, maximum number of processes
+	RlimitNofile     = 7  // RLIMIT_NOFILE, maximum number of open files
+	RlimitMemlock    = 8  // RLIMIT_MEMLOCK, maximum locked-in-memory address space in bytes
+	RlimitAS         = 9  // RLIMIT_AS, maximum virtual memory size in bytes
+	RlimitLocks      = 10 // RLIMIT_LOCKS, maximum number of file locks
+	RlimitSigpending = 11 // RLIMIT_SIGPENDING, maximum number of pending signals
+	RlimitMsgqueue   = 12 // RLIMIT_MSGQUEUE, maximum bytes in POSIX message queues
+	RlimitNice       = 13 // RLIMIT_NICE, ceiling for nice value
+	RlimitRtprio     = 14 // RLIMIT_RTPRIO, ceiling for real-time priority
+	RlimitRttime     = 15 // RLIMIT_RTTIME, real-time CPU time in microseconds
+)
+
+// RlimitNames maps the resource to its name.
+var RlimitNames = map[int]string{
+	RlimitCPU:        "RLIMIT_CPU",
+	RlimitFsize:      "RLIMIT_FSIZE",
+	RlimitData:       "RLIMIT_DATA",
+	RlimitStack:      "RLIMIT_STACK",
+	RlimitCore:       "RLIMIT_CORE",
+	RlimitRSS:        "RLIMIT_RSS",
+	RlimitNproc:      "RLIMIT_NPROC",
+	RlimitNofile:     "RLIMIT_NOFILE",
+	RlimitMemlock:    "RLIMIT_MEMLOCK",
+	RlimitAS:         "RLIMIT_AS",
+	RlimitLocks:      "RLIMIT_LOCKS",
+	RlimitSigpending: "RLIMIT_SIGPENDING",
+	RlimitMsgqueue:   "RLIMIT_MSGQUEUE",
+	RlimitNice:       "RLIMIT_NICE",
+	RlimitRtprio:     "RLIMIT_RTPRIO",
+	RlimitRttime:     "RLIMIT_RTTIME",
+}
+
+// RlimInfinity is the limit value for 'unlimited' (RLIM_INFINITY).
+const RlimInfinity = ^uint64(0)
+
+// GetRlimitByPID returns the soft and hard limits of the resource
+// (e.g. 'RlimitNofile') for the PID, using 'prlimit64' syscall.
+// It's cheaper than parsing the whole '/proc/$PID/limits' when
+// only one limit is needed. Unlimited is returned as 'RlimInfinity'.
+// Querying other users' processes requires CAP_SYS_RESOURCE.
+func GetRlimitByPID(pid int64, resource int) (soft, hard uint64, err error) {
+	var rlim unix.Rlimit
+	_, _, errno := unix.RawSyscall6(
+		unix.SYS_PRLIMIT64,
+		uintptr(pid),
+		uintptr(resource),
+		0, // new limit; nil to only read
+		uintptr(unsafe.Pointer(&rlim)),
+		0,
+		0,
+	)
+	if errno != 0 {
+		return 0, 0, errno
+	}
+	return rlim.Cur, rlim.Max, nil
+}
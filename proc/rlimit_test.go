@@ -0,0 +1,25 @@
+package proc
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestGetRlimitByPID(t *testing.T) {
+	soft, hard, err := GetRlimitByPID(int64(os.Getpid()), RlimitNofile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println("RLIMIT_NOFILE:", soft, hard)
+
+	var rlim unix.Rlimit
+	if err = unix.Getrlimit(unix.RLIMIT_NOFILE, &rlim); err != nil {
+		t.Fatal(err)
+	}
+	if soft != rlim.Cur || hard != rlim.Max {
+		t.Fatalf("expected %d/%d, got %d/%d", rlim.Cur, rlim.Max, soft, hard)
+	}
+}
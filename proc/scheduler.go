@@ -0,0 +1,70 @@
+package proc
+
+import "fmt"
+
+// Scheduling policies in 'policy' of '/proc/$PID/stat'.
+// Reference http://man7.org/linux/man-pages/man7/sched.7.html.
+const (
+	SchedOther    = 0
+	SchedFIFO     = 1
+	SchedRR       = 2
+	SchedBatch    = 3
+	SchedIdle     = 5
+	SchedDeadline = 6
+)
+
+// Scheduler is the scheduling policy and priority of a process,
+// from 'policy', 'rt_priority' and 'nice' in '/proc/$PID/stat'.
+type Scheduler struct {
+	Policy uint64
+	// PolicyParsedStatus is the name of the policy (e.g. "SCHED_FIFO").
+	PolicyParsedStatus string
+	// RtPriority is 1 to 99 for real-time policies, 0 otherwise.
+	RtPriority uint64
+	// Nice is the nice value for non-real-time policies.
+	Nice int64
+}
+
+// Realtime returns true if the process is scheduled under a real-time
+// policy (SCHED_FIFO, SCHED_RR, SCHED_DEADLINE), which can starve
+// normal processes when it does not yield.
+func (s Scheduler) Realtime() bool {
+	switch s.Policy {
+	case SchedFIFO, SchedRR, SchedDeadline:
+		return true
+	}
+	return false
+}
+
+// GetSchedulerByPID reads the scheduling policy from '/proc/$PID/stat'.
+func GetSchedulerByPID(pid int64) (Scheduler, error) {
+	st, err := GetStatByPID(pid)
+	if err != nil {
+		return Scheduler{}, err
+	}
+	return Scheduler{
+		Policy:             st.Policy,
+		PolicyParsedStatus: convertSchedPolicy(st.Policy),
+		RtPriority:         st.RtPriority,
+		Nice:               st.Nice,
+	}, nil
+}
+
+func convertSchedPolicy(policy uint64) string {
+	switch policy {
+	case SchedOther:
+		return "SCHED_OTHER"
+	case SchedFIFO:
+		return "SCHED_FIFO"
+	case SchedRR:
+		return "SCHED_RR"
+	case SchedBatch:
+		return "SCHED_BATCH"
+	case SchedIdle:
+		return "SCHED_IDLE"
+	case SchedDeadline:
+		return "SCHED_DEADLINE"
+	default:
+		return fmt.Sprintf("unknown scheduling policy %d", policy)
+	}
+}
@@ -0,0 +1,25 @@
+package proc
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestGetSchedulerByPID(t *testing.T) {
+	s, err := GetSchedulerByPID(int64(os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.PolicyParsedStatus != convertSchedPolicy(s.Policy) {
+		t.Fatalf("unexpected policy %q for %d", s.PolicyParsedStatus, s.Policy)
+	}
+	if s.Realtime() != (s.RtPriority > 0) {
+		t.Fatalf("unexpected real-time %+v", s)
+	}
+	fmt.Printf("GetSchedulerByPID: %+v\n", s)
+
+	if !(Scheduler{Policy: SchedFIFO}).Realtime() || (Scheduler{Policy: SchedBatch}).Realtime() {
+		t.Fatal("unexpected Realtime")
+	}
+}
@@ -146,6 +146,76 @@ var IOSchema = schema.RawData{
 	},
 }
 
+// MeminfoSchema represents '/proc/meminfo'.
+// Reference http://man7.org/linux/man-pages/man5/proc.5.html.
+var MeminfoSchema = schema.RawData{
+	IsYAML: true,
+	Columns: []schema.Column{
+		{Name: "MemTotal", Godoc: "total usable RAM (physical RAM minus a few reserved bits and the kernel binary code)", Kind: reflect.String},
+		{Name: "MemFree", Godoc: "sum of LowFree+HighFree", Kind: reflect.String},
+		{Name: "MemAvailable", Godoc: "estimate of how much memory is available for starting new applications, without swapping", Kind: reflect.String},
+
+		{Name: "Buffers", Godoc: "relatively temporary storage for raw disk blocks", Kind: reflect.String},
+		{Name: "Cached", Godoc: "in-memory cache for files read from the disk (the page cache), not including SwapCached", Kind: reflect.String},
+		{Name: "SwapCached", Godoc: "memory that once was swapped out, is swapped back in but still also is in the swap file", Kind: reflect.String},
+		{Name: "Active", Godoc: "memory that has been used more recently and usually not reclaimed unless absolutely necessary", Kind: reflect.String},
+		{Name: "Inactive", Godoc: "memory which has been less recently used, more eligible to be reclaimed for other purposes", Kind: reflect.String},
+
+		{Name: "SwapTotal", Godoc: "total amount of swap space available", Kind: reflect.String},
+		{Name: "SwapFree", Godoc: "amount of swap space that is currently unused", Kind: reflect.String},
+
+		{Name: "Dirty", Godoc: "memory which is waiting to get written back to the disk", Kind: reflect.String},
+		{Name: "Writeback", Godoc: "memory which is actively being written back to the disk", Kind: reflect.String},
+		{Name: "AnonPages", Godoc: "non-file backed pages mapped into user-space page tables", Kind: reflect.String},
+		{Name: "Mapped", Godoc: "files which have been mapped into memory (with mmap), such as libraries", Kind: reflect.String},
+		{Name: "Shmem", Godoc: "amount of memory consumed in tmpfs filesystems", Kind: reflect.String},
+		{Name: "Slab", Godoc: "in-kernel data structures cache", Kind: reflect.String},
+		{Name: "SReclaimable", Godoc: "part of Slab, that might be reclaimed, such as caches", Kind: reflect.String},
+		{Name: "SUnreclaim", Godoc: "part of Slab, that cannot be reclaimed on memory pressure", Kind: reflect.String},
+		{Name: "KernelStack", Godoc: "amount of memory allocated to kernel stacks", Kind: reflect.String},
+		{Name: "PageTables", Godoc: "amount of memory dedicated to the lowest level of page tables", Kind: reflect.String},
+		{Name: "CommitLimit", Godoc: "total amount of memory currently available to be allocated on the system, based on the overcommit ratio", Kind: reflect.String},
+		{Name: "Committed_AS", Godoc: "amount of memory presently allocated on the system", Kind: reflect.String},
+		{Name: "VmallocTotal", Godoc: "total size of vmalloc memory area", Kind: reflect.String},
+		{Name: "VmallocUsed", Godoc: "amount of vmalloc area which is used", Kind: reflect.String},
+		{Name: "AnonHugePages", Godoc: "non-file backed huge pages mapped into user-space page tables", Kind: reflect.String},
+		{Name: "Hugepagesize", Godoc: "size of huge pages", Kind: reflect.String},
+
+		{Name: "HugePages_Total", Godoc: "size of the pool of huge pages", Kind: reflect.Uint64},
+		{Name: "HugePages_Free", Godoc: "number of huge pages in the pool that are not yet allocated", Kind: reflect.Uint64},
+		{Name: "HugePages_Rsvd", Godoc: "number of huge pages for which a commitment to allocate from the pool has been made, but no allocation has yet been made", Kind: reflect.Uint64},
+		{Name: "HugePages_Surp", Godoc: "number of huge pages in the pool above the value in /proc/sys/vm/nr_hugepages", Kind: reflect.Uint64},
+	},
+	ColumnsToParse: map[string]schema.RawDataType{
+		"MemTotal":      schema.TypeBytes,
+		"MemFree":       schema.TypeBytes,
+		"MemAvailable":  schema.TypeBytes,
+		"Buffers":       schema.TypeBytes,
+		"Cached":        schema.TypeBytes,
+		"SwapCached":    schema.TypeBytes,
+		"Active":        schema.TypeBytes,
+		"Inactive":      schema.TypeBytes,
+		"SwapTotal":     schema.TypeBytes,
+		"SwapFree":      schema.TypeBytes,
+		"Dirty":         schema.TypeBytes,
+		"Writeback":     schema.TypeBytes,
+		"AnonPages":     schema.TypeBytes,
+		"Mapped":        schema.TypeBytes,
+		"Shmem":         schema.TypeBytes,
+		"Slab":          schema.TypeBytes,
+		"SReclaimable":  schema.TypeBytes,
+		"SUnreclaim":    schema.TypeBytes,
+		"KernelStack":   schema.TypeBytes,
+		"PageTables":    schema.TypeBytes,
+		"CommitLimit":   schema.TypeBytes,
+		"Committed_AS":  schema.TypeBytes,
+		"VmallocTotal":  schema.TypeBytes,
+		"VmallocUsed":   schema.TypeBytes,
+		"AnonHugePages": schema.TypeBytes,
+		"Hugepagesize":  schema.TypeBytes,
+	},
+}
+
 // StatSchema represents '/proc/$PID/stat'.
 // Reference http://man7.org/linux/man-pages/man5/proc.5.html.
 var StatSchema = schema.RawData{
@@ -237,6 +307,7 @@ var StatusSchema = schema.RawData{
 		{Name: "NSpid", Godoc: "thread ID (i.e., PID) in each of the PID namespaces of which [pid] is a member", Kind: reflect.String},
 		{Name: "NSpgid", Godoc: "process group ID (i.e., PID) in each of the PID namespaces of which [pid] is a member", Kind: reflect.String},
 		{Name: "NSsid", Godoc: "descendant namespace session ID hierarchy Session ID in each of the PID namespaces of which [pid] is a member", Kind: reflect.String},
+		{Name: "Kthread", Godoc: "1 if the process is a kernel thread, 0 otherwise (since Linux 6.4)", Kind: reflect.Uint64},
 
 		{Name: "VmPeak", Godoc: "peak virtual memory usage. Vm includes physical memory and swap", Kind: reflect.String},
 		{Name: "VmSize", Godoc: "current virtual memory usage. VmSize is the total amount of memory required for this process", Kind: reflect.String},
@@ -244,6 +315,9 @@ var StatusSchema = schema.RawData{
 		{Name: "VmPin", Godoc: "pinned memory size (pages can't be moved, requires direct-access to physical memory)", Kind: reflect.String},
 		{Name: "VmHWM", Godoc: `peak resident set size ("high water mark")`, Kind: reflect.String},
 		{Name: "VmRSS", Godoc: "resident set size. VmRSS is the actual amount in memory. Some memory can be swapped out to physical disk. So this is the real memory usage of the process", Kind: reflect.String},
+		{Name: "RssAnon", Godoc: "size of resident anonymous memory", Kind: reflect.String},
+		{Name: "RssFile", Godoc: "size of resident file mappings", Kind: reflect.String},
+		{Name: "RssShmem", Godoc: "size of resident shared memory (includes System V shared memory, mappings from tmpfs, and shared anonymous mappings)", Kind: reflect.String},
 		{Name: "VmData", Godoc: "size of data segment", Kind: reflect.String},
 		{Name: "VmStk", Godoc: "size of stack", Kind: reflect.String},
 		{Name: "VmExe", Godoc: "size of text segments", Kind: reflect.String},
@@ -253,6 +327,10 @@ var StatusSchema = schema.RawData{
 		{Name: "VmSwap", Godoc: "swapped-out virtual memory size by anonymous private", Kind: reflect.String},
 		{Name: "HugetlbPages", Godoc: "size of hugetlb memory portions", Kind: reflect.String},
 
+		{Name: "CoreDumping", Godoc: "1 if the process is currently dumping core", Kind: reflect.Uint64},
+		{Name: "THP_enabled", Godoc: "1 if transparent huge pages are enabled for the process (not disabled by prctl)", Kind: reflect.Uint64},
+		{Name: "untag_mask", Godoc: "mask of the address bits that are not tagged (e.g. by Intel LAM or ARM TBI)", Kind: reflect.String},
+
 		{Name: "Threads", Godoc: "number of threads in process containing this thread (process)", Kind: reflect.Uint64},
 
 		{Name: "SigQ", Godoc: "queued signals for the real user ID of this process (queued signals / limits)", Kind: reflect.String},
@@ -269,7 +347,13 @@ var StatusSchema = schema.RawData{
 		{Name: "CapBnd", Godoc: "capability Bounding set", Kind: reflect.String},
 		{Name: "CapAmb", Godoc: "ambient capability set", Kind: reflect.String},
 
+		{Name: "NoNewPrivs", Godoc: "value of the no_new_privs bit", Kind: reflect.Uint64},
+
 		{Name: "Seccomp", Godoc: "seccomp mode of the process (0 means SECCOMP_MODE_DISABLED; 1 means SECCOMP_MODE_STRICT; 2 means SECCOMP_MODE_FILTER)", Kind: reflect.Uint64},
+		{Name: "Seccomp_filters", Godoc: "number of seccomp filters attached to the process", Kind: reflect.Uint64},
+
+		{Name: "Speculation_Store_Bypass", Godoc: "speculation flaw mitigation state (e.g. thread vulnerable)", Kind: reflect.String},
+		{Name: "SpeculationIndirectBranch", Godoc: "indirect branch speculation mode (e.g. conditional enabled)", Kind: reflect.String},
 
 		{Name: "Cpus_allowed", Godoc: "mask of CPUs on which this process may run", Kind: reflect.String},
 		{Name: "Cpus_allowed_list", Godoc: "list of CPUs on which this process may run", Kind: reflect.String},
@@ -287,6 +371,9 @@ var StatusSchema = schema.RawData{
 		"VmPin":        schema.TypeBytes,
 		"VmHWM":        schema.TypeBytes,
 		"VmRSS":        schema.TypeBytes,
+		"RssAnon":      schema.TypeBytes,
+		"RssFile":      schema.TypeBytes,
+		"RssShmem":     schema.TypeBytes,
 		"VmData":       schema.TypeBytes,
 		"VmStk":        schema.TypeBytes,
 		"VmExe":        schema.TypeBytes,
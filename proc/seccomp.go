@@ -0,0 +1,48 @@
+package proc
+
+import "fmt"
+
+// SeccompStatus is the seccomp status of a process,
+// from 'Seccomp' and 'Seccomp_filters' in '/proc/$PID/status'.
+// Reference http://man7.org/linux/man-pages/man2/seccomp.2.html.
+type SeccompStatus struct {
+	// Mode is 0 (SECCOMP_MODE_DISABLED), 1 (SECCOMP_MODE_STRICT),
+	// or 2 (SECCOMP_MODE_FILTER).
+	Mode uint64
+	// ModeParsedStatus is the name of the mode ("disabled", "strict", "filter").
+	ModeParsedStatus string
+	// Filters is the number of seccomp filters attached to the process.
+	// Always 0 on kernels without 'Seccomp_filters' (< 5.9).
+	Filters uint64
+}
+
+// Enabled returns true if the process runs in strict or filter mode.
+func (s SeccompStatus) Enabled() bool {
+	return s.Mode != 0
+}
+
+// GetSeccompByPID reads the seccomp status from '/proc/$PID/status'.
+func GetSeccompByPID(pid int64) (SeccompStatus, error) {
+	s, err := GetStatusByPID(pid)
+	if err != nil {
+		return SeccompStatus{}, err
+	}
+	return SeccompStatus{
+		Mode:             s.Seccomp,
+		ModeParsedStatus: convertSeccompMode(s.Seccomp),
+		Filters:          s.SeccompFilters,
+	}, nil
+}
+
+func convertSeccompMode(mode uint64) string {
+	switch mode {
+	case 0:
+		return "disabled"
+	case 1:
+		return "strict"
+	case 2:
+		return "filter"
+	default:
+		return fmt.Sprintf("unknown seccomp mode %d", mode)
+	}
+}
@@ -0,0 +1,18 @@
+package proc
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestGetSeccompByPID(t *testing.T) {
+	s, err := GetSeccompByPID(int64(os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ModeParsedStatus != convertSeccompMode(s.Mode) {
+		t.Fatalf("unexpected mode %q for %d", s.ModeParsedStatus, s.Mode)
+	}
+	fmt.Printf("GetSeccompByPID: %+v\n", s)
+}
@@ -0,0 +1,249 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gyuho/linux-inspect/pkg/fileutil"
+)
+
+// SmapsRollup is '/proc/$PID/smaps_rollup', the sum of all
+// '/proc/$PID/smaps' mappings. All sizes are in bytes.
+// Reference https://www.kernel.org/doc/Documentation/ABI/testing/procfs-smaps_rollup.
+type SmapsRollup struct {
+	Rss          uint64
+	Pss          uint64
+	PssDirty     uint64
+	PssAnon      uint64
+	PssFile      uint64
+	PssShmem     uint64
+	SharedClean  uint64
+	SharedDirty  uint64
+	PrivateClean uint64
+	PrivateDirty uint64
+	Referenced   uint64
+	Anonymous    uint64
+	Swap         uint64
+	SwapPss      uint64
+	Locked       uint64
+}
+
+// Uss returns the unique set size, the memory that would be freed
+// if the process exited: its private clean and dirty pages.
+// Unlike 'Rss', 'Pss' and 'Uss' do not double-count shared pages.
+func (s SmapsRollup) Uss() uint64 {
+	return s.PrivateClean + s.PrivateDirty
+}
+
+// fields maps the smaps keys to the fields.
+func (s *SmapsRollup) fields() map[string]*uint64 {
+	return map[string]*uint64{
+		"Rss":           &s.Rss,
+		"Pss":           &s.Pss,
+		"Pss_Dirty":     &s.PssDirty,
+		"Pss_Anon":      &s.PssAnon,
+		"Pss_File":      &s.PssFile,
+		"Pss_Shmem":     &s.PssShmem,
+		"Shared_Clean":  &s.SharedClean,
+		"Shared_Dirty":  &s.SharedDirty,
+		"Private_Clean": &s.PrivateClean,
+		"Private_Dirty": &s.PrivateDirty,
+		"Referenced":    &s.Referenced,
+		"Anonymous":     &s.Anonymous,
+		"Swap":          &s.Swap,
+		"SwapPss":       &s.SwapPss,
+		"Locked":        &s.Locked,
+	}
+}
+
+// add adds the sizes of 'o' to 's'.
+func (s *SmapsRollup) add(o SmapsRollup) {
+	fs := s.fields()
+	for k, v := range o.fields() {
+		*fs[k] += *v
+	}
+}
+
+// GetSmapsRollupByPID reads '/proc/$PID/smaps_rollup' (Linux 4.14+).
+// On older kernels, it sums '/proc/$PID/smaps' instead, which is slower.
+func GetSmapsRollupByPID(pid int64) (SmapsRollup, error) {
+	fpath := fmt.Sprintf("/proc/%d/smaps_rollup", pid)
+	f, err := fileutil.OpenToRead(fpath)
+	if os.IsNotExist(err) {
+		ms, err := GetSmapsByPID(pid)
+		if err != nil {
+			return SmapsRollup{}, err
+		}
+		return SumSmaps(ms), nil
+	}
+	if err != nil {
+		return SmapsRollup{}, err
+	}
+	defer f.Close()
+
+	d, err := ioutil.ReadAll(f)
+	if err != nil {
+		return SmapsRollup{}, err
+	}
+	return parseSmapsRollup(d)
+}
+
+// parseSmapsRollup parses lines like 'Pss:  500 kB'.
+// The first header line and unknown keys are ignored.
+func parseSmapsRollup(d []byte) (SmapsRollup, error) {
+	var s SmapsRollup
+	fields := s.fields()
+
+	scanner := bufio.NewScanner(bytes.NewReader(d))
+	for scanner.Scan() {
+		txt := strings.TrimSpace(scanner.Text())
+		if len(txt) == 0 {
+			continue
+		}
+		if err := parseSmapsLine(fields, txt); err != nil {
+			return SmapsRollup{}, err
+		}
+	}
+	return s, scanner.Err()
+}
+
+// parseSmapsLine parses 'Pss:  500 kB' into the field.
+// Lines with unknown keys are ignored.
+func parseSmapsLine(fields map[string]*uint64, txt string) error {
+	kv := strings.SplitN(txt, ":", 2)
+	if len(kv) != 2 {
+		return nil
+	}
+	p, ok := fields[strings.TrimSpace(kv[0])]
+	if !ok {
+		return nil
+	}
+	v, err := parseKibibytes(strings.TrimSpace(kv[1]))
+	if err != nil {
+		return fmt.Errorf("%v when parsing %q", err, txt)
+	}
+	*p = v
+	return nil
+}
+
+// SmapsMapping is a memory mapping in '/proc/$PID/smaps'.
+// All sizes are in bytes.
+type SmapsMapping struct {
+	Start  uint64
+	End    uint64
+	Perms  string
+	Offset uint64
+	Dev    string
+	Inode  uint64
+	// Pathname is the mapped file, or a pseudo-path like '[heap]'
+	// and '[stack]'. It is empty for anonymous mappings.
+	Pathname string
+	Size     uint64
+
+	SmapsRollup
+}
+
+// GetSmapsByPID reads all mappings in '/proc/$PID/smaps'. It is
+// much slower than 'GetSmapsRollupByPID' for processes with many
+// mappings, since the kernel walks the page tables per mapping.
+func GetSmapsByPID(pid int64) ([]SmapsMapping, error) {
+	fpath := fmt.Sprintf("/proc/%d/smaps", pid)
+	f, err := fileutil.OpenToRead(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	d, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return parseSmaps(d)
+}
+
+// SumSmaps sums the mappings into 'SmapsRollup'.
+func SumSmaps(ms []SmapsMapping) SmapsRollup {
+	var s SmapsRollup
+	for _, m := range ms {
+		s.add(m.SmapsRollup)
+	}
+	return s
+}
+
+// parseSmaps parses the mapping headers like
+// '55592fdf2000-55592fe1a000 r--p 00000000 08:01 1234  /usr/bin/bash',
+// each followed by 'Rss:  4 kB' lines. Unknown keys (e.g. 'VmFlags')
+// are ignored.
+func parseSmaps(d []byte) ([]SmapsMapping, error) {
+	var (
+		ms     []SmapsMapping
+		fields map[string]*uint64
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(d))
+	for scanner.Scan() {
+		txt := strings.TrimSpace(scanner.Text())
+		if len(txt) == 0 {
+			continue
+		}
+		if k := strings.Fields(txt)[0]; strings.HasSuffix(k, ":") {
+			if fields == nil {
+				return nil, fmt.Errorf("no mapping header before %q", txt)
+			}
+			if k == "Size:" {
+				v, err := parseKibibytes(strings.TrimSpace(strings.TrimPrefix(txt, k)))
+				if err != nil {
+					return nil, fmt.Errorf("%v when parsing %q", err, txt)
+				}
+				ms[len(ms)-1].Size = v
+				continue
+			}
+			if err := parseSmapsLine(fields, txt); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		m, err := parseSmapsHeader(txt)
+		if err != nil {
+			return nil, err
+		}
+		ms = append(ms, m)
+		fields = ms[len(ms)-1].fields()
+	}
+	return ms, scanner.Err()
+}
+
+func parseSmapsHeader(txt string) (SmapsMapping, error) {
+	fs := strings.Fields(txt)
+	if len(fs) < 5 {
+		return SmapsMapping{}, fmt.Errorf("unexpected smaps header %q", txt)
+	}
+	addr := strings.SplitN(fs[0], "-", 2)
+	if len(addr) != 2 {
+		return SmapsMapping{}, fmt.Errorf("unexpected address range in %q", txt)
+	}
+	m := SmapsMapping{Perms: fs[1], Dev: fs[3]}
+	var err error
+	if m.Start, err = strconv.ParseUint(addr[0], 16, 64); err != nil {
+		return SmapsMapping{}, fmt.Errorf("%v when parsing %q", err, txt)
+	}
+	if m.End, err = strconv.ParseUint(addr[1], 16, 64); err != nil {
+		return SmapsMapping{}, fmt.Errorf("%v when parsing %q", err, txt)
+	}
+	if m.Offset, err = strconv.ParseUint(fs[2], 16, 64); err != nil {
+		return SmapsMapping{}, fmt.Errorf("%v when parsing %q", err, txt)
+	}
+	if m.Inode, err = strconv.ParseUint(fs[4], 10, 64); err != nil {
+		return SmapsMapping{}, fmt.Errorf("%v when parsing %q", err, txt)
+	}
+	if len(fs) > 5 {
+		// pathnames may contain spaces
+		m.Pathname = strings.Join(fs[5:], " ")
+	}
+	return m, nil
+}
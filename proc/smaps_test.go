@@ -0,0 +1,152 @@
+package proc
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gyuho/linux-inspect/pkg/timeutil"
+)
+
+func TestParseSmapsRollup(t *testing.T) {
+	s, err := parseSmapsRollup([]byte(`55592fdf2000-7fff6cd9f000 ---p 00000000 00:00 0                          [rollup]
+Rss:                1424 kB
+Pss:                 500 kB
+Shared_Clean:       1252 kB
+Shared_Dirty:          0 kB
+Private_Clean:        68 kB
+Private_Dirty:       104 kB
+Referenced:         1424 kB
+SwapPss:               8 kB
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Rss != 1424*1024 {
+		t.Fatalf("Rss expected %d, got %d", 1424*1024, s.Rss)
+	}
+	if s.Pss != 500*1024 {
+		t.Fatalf("Pss expected %d, got %d", 500*1024, s.Pss)
+	}
+	if s.PrivateDirty != 104*1024 {
+		t.Fatalf("PrivateDirty expected %d, got %d", 104*1024, s.PrivateDirty)
+	}
+	if s.SwapPss != 8*1024 {
+		t.Fatalf("SwapPss expected %d, got %d", 8*1024, s.SwapPss)
+	}
+}
+
+func TestMemBandwidthDelta(t *testing.T) {
+	s1 := SmapsRollup{Referenced: 1000, PrivateDirty: 100, Pss: 2000}
+	s2 := SmapsRollup{Referenced: 3000, PrivateDirty: 50, SharedDirty: 10, Pss: 2000}
+	mb := memBandwidthDelta(s1, s2, 2*time.Second)
+	if mb.ReferencedDelta != 2000 || mb.ReferencedPerSecond != 1000 {
+		t.Fatalf("unexpected referenced %+v", mb)
+	}
+	if mb.DirtyDelta != -40 || mb.DirtyPerSecond != -20 {
+		t.Fatalf("unexpected dirty %+v", mb)
+	}
+	if mb.PssDelta != 0 {
+		t.Fatalf("unexpected pss %+v", mb)
+	}
+}
+
+func TestGetMemBandwidthByPID(t *testing.T) {
+	mb, err := GetMemBandwidthByPID(int64(os.Getpid()), 100*time.Millisecond)
+	if err != nil {
+		t.Skip(err)
+	}
+	fmt.Printf("%+v\n", mb)
+}
+
+func TestGetMemBandwidthByPIDWithClock(t *testing.T) {
+	if _, err := GetSmapsRollupByPID(int64(os.Getpid())); err != nil {
+		t.Skip(err)
+	}
+	clock := timeutil.NewFakeClock(time.Unix(0, 0))
+	type result struct {
+		mb  MemBandwidth
+		err error
+	}
+	donec := make(chan result)
+	go func() {
+		mb, err := GetMemBandwidthByPIDWithClock(int64(os.Getpid()), time.Hour, clock)
+		donec <- result{mb, err}
+	}()
+	for clock.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(time.Hour)
+
+	rs := <-donec
+	if rs.err != nil {
+		t.Fatal(rs.err)
+	}
+	if rs.mb.Interval != time.Hour {
+		t.Fatalf("expected interval %v, got %v", time.Hour, rs.mb.Interval)
+	}
+}
+
+func TestParseSmaps(t *testing.T) {
+	ms, err := parseSmaps([]byte(`55592fdf2000-55592fe1a000 r--p 00000000 08:01 1234                       /usr/bin/my prog
+Size:                160 kB
+KernelPageSize:        4 kB
+Rss:                 160 kB
+Pss:                  80 kB
+Shared_Clean:        160 kB
+Shared_Dirty:          0 kB
+Private_Clean:         0 kB
+Private_Dirty:         0 kB
+Swap:                  0 kB
+THPeligible:    0
+VmFlags: rd mr mw me dw sd
+7fff6cd7e000-7fff6cd9f000 rw-p 00000000 00:00 0                          [stack]
+Size:                132 kB
+Rss:                  12 kB
+Pss:                  12 kB
+Private_Clean:         4 kB
+Private_Dirty:         8 kB
+Swap:                  4 kB
+SwapPss:               4 kB
+VmFlags: rd wr mr mw me gd ac
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ms) != 2 {
+		t.Fatalf("expected 2 mappings, got %+v", ms)
+	}
+	m := ms[0]
+	if m.Start != 0x55592fdf2000 || m.End != 0x55592fe1a000 || m.Perms != "r--p" || m.Dev != "08:01" || m.Inode != 1234 || m.Pathname != "/usr/bin/my prog" {
+		t.Fatalf("unexpected mapping %+v", m)
+	}
+	if m.Size != 160*1024 || m.Pss != 80*1024 || m.SharedClean != 160*1024 || m.Uss() != 0 {
+		t.Fatalf("unexpected sizes %+v", m)
+	}
+	if ms[1].Pathname != "[stack]" || ms[1].Uss() != 12*1024 {
+		t.Fatalf("unexpected mapping %+v", ms[1])
+	}
+
+	s := SumSmaps(ms)
+	if s.Rss != 172*1024 || s.Pss != 92*1024 || s.Uss() != 12*1024 || s.SwapPss != 4*1024 {
+		t.Fatalf("unexpected sum %+v", s)
+	}
+
+	if _, err = parseSmaps([]byte("Rss: 4 kB")); err == nil {
+		t.Fatal("expected error without header")
+	}
+}
+
+func TestGetSmapsByPID(t *testing.T) {
+	pid := int64(os.Getpid())
+	ms, err := GetSmapsByPID(pid)
+	if err != nil {
+		t.Skip(err)
+	}
+	s := SumSmaps(ms)
+	if s.Rss == 0 || s.Uss() > s.Rss {
+		t.Fatalf("unexpected sum %+v", s)
+	}
+	fmt.Printf("%d mappings, PSS %d, USS %d\n", len(ms), s.Pss, s.Uss())
+}
@@ -0,0 +1,337 @@
+package proc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// https://github.com/torvalds/linux/blob/master/include/uapi/linux/sock_diag.h
+// https://github.com/torvalds/linux/blob/master/include/uapi/linux/inet_diag.h
+const (
+	netlinkSockDiag   = 4  // NETLINK_SOCK_DIAG
+	sockDiagByFamily  = 20 // SOCK_DIAG_BY_FAMILY
+	inetDiagAllStates = 0xFFFFFFFF
+	inetDiagInfo      = 2 // INET_DIAG_INFO
+)
+
+type inetDiagSockID struct {
+	SPort  [2]byte
+	DPort  [2]byte
+	Src    [16]byte
+	Dst    [16]byte
+	If     uint32
+	Cookie [2]uint32
+}
+
+type inetDiagReqV2 struct {
+	Family   uint8
+	Protocol uint8
+	Ext      uint8
+	Pad      uint8
+	States   uint32
+	ID       inetDiagSockID
+}
+
+type inetDiagMsg struct {
+	Family  uint8
+	State   uint8
+	Timer   uint8
+	Retrans uint8
+	ID      inetDiagSockID
+	Expires uint32
+	RQueue  uint32
+	WQueue  uint32
+	UID     uint32
+	Inode   uint32
+}
+
+// nativeEndian is the host byte order, used by netlink headers
+// and by the '/proc/net/tcp' hex addresses.
+var nativeEndian binary.ByteOrder = func() binary.ByteOrder {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
+
+// TCPInfo is the kernel 'struct tcp_info' of a socket, as in 'ss -i'.
+// https://github.com/torvalds/linux/blob/master/include/uapi/linux/tcp.h
+type TCPInfo struct {
+	State       uint8 `json:"state"`
+	CAState     uint8 `json:"ca_state"`
+	Retransmits uint8 `json:"retransmits"`
+	Probes      uint8 `json:"probes"`
+	Backoff     uint8 `json:"backoff"`
+
+	// RTO, RTT, RTTVar are in microseconds.
+	RTO    uint32 `json:"rto_us"`
+	RTT    uint32 `json:"rtt_us"`
+	RTTVar uint32 `json:"rtt_var_us"`
+
+	SndMSS       uint32 `json:"snd_mss"`
+	RcvMSS       uint32 `json:"rcv_mss"`
+	Unacked      uint32 `json:"unacked"`
+	Lost         uint32 `json:"lost"`
+	Retrans      uint32 `json:"retrans"`
+	TotalRetrans uint32 `json:"total_retrans"`
+	SndCwnd      uint32 `json:"snd_cwnd"`
+	SndSsthresh  uint32 `json:"snd_ssthresh"`
+
+	// PacingRate, MaxPacingRate are in bytes per second.
+	// Only available in Linux 3.15+, otherwise zero.
+	PacingRate    uint64 `json:"pacing_rate"`
+	MaxPacingRate uint64 `json:"max_pacing_rate"`
+	// BytesAcked, BytesReceived are only available in Linux 4.1+.
+	BytesAcked    uint64 `json:"bytes_acked"`
+	BytesReceived uint64 `json:"bytes_received"`
+}
+
+// NetTCPInfo is a TCP socket with its 'TCPInfo'.
+type NetTCPInfo struct {
+	NetTCP
+	// Info is nil if the kernel does not report it
+	// (e.g. TIME_WAIT sockets).
+	Info *TCPInfo
+	// Ifindex is the interface the socket is bound to (the scope ID
+	// of link-local IPv6 addresses), 0 if not bound. '/proc/net/tcp6'
+	// does not report it, so it is only set by netlink.
+	Ifindex int
+}
+
+// GetNetTCPByNetlink dumps the TCP socket table of the current network
+// namespace with NETLINK_SOCK_DIAG (inet_diag), in one request instead
+// of reading '/proc/net/tcp(6)'. The results are formatted the same as
+// 'GetNetTCPByPID' (e.g. 'LocalAddress' in '0101007F:0035' form).
+func GetNetTCPByNetlink(tp TransportProtocol) ([]NetTCP, error) {
+	nis, err := dumpInetDiag(tp, 0)
+	if err != nil {
+		return nil, err
+	}
+	nss := make([]NetTCP, len(nis))
+	for i := range nis {
+		nss[i] = nis[i].NetTCP
+	}
+	return nss, nil
+}
+
+// GetNetTCPInfoByNetlink is 'GetNetTCPByNetlink' with the
+// per-socket 'TCPInfo' (INET_DIAG_INFO).
+func GetNetTCPInfoByNetlink(tp TransportProtocol) ([]NetTCPInfo, error) {
+	return dumpInetDiag(tp, 1<<(inetDiagInfo-1))
+}
+
+func dumpInetDiag(tp TransportProtocol, ext uint8) ([]NetTCPInfo, error) {
+	var family uint8
+	switch tp {
+	case TypeTCP:
+		family = syscall.AF_INET
+	case TypeTCP6:
+		family = syscall.AF_INET6
+	default:
+		return nil, fmt.Errorf("%s is not supported by netlink sock_diag", tp)
+	}
+
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, netlinkSockDiag)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+	defer syscall.Close(fd)
+
+	sa := &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}
+	if err = syscall.Bind(fd, sa); err != nil {
+		return nil, os.NewSyscallError("bind", err)
+	}
+
+	req := inetDiagReqV2{
+		Family:   family,
+		Protocol: syscall.IPPROTO_TCP,
+		Ext:      ext,
+		States:   inetDiagAllStates,
+	}
+	hdr := syscall.NlMsghdr{
+		Len:   uint32(syscall.NLMSG_HDRLEN + binary.Size(req)),
+		Type:  sockDiagByFamily,
+		Flags: syscall.NLM_F_REQUEST | syscall.NLM_F_DUMP,
+		Seq:   1,
+	}
+	buf := new(bytes.Buffer)
+	binary.Write(buf, nativeEndian, hdr)
+	binary.Write(buf, nativeEndian, req)
+	if err = syscall.Sendto(fd, buf.Bytes(), 0, sa); err != nil {
+		return nil, os.NewSyscallError("sendto", err)
+	}
+
+	var nss []NetTCPInfo
+	rb := make([]byte, 64*1024)
+	for {
+		n, _, rerr := syscall.Recvfrom(fd, rb, 0)
+		if rerr != nil {
+			return nil, os.NewSyscallError("recvfrom", rerr)
+		}
+		ns, done, perr := parseInetDiagMsgs(rb[:n], tp)
+		if perr != nil {
+			return nil, perr
+		}
+		nss = append(nss, ns...)
+		if done {
+			break
+		}
+	}
+	for i := range nss {
+		nss[i].Sl = uint64(i)
+	}
+	return nss, nil
+}
+
+// parseInetDiagMsgs parses one netlink read. It returns true
+// when the dump is complete (NLMSG_DONE).
+func parseInetDiagMsgs(b []byte, tp TransportProtocol) ([]NetTCPInfo, bool, error) {
+	msgs, err := syscall.ParseNetlinkMessage(b)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var ipParse func(string) (string, int64, error)
+	if tp.IPv6() {
+		ipParse = parseLittleEndianIpv6
+	} else {
+		ipParse = parseLittleEndianIpv4
+	}
+
+	var nss []NetTCPInfo
+	for _, m := range msgs {
+		switch m.Header.Type {
+		case syscall.NLMSG_DONE:
+			return nss, true, nil
+		case syscall.NLMSG_ERROR:
+			if len(m.Data) < 4 {
+				return nil, false, fmt.Errorf("short netlink error message (%d bytes)", len(m.Data))
+			}
+			errno := -int32(nativeEndian.Uint32(m.Data[:4]))
+			return nil, false, os.NewSyscallError("netlink", syscall.Errno(errno))
+		case sockDiagByFamily:
+		default:
+			continue
+		}
+
+		var dm inetDiagMsg
+		if err = binary.Read(bytes.NewReader(m.Data), nativeEndian, &dm); err != nil {
+			return nil, false, err
+		}
+
+		np := NetTCP{Type: tp.String()}
+		np.LocalAddress = formatProcNetAddr(dm.ID.Src, dm.ID.SPort, tp)
+		if np.LocalAddressParsedIPHost, np.LocalAddressParsedIPPort, err = ipParse(np.LocalAddress); err != nil {
+			return nil, false, err
+		}
+		np.RemAddress = formatProcNetAddr(dm.ID.Dst, dm.ID.DPort, tp)
+		if np.RemAddressParsedIPHost, np.RemAddressParsedIPPort, err = ipParse(np.RemAddress); err != nil {
+			return nil, false, err
+		}
+		np.St = fmt.Sprintf("%02X", dm.State)
+		np.StParsedStatus = netTCPStatus[np.St]
+		np.TxQueue = fmt.Sprintf("%08X", dm.WQueue)
+		np.RxQueue = fmt.Sprintf("%08X", dm.RQueue)
+		np.Tr = fmt.Sprintf("%02X", dm.Timer)
+		np.TmWhen = fmt.Sprintf("%08X", dm.Expires)
+		np.Retrnsmt = fmt.Sprintf("%08X", dm.Retrans)
+		np.Uid = uint64(dm.UID)
+		np.Inode = strconv.FormatUint(uint64(dm.Inode), 10)
+
+		info, err := parseInetDiagAttrs(m.Data[binary.Size(dm):])
+		if err != nil {
+			return nil, false, err
+		}
+		nss = append(nss, NetTCPInfo{NetTCP: np, Info: info, Ifindex: int(dm.ID.If)})
+	}
+	return nss, false, nil
+}
+
+// parseInetDiagAttrs returns the INET_DIAG_INFO attribute
+// in the route attributes following 'inet_diag_msg', if any.
+func parseInetDiagAttrs(b []byte) (*TCPInfo, error) {
+	for len(b) >= syscall.SizeofRtAttr {
+		l := int(nativeEndian.Uint16(b[0:2]))
+		tp := nativeEndian.Uint16(b[2:4])
+		if l < syscall.SizeofRtAttr || l > len(b) {
+			return nil, fmt.Errorf("not-valid netlink attribute length %d", l)
+		}
+		if tp == inetDiagInfo {
+			return parseTCPInfo(b[syscall.SizeofRtAttr:l]), nil
+		}
+		// attributes are 4-byte aligned
+		l = (l + syscall.RTA_ALIGNTO - 1) &^ (syscall.RTA_ALIGNTO - 1)
+		if l > len(b) {
+			break
+		}
+		b = b[l:]
+	}
+	return nil, nil
+}
+
+// parseTCPInfo parses 'struct tcp_info'. Older kernels report
+// a shorter struct, so the fields not reported are left zero.
+func parseTCPInfo(b []byte) *TCPInfo {
+	u8 := func(off int) uint8 {
+		if off+1 > len(b) {
+			return 0
+		}
+		return b[off]
+	}
+	u32 := func(off int) uint32 {
+		if off+4 > len(b) {
+			return 0
+		}
+		return nativeEndian.Uint32(b[off : off+4])
+	}
+	u64 := func(off int) uint64 {
+		if off+8 > len(b) {
+			return 0
+		}
+		return nativeEndian.Uint64(b[off : off+8])
+	}
+	return &TCPInfo{
+		State:       u8(0),
+		CAState:     u8(1),
+		Retransmits: u8(2),
+		Probes:      u8(3),
+		Backoff:     u8(4),
+
+		RTO:          u32(8),
+		SndMSS:       u32(16),
+		RcvMSS:       u32(20),
+		Unacked:      u32(24),
+		Lost:         u32(32),
+		Retrans:      u32(36),
+		RTT:          u32(68),
+		RTTVar:       u32(72),
+		SndSsthresh:  u32(76),
+		SndCwnd:      u32(80),
+		TotalRetrans: u32(100),
+
+		PacingRate:    u64(104),
+		MaxPacingRate: u64(112),
+		BytesAcked:    u64(120),
+		BytesReceived: u64(128),
+	}
+}
+
+// formatProcNetAddr formats the network-order address and port
+// the way '/proc/net/tcp(6)' does (e.g. '0101007F:0035').
+func formatProcNetAddr(addr [16]byte, port [2]byte, tp TransportProtocol) string {
+	words := 1
+	if tp.IPv6() {
+		words = 4
+	}
+	s := ""
+	for i := 0; i < words; i++ {
+		s += fmt.Sprintf("%08X", nativeEndian.Uint32(addr[i*4:i*4+4]))
+	}
+	return fmt.Sprintf("%s:%04X", s, binary.BigEndian.Uint16(port[:]))
+}
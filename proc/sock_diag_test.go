@@ -0,0 +1,147 @@
+package proc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+func TestParseInetDiagMsgs(t *testing.T) {
+	dm := inetDiagMsg{
+		Family: syscall.AF_INET,
+		State:  0x0A,
+		UID:    1000,
+		Inode:  12345,
+	}
+	binary.BigEndian.PutUint16(dm.ID.SPort[:], 53)
+	copy(dm.ID.Src[:], net.IPv4(127, 0, 0, 1).To4())
+	dm.ID.If = 2
+
+	buf := new(bytes.Buffer)
+	binary.Write(buf, nativeEndian, syscall.NlMsghdr{
+		Len:  uint32(syscall.NLMSG_HDRLEN + binary.Size(dm)),
+		Type: sockDiagByFamily,
+	})
+	binary.Write(buf, nativeEndian, dm)
+	binary.Write(buf, nativeEndian, syscall.NlMsghdr{
+		Len:  uint32(syscall.NLMSG_HDRLEN + 4),
+		Type: syscall.NLMSG_DONE,
+	})
+	binary.Write(buf, nativeEndian, int32(0))
+
+	nss, done, err := parseInetDiagMsgs(buf.Bytes(), TypeTCP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !done {
+		t.Fatal("expected done")
+	}
+	if len(nss) != 1 {
+		t.Fatalf("expected 1 socket, got %d", len(nss))
+	}
+	if nss[0].Info != nil {
+		t.Fatalf("unexpected info %+v", nss[0].Info)
+	}
+	if nss[0].Ifindex != 2 {
+		t.Fatalf("ifindex expected 2, got %d", nss[0].Ifindex)
+	}
+	np := nss[0].NetTCP
+	if nativeEndian == binary.LittleEndian && np.LocalAddress != "0100007F:0035" {
+		t.Fatalf("local address expected '0100007F:0035', got %q", np.LocalAddress)
+	}
+	if np.LocalAddressParsedIPHost != "127.0.0.1" || np.LocalAddressParsedIPPort != 53 {
+		t.Fatalf("unexpected local address %q:%d", np.LocalAddressParsedIPHost, np.LocalAddressParsedIPPort)
+	}
+	if np.StParsedStatus != "LISTEN" || np.Uid != 1000 || np.Inode != "12345" {
+		t.Fatalf("unexpected %+v", np)
+	}
+}
+
+func TestGetNetTCPByNetlink(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	nss, err := GetNetTCPByNetlink(TypeTCP)
+	if err != nil {
+		t.Skip(err)
+	}
+	found := false
+	for _, np := range nss {
+		if np.LocalAddressParsedIPPort == int64(port) && np.StParsedStatus == "LISTEN" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("listener on port %d not found", port)
+	}
+	fmt.Println("netlink tcp sockets:", len(nss), "port", strconv.Itoa(port))
+}
+
+func TestParseInetDiagAttrs(t *testing.T) {
+	info := make([]byte, 136)
+	info[0] = 0x01
+	nativeEndian.PutUint32(info[68:], 1500)
+	nativeEndian.PutUint32(info[80:], 10)
+	nativeEndian.PutUint32(info[100:], 3)
+	nativeEndian.PutUint64(info[104:], 1<<20)
+
+	buf := new(bytes.Buffer)
+	// unrelated attribute with padding
+	binary.Write(buf, nativeEndian, syscall.RtAttr{Len: 5, Type: 1})
+	buf.Write([]byte{0, 0, 0, 0})
+	binary.Write(buf, nativeEndian, syscall.RtAttr{Len: uint16(syscall.SizeofRtAttr + len(info)), Type: inetDiagInfo})
+	buf.Write(info)
+
+	ti, err := parseInetDiagAttrs(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ti == nil {
+		t.Fatal("expected tcp_info")
+	}
+	if ti.State != 1 || ti.RTT != 1500 || ti.SndCwnd != 10 || ti.TotalRetrans != 3 || ti.PacingRate != 1<<20 {
+		t.Fatalf("unexpected %+v", ti)
+	}
+	// shorter struct from older kernels
+	if ti = parseTCPInfo(info[:104]); ti.PacingRate != 0 || ti.RTT != 1500 {
+		t.Fatalf("unexpected %+v", ti)
+	}
+}
+
+func TestGetNetTCPInfoByNetlink(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	conn, err := net.Dial("tcp4", ln.Addr().String())
+	if err != nil {
+		t.Skip(err)
+	}
+	defer conn.Close()
+	port := int64(conn.LocalAddr().(*net.TCPAddr).Port)
+
+	nis, err := GetNetTCPInfoByNetlink(TypeTCP)
+	if err != nil {
+		t.Skip(err)
+	}
+	for _, ni := range nis {
+		if ni.LocalAddressParsedIPPort != port {
+			continue
+		}
+		if ni.Info == nil || ni.Info.SndCwnd == 0 {
+			t.Fatalf("unexpected info %+v", ni.Info)
+		}
+		fmt.Printf("tcp_info %+v\n", *ni.Info)
+		return
+	}
+	t.Fatalf("connection from port %d not found", port)
+}
@@ -0,0 +1,120 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/gyuho/linux-inspect/pkg/fileutil"
+)
+
+// Sockstat is '/proc/net/sockstat'.
+type Sockstat struct {
+	// SocketsUsed is the number of sockets in use.
+	SocketsUsed int64
+
+	TCPInUse  int64
+	TCPOrphan int64
+	// TCPTimeWait is the number of TIME_WAIT sockets (tw buckets).
+	TCPTimeWait int64
+	TCPAlloc    int64
+	// TCPMemPages is the TCP memory in pages, to compare
+	// with 'net.ipv4.tcp_mem' for memory pressure.
+	TCPMemPages int64
+
+	UDPInUse int64
+	// UDPMemPages is the UDP memory in pages.
+	UDPMemPages  int64
+	UDPLiteInUse int64
+	RawInUse     int64
+	FragInUse    int64
+	// FragMemory is the IP fragment reassembly memory in bytes.
+	FragMemory int64
+
+	// IPv6 counts from '/proc/net/sockstat6', zero if IPv6 is disabled.
+	// The memory is accounted in the IPv4 counters above.
+	TCP6InUse     int64
+	UDP6InUse     int64
+	UDPLite6InUse int64
+	Raw6InUse     int64
+	Frag6InUse    int64
+	Frag6Memory   int64
+}
+
+// GetSockstat reads '/proc/net/sockstat' and '/proc/net/sockstat6',
+// if it exists.
+func GetSockstat() (Sockstat, error) {
+	d, err := readSockstat("/proc/net/sockstat")
+	if err != nil {
+		return Sockstat{}, err
+	}
+	if fileutil.Exist("/proc/net/sockstat6") {
+		d6, err := readSockstat("/proc/net/sockstat6")
+		if err != nil {
+			return Sockstat{}, err
+		}
+		d = append(d, d6...)
+	}
+	return parseSockstat(d)
+}
+
+func readSockstat(fpath string) ([]byte, error) {
+	f, err := fileutil.OpenToRead(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+// parseSockstat parses lines like 'TCP: inuse 4 orphan 0 tw 0 alloc 4 mem 0',
+// of both '/proc/net/sockstat' and '/proc/net/sockstat6'.
+func parseSockstat(d []byte) (Sockstat, error) {
+	var s Sockstat
+	fields := map[string]map[string]*int64{
+		"sockets": {"used": &s.SocketsUsed},
+		"TCP": {
+			"inuse":  &s.TCPInUse,
+			"orphan": &s.TCPOrphan,
+			"tw":     &s.TCPTimeWait,
+			"alloc":  &s.TCPAlloc,
+			"mem":    &s.TCPMemPages,
+		},
+		"UDP":      {"inuse": &s.UDPInUse, "mem": &s.UDPMemPages},
+		"UDPLITE":  {"inuse": &s.UDPLiteInUse},
+		"RAW":      {"inuse": &s.RawInUse},
+		"FRAG":     {"inuse": &s.FragInUse, "memory": &s.FragMemory},
+		"TCP6":     {"inuse": &s.TCP6InUse},
+		"UDP6":     {"inuse": &s.UDP6InUse},
+		"UDPLITE6": {"inuse": &s.UDPLite6InUse},
+		"RAW6":     {"inuse": &s.Raw6InUse},
+		"FRAG6":    {"inuse": &s.Frag6InUse, "memory": &s.Frag6Memory},
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(d))
+	for scanner.Scan() {
+		fs := strings.Fields(scanner.Text())
+		if len(fs) == 0 {
+			continue
+		}
+		kvs, ok := fields[strings.TrimSuffix(fs[0], ":")]
+		if !ok {
+			continue
+		}
+		for i := 1; i+1 < len(fs); i += 2 {
+			p, ok := kvs[fs[i]]
+			if !ok {
+				continue
+			}
+			v, err := strconv.ParseInt(fs[i+1], 10, 64)
+			if err != nil {
+				return Sockstat{}, fmt.Errorf("%v when parsing %s %s", err, fs[0], fs[i])
+			}
+			*p = v
+		}
+	}
+	return s, scanner.Err()
+}
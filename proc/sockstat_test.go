@@ -0,0 +1,52 @@
+package proc
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseSockstat(t *testing.T) {
+	s, err := parseSockstat([]byte(`sockets: used 18
+TCP: inuse 4 orphan 1 tw 120 alloc 5 mem 3
+UDP: inuse 2 mem 1
+UDPLITE: inuse 0
+RAW: inuse 1
+FRAG: inuse 0 memory 0
+TCP6: inuse 3
+UDP6: inuse 1
+UDPLITE6: inuse 0
+RAW6: inuse 0
+FRAG6: inuse 1 memory 256
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Sockstat{
+		SocketsUsed: 18, TCPInUse: 4, TCPOrphan: 1, TCPTimeWait: 120, TCPAlloc: 5, TCPMemPages: 3,
+		UDPInUse: 2, UDPMemPages: 1, RawInUse: 1,
+		TCP6InUse: 3, UDP6InUse: 1, Frag6InUse: 1, Frag6Memory: 256,
+	}
+	if s != expected {
+		t.Fatalf("expected %+v, got %+v", expected, s)
+	}
+}
+
+func TestGetSockstat(t *testing.T) {
+	s, err := GetSockstat()
+	if err != nil {
+		t.Skip(err)
+	}
+	fmt.Printf("%+v\n", s)
+}
+
+func TestGetSysctl(t *testing.T) {
+	v, err := GetSysctlInt("net.ipv4.tcp_fin_timeout")
+	if err != nil {
+		t.Skip(err)
+	}
+	fmt.Println("net.ipv4.tcp_fin_timeout:", v)
+
+	if _, err = GetSysctl("net.ipv4.linux_inspect_does_not_exist"); err == nil {
+		t.Fatal("expected error")
+	}
+}
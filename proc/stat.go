@@ -3,6 +3,7 @@ package proc
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"html/template"
 	"io/ioutil"
@@ -10,6 +11,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/gyuho/linux-inspect/pkg/fileutil"
 	"github.com/gyuho/linux-inspect/schema"
@@ -17,6 +19,11 @@ import (
 	"github.com/dustin/go-humanize"
 )
 
+// UserHZ is USER_HZ, the clock ticks per second of the times in '/proc'
+// (e.g. 'utime', 'stime' and 'starttime' in '/proc/$PID/stat').
+// It is 100 on all supported architectures.
+const UserHZ = 100
+
 // GetStatByPID reads '/proc/$PID/stat' data.
 func GetStatByPID(pid int64) (s Stat, err error) {
 	var d []byte
@@ -27,6 +34,66 @@ func GetStatByPID(pid int64) (s Stat, err error) {
 	return parseStat(d)
 }
 
+// maxConcurrentStatReads limits the number of '/proc/$PID/stat'
+// files being read concurrently in 'GetStats'.
+const maxConcurrentStatReads = 32
+
+// GetStats reads '/proc/$PID/stat' of the given PIDs concurrently.
+// If no PID is given, it reads all PIDs in '/proc'.
+// PIDs that exit during the scan are skipped.
+func GetStats(pids ...int64) (map[int64]Stat, error) {
+	return GetStatsContext(context.Background(), pids...)
+}
+
+// GetStatsContext is 'GetStats' with a context. Once the context is
+// done, no more files are read, and it returns the context error.
+func GetStatsContext(ctx context.Context, pids ...int64) (map[int64]Stat, error) {
+	if len(pids) == 0 {
+		var err error
+		pids, err = ListPIDsContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var mu sync.Mutex
+	sm := make(map[int64]Stat, len(pids))
+
+	var wg sync.WaitGroup
+	wg.Add(len(pids))
+	limitc := make(chan struct{}, maxConcurrentStatReads)
+	for _, pid := range pids {
+		go func(pid int64) {
+			defer wg.Done()
+			select {
+			case limitc <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-limitc }()
+			if ctx.Err() != nil {
+				return
+			}
+
+			st, err := GetStatByPID(pid)
+			if err != nil {
+				// process exited during the scan
+				return
+			}
+
+			mu.Lock()
+			sm[pid] = st
+			mu.Unlock()
+		}(pid)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return sm, nil
+}
+
 func readStat(pid int64) ([]byte, error) {
 	fpath := fmt.Sprintf("/proc/%d/stat", pid)
 	f, err := fileutil.OpenToRead(fpath)
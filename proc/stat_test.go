@@ -1,6 +1,7 @@
 package proc
 
 import (
+	"context"
 	"fmt"
 	"testing"
 )
@@ -15,3 +16,30 @@ func TestGetStatByPID(t *testing.T) {
 	}
 	fmt.Printf("GetStatByPID: %+v\n", s)
 }
+
+func TestGetStats(t *testing.T) {
+	sm, err := GetStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sm) == 0 {
+		t.Fatal("expected at least one '/proc/$PID/stat'")
+	}
+	for pid, s := range sm {
+		if pid != s.Pid {
+			t.Fatalf("PID expected %d, got %d", pid, s.Pid)
+		}
+	}
+	fmt.Println("GetStats:", len(sm), "processes")
+}
+
+func TestGetStatsContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := GetStatsContext(ctx); err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+	if _, err := ListPIDsContext(ctx); err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -45,6 +46,15 @@ func GetStatusByPID(pid int64) (s Status, err error) {
 	u, _ = humanize.ParseBytes(s.VmRSS)
 	s.VmRSSBytesN = u
 	s.VmRSSParsedBytes = humanize.Bytes(u)
+	u, _ = humanize.ParseBytes(s.RssAnon)
+	s.RssAnonBytesN = u
+	s.RssAnonParsedBytes = humanize.Bytes(u)
+	u, _ = humanize.ParseBytes(s.RssFile)
+	s.RssFileBytesN = u
+	s.RssFileParsedBytes = humanize.Bytes(u)
+	u, _ = humanize.ParseBytes(s.RssShmem)
+	s.RssShmemBytesN = u
+	s.RssShmemParsedBytes = humanize.Bytes(u)
 	u, _ = humanize.ParseBytes(s.VmData)
 	s.VmDataBytesN = u
 	s.VmDataParsedBytes = humanize.Bytes(u)
@@ -101,6 +111,8 @@ Ngid:      {{.Ngid}}
 Pid:       {{.Pid}}
 PPid:      {{.PPid}}
 TracerPid: {{.TracerPid}}
+NSpid:     {{.NSpid}}
+Kthread:   {{.Kthread}}
 
 FDSize:  {{.FDSize}}
 
@@ -110,6 +122,9 @@ VmLck:   {{.VmLckParsedBytes}}
 VmPin:   {{.VmPinParsedBytes}}
 VmHWM:   {{.VmHWMParsedBytes}}
 VmRSS:   {{.VmRSSParsedBytes}}
+RssAnon:  {{.RssAnonParsedBytes}}
+RssFile:  {{.RssFileParsedBytes}}
+RssShmem: {{.RssShmemParsedBytes}}
 VmData:  {{.VmDataParsedBytes}}
 VmStk:   {{.VmStkParsedBytes}}
 VmExe:   {{.VmExeParsedBytes}}
@@ -135,8 +150,13 @@ CapInh: {{.CapInh}}
 CapPrm: {{.CapPrm}}
 CapEff: {{.CapEff}}
 CapBnd: {{.CapBnd}}
+CapAmb: {{.CapAmb}}
 
+NoNewPrivs: {{.NoNewPrivs}}
 Seccomp: {{.Seccomp}}
+Seccomp_filters: {{.SeccompFilters}}
+Speculation_Store_Bypass:  {{.SpeculationStoreBypass}}
+SpeculationIndirectBranch: {{.SpeculationIndirectBranch}}
 
 Cpus_allowed:      {{.CpusAllowed}}
 Cpus_allowed_list: {{.CpusAllowedList}}
@@ -161,6 +181,82 @@ func (s Status) String() string {
 	return buf.String()
 }
 
+// UIDs returns the real, effective, saved set, and filesystem UIDs.
+func (s Status) UIDs() ([]uint64, error) {
+	return parseUintFields(s.Uid)
+}
+
+// GIDs returns the real, effective, saved set, and filesystem GIDs.
+func (s Status) GIDs() ([]uint64, error) {
+	return parseUintFields(s.Gid)
+}
+
+// SupplementaryGIDs returns the supplementary group list.
+func (s Status) SupplementaryGIDs() ([]uint64, error) {
+	return parseUintFields(s.Groups)
+}
+
+// NSpids returns the PIDs in each of the PID namespaces,
+// from the outermost one. The last is the PID in the
+// namespace of the process (e.g. 1 in a container).
+func (s Status) NSpids() ([]uint64, error) {
+	return parseUintFields(s.NSpid)
+}
+
+// AllowedCPUs returns the CPUs on which the process may run,
+// parsed from 'Cpus_allowed_list' (e.g. "0-3,8").
+func (s Status) AllowedCPUs() ([]int, error) {
+	return parseIntList(s.CpusAllowedList)
+}
+
+// AllowedMemNodes returns the memory nodes allowed to the process,
+// parsed from 'Mems_allowed_list'.
+func (s Status) AllowedMemNodes() ([]int, error) {
+	return parseIntList(s.MemsAllowedList)
+}
+
+// parseUintFields parses the space-separated numbers (e.g. "0 0 0 0").
+func parseUintFields(s string) ([]uint64, error) {
+	fs := strings.Fields(s)
+	vs := make([]uint64, len(fs))
+	for i, f := range fs {
+		v, err := strconv.ParseUint(f, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse %q (%v)", s, err)
+		}
+		vs[i] = v
+	}
+	return vs, nil
+}
+
+// parseIntList parses the list format (e.g. "0-3,8") in
+// '/proc' and '/sys', used for CPUs and memory nodes.
+func parseIntList(s string) ([]int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	var vs []int
+	for _, r := range strings.Split(s, ",") {
+		lo, hi := r, r
+		if i := strings.IndexByte(r, '-'); i >= 0 {
+			lo, hi = r[:i], r[i+1:]
+		}
+		l, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse list %q (%v)", s, err)
+		}
+		h, err := strconv.Atoi(hi)
+		if err != nil || h < l {
+			return nil, fmt.Errorf("cannot parse list %q (range %q)", s, r)
+		}
+		for v := l; v <= h; v++ {
+			vs = append(vs, v)
+		}
+	}
+	return vs, nil
+}
+
 // GetProgram returns the program name.
 func GetProgram(pid int64) (string, error) {
 	// Readlink needs root permission
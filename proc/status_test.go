@@ -2,6 +2,8 @@ package proc
 
 import (
 	"fmt"
+	"os"
+	"reflect"
 	"testing"
 )
 
@@ -37,3 +39,117 @@ func TestGetProgram(t *testing.T) {
 	}
 	fmt.Println("GetProgram:", nm)
 }
+
+const testStatus = `Name:	bash
+Umask:	0022
+State:	S (sleeping)
+Tgid:	1234
+Ngid:	0
+Pid:	1234
+PPid:	1200
+TracerPid:	0
+Uid:	1000	1000	1000	1000
+Gid:	1000	1000	1000	1000
+FDSize:	256
+Groups:	4 24 27 1000 
+NStgid:	1234	7
+NSpid:	1234	7
+NSpgid:	1234	7
+NSsid:	1200	1
+Kthread:	0
+VmPeak:	   10000 kB
+VmSize:	    9000 kB
+VmLck:	       0 kB
+VmPin:	       0 kB
+VmHWM:	    5000 kB
+VmRSS:	    4000 kB
+RssAnon:	    1000 kB
+RssFile:	    2900 kB
+RssShmem:	     100 kB
+VmData:	    1500 kB
+VmStk:	     132 kB
+VmExe:	     900 kB
+VmLib:	    2000 kB
+VmPTE:	      60 kB
+VmSwap:	      12 kB
+HugetlbPages:	       0 kB
+CoreDumping:	0
+THP_enabled:	1
+untag_mask:	0xffffffffffffffff
+Threads:	1
+SigQ:	0/31574
+SigPnd:	0000000000000000
+ShdPnd:	0000000000000000
+SigBlk:	0000000000010000
+SigIgn:	0000000000380004
+SigCgt:	000000004b817efb
+CapInh:	0000000000000000
+CapPrm:	0000000000000000
+CapEff:	0000000000000000
+CapBnd:	000001ffffffffff
+CapAmb:	0000000000000000
+NoNewPrivs:	1
+Seccomp:	2
+Seccomp_filters:	1
+Speculation_Store_Bypass:	thread vulnerable
+SpeculationIndirectBranch:	conditional enabled
+Cpus_allowed:	ff
+Cpus_allowed_list:	0-3,6
+Mems_allowed:	00000000,00000001
+Mems_allowed_list:	0
+voluntary_ctxt_switches:	150
+nonvoluntary_ctxt_switches:	3
+`
+
+func TestParseStatus(t *testing.T) {
+	s, err := parseStatus([]byte(testStatus))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Kthread != 0 || s.THPEnabled != 1 || s.NoNewPrivs != 1 || s.Seccomp != 2 || s.SeccompFilters != 1 {
+		t.Fatalf("unexpected status %+v", s)
+	}
+	if s.UntagMask != "0xffffffffffffffff" || s.SpeculationStoreBypass != "thread vulnerable" || s.SpeculationIndirectBranch != "conditional enabled" {
+		t.Fatalf("unexpected status %+v", s)
+	}
+	if s.RssAnon != "1000 kB" || s.VoluntaryCtxtSwitches != 150 || s.NonvoluntaryCtxtSwitches != 3 {
+		t.Fatalf("unexpected status %+v", s)
+	}
+
+	uids, err := s.UIDs()
+	if err != nil || !reflect.DeepEqual(uids, []uint64{1000, 1000, 1000, 1000}) {
+		t.Fatalf("unexpected UIDs %v (%v)", uids, err)
+	}
+	groups, err := s.SupplementaryGIDs()
+	if err != nil || !reflect.DeepEqual(groups, []uint64{4, 24, 27, 1000}) {
+		t.Fatalf("unexpected groups %v (%v)", groups, err)
+	}
+	nspids, err := s.NSpids()
+	if err != nil || !reflect.DeepEqual(nspids, []uint64{1234, 7}) {
+		t.Fatalf("unexpected NSpids %v (%v)", nspids, err)
+	}
+	cpus, err := s.AllowedCPUs()
+	if err != nil || !reflect.DeepEqual(cpus, []int{0, 1, 2, 3, 6}) {
+		t.Fatalf("unexpected CPUs %v (%v)", cpus, err)
+	}
+	nodes, err := s.AllowedMemNodes()
+	if err != nil || !reflect.DeepEqual(nodes, []int{0}) {
+		t.Fatalf("unexpected memory nodes %v (%v)", nodes, err)
+	}
+	if _, err = parseIntList("3-1"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestGetStatusByPIDSelf(t *testing.T) {
+	s, err := GetStatusByPID(int64(os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.RssAnon != "" && (s.RssAnonBytesN == 0 || s.RssAnonBytesN > s.VmRSSBytesN) {
+		t.Fatalf("unexpected RssAnon %q (%d bytes, VmRSS %d bytes)", s.RssAnon, s.RssAnonBytesN, s.VmRSSBytesN)
+	}
+	if cpus, err := s.AllowedCPUs(); err != nil || len(cpus) == 0 {
+		t.Fatalf("unexpected CPUs %v (%v)", cpus, err)
+	}
+}
@@ -0,0 +1,41 @@
+package proc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gyuho/linux-inspect/pkg/fileutil"
+)
+
+// GetSysctl reads the kernel parameter in '/proc/sys'.
+// The name is dot-separated as in 'sysctl' (e.g. 'net.ipv4.tcp_fin_timeout').
+func GetSysctl(name string) (string, error) {
+	fpath := filepath.Join("/proc/sys", strings.Replace(name, ".", "/", -1))
+	f, err := fileutil.OpenToRead(fpath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	d, err := ioutil.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(d)), nil
+}
+
+// GetSysctlInt reads the integer kernel parameter in '/proc/sys'.
+func GetSysctlInt(name string) (int64, error) {
+	s, err := GetSysctl(name)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%v when parsing %s %q", err, name, s)
+	}
+	return v, nil
+}
@@ -0,0 +1,63 @@
+package proc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"syscall"
+
+	"github.com/gyuho/linux-inspect/pkg/fileutil"
+)
+
+// GetThreadStatByPID reads '/proc/$PID/task/$TID/stat' data.
+func GetThreadStatByPID(pid, tid int64) (Stat, error) {
+	fpath := fmt.Sprintf("/proc/%d/task/%d/stat", pid, tid)
+	f, err := fileutil.OpenToRead(fpath)
+	if err != nil {
+		return Stat{}, err
+	}
+	defer f.Close()
+
+	d, err := ioutil.ReadAll(f)
+	if err != nil {
+		return Stat{}, err
+	}
+	return parseStat(d)
+}
+
+// GetThreadStatesByPID returns the number of threads per state
+// (e.g. "R", "S", "D") of the process. Many threads in "D"
+// (uninterruptible disk sleep) indicate IO contention.
+// Threads that exit during the scan are skipped, and any other read
+// error is returned.
+func GetThreadStatesByPID(pid int64) (map[string]int, error) {
+	tids, err := ListThreads(pid)
+	if err != nil {
+		return nil, err
+	}
+	states := make(map[string]int)
+	for _, tid := range tids {
+		st, err := GetThreadStatByPID(pid, tid)
+		if err != nil {
+			if isThreadExited(err) {
+				continue
+			}
+			return nil, err
+		}
+		states[st.State]++
+	}
+	return states, nil
+}
+
+// isThreadExited returns true if the error is from reading a thread
+// that exited: its '/proc' entry is gone (ENOENT) or it is being
+// reaped (ESRCH).
+func isThreadExited(err error) bool {
+	if os.IsNotExist(err) {
+		return true
+	}
+	if pe, ok := err.(*os.PathError); ok {
+		err = pe.Err
+	}
+	return err == syscall.ESRCH
+}
@@ -0,0 +1,47 @@
+package proc
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestGetThreadStatesByPID(t *testing.T) {
+	pid := int64(os.Getpid())
+	tids, err := ListThreads(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	states, err := GetThreadStatesByPID(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, n := range states {
+		total += n
+	}
+	if total == 0 || total > len(tids)+8 {
+		t.Fatalf("unexpected thread count %d (listed %d)", total, len(tids))
+	}
+	fmt.Println("GetThreadStatesByPID:", states)
+}
+
+func TestIsThreadExited(t *testing.T) {
+	tests := []struct {
+		err error
+		exp bool
+	}{
+		{&os.PathError{Op: "open", Path: "/proc/1/task/2/stat", Err: syscall.ENOENT}, true},
+		{&os.PathError{Op: "read", Path: "/proc/1/task/2/stat", Err: syscall.ESRCH}, true},
+		{&os.PathError{Op: "open", Path: "/proc/1/task/2/stat", Err: syscall.EACCES}, false},
+		{&os.PathError{Op: "read", Path: "/proc/1/task/2/stat", Err: syscall.EIO}, false},
+		{errors.New("parse error"), false},
+	}
+	for i, tt := range tests {
+		if got := isThreadExited(tt.err); got != tt.exp {
+			t.Fatalf("#%d: %v expected %v, got %v", i, tt.err, tt.exp, got)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package top
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Flavor is the implementation of 'top'.
+type Flavor string
+
+const (
+	// FlavorAuto detects the flavor from the 'top' command path,
+	// which is a symlink to 'busybox' in busybox-based images
+	// (e.g. Alpine).
+	FlavorAuto Flavor = ""
+	// FlavorProcps is 'top' from procps (procps-ng).
+	FlavorProcps Flavor = "procps"
+	// FlavorBusybox is 'top' from busybox, which prints
+	// "PID PPID USER STAT VSZ %VSZ CPU %CPU COMMAND".
+	// It has no "TIME+" and no thread mode, and 'Config.PIDs'
+	// and 'Config.SortBy' are applied to its rows by the stream.
+	FlavorBusybox Flavor = "busybox"
+)
+
+// flavor returns 'Flavor', detected from 'Exec' if 'FlavorAuto'.
+func (cfg *Config) flavor() Flavor {
+	if cfg.Flavor != FlavorAuto {
+		return cfg.Flavor
+	}
+	if p, err := filepath.EvalSymlinks(cfg.Exec); err == nil && filepath.Base(p) == "busybox" {
+		return FlavorBusybox
+	}
+	return FlavorProcps
+}
+
+// busyboxFlags returns the flags of busybox 'top',
+// whose delay is in whole seconds.
+func (cfg *Config) busyboxFlags() (fs []string) {
+	fs = append(fs, "-b")
+	if iterations := cfg.iterations(); iterations > 0 {
+		fs = append(fs, "-n", fmt.Sprintf("%d", iterations))
+	}
+	if cfg.Delay > 0 || cfg.IntervalSecond > 0 {
+		fs = append(fs, "-d", fmt.Sprintf("%d", int64(math.Ceil(cfg.delay().Seconds()))))
+	}
+	return
+}
+
+// pidFilter returns the PIDs to keep from the rows of busybox 'top',
+// which has no '-p' flag. It returns nil to keep all rows.
+func (cfg *Config) pidFilter() map[int64]struct{} {
+	pids := cfg.pids()
+	if len(pids) == 0 || cfg.flavor() != FlavorBusybox {
+		return nil
+	}
+	m := make(map[int64]struct{}, len(pids))
+	for _, pid := range pids {
+		m[pid] = struct{}{}
+	}
+	return m
+}
+
+// ParseBusybox parses busybox 'top' command output and returns
+// the rows. The columns are read from the header, since they
+// depend on how busybox was built.
+func ParseBusybox(s string) ([]Row, error) {
+	var (
+		lt   *layout
+		rows []Row
+	)
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || isBusyboxSummaryLine(line) {
+			continue
+		}
+		if strings.HasPrefix(line, "PID ") {
+			var err error
+			if lt, err = newLayout(strings.Fields(line)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if lt == nil {
+			return nil, fmt.Errorf("no header before row %q", line)
+		}
+
+		row, err := lt.split(line)
+		if err != nil {
+			return nil, err
+		}
+		r, err := lt.parse(row)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, r)
+	}
+	return rows, nil
+}
+
+// ParseBusyboxSummary parses the summary area of busybox 'top'
+// command output. If the output has multiple iterations, it returns
+// the last one. Busybox does not print the time, the uptime, the
+// users and the swap, and counts only the running and total tasks.
+func ParseBusyboxSummary(s string) Summary {
+	var sm Summary
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "Mem:") {
+			sm = Summary{}
+		}
+		parseBusyboxSummaryLine(&sm, line)
+	}
+	return sm
+}
+
+func isBusyboxSummaryLine(line string) bool {
+	var sm Summary
+	return parseBusyboxSummaryLine(&sm, line)
+}
+
+// parseBusyboxSummaryLine parses the summary line into 'sm',
+// and returns false if the line is not in the summary area.
+// Malformed values are left zero.
+func parseBusyboxSummaryLine(sm *Summary, line string) bool {
+	switch {
+	case strings.HasPrefix(line, "Mem:"):
+		// "1893660K used, 6274188K free, 1216K shrd, 52244K buff, 1134184K cached"
+		for k, v := range summaryPairs(line) {
+			n, _, _ := parseMemoryTxt(v)
+			switch k {
+			case "used":
+				sm.MemUsedBytesN = n
+			case "free":
+				sm.MemFreeBytesN = n
+			case "buff", "cached":
+				sm.MemBuffCacheBytesN += n
+			}
+		}
+		sm.MemTotalBytesN = sm.MemUsedBytesN + sm.MemFreeBytesN
+
+	case strings.HasPrefix(line, "CPU:"):
+		// "  0% usr   0% sys   0% nic 100% idle   0% io   0% irq   0% sirq"
+		fs := strings.Fields(strings.TrimPrefix(line, "CPU:"))
+		for i := 0; i+1 < len(fs); i += 2 {
+			f, _ := parseFloat(strings.TrimSuffix(fs[i], "%"))
+			switch fs[i+1] {
+			case "usr":
+				sm.CPUUser = f
+			case "sys":
+				sm.CPUSystem = f
+			case "nic":
+				sm.CPUNice = f
+			case "idle":
+				sm.CPUIdle = f
+			case "io":
+				sm.CPUIowait = f
+			case "irq":
+				sm.CPUHardIRQ = f
+			case "sirq":
+				sm.CPUSoftIRQ = f
+			}
+		}
+
+	case strings.HasPrefix(line, "Load average:"):
+		// "0.04 0.05 0.01 2/291 7" (running/total, last PID)
+		fs := strings.Fields(strings.TrimPrefix(line, "Load average:"))
+		lds := []*float64{&sm.LoadAvg1Minute, &sm.LoadAvg5Minute, &sm.LoadAvg15Minute}
+		for i := 0; i < len(fs) && i < len(lds); i++ {
+			*lds[i], _ = strconv.ParseFloat(fs[i], 64)
+		}
+		if len(fs) > 3 {
+			if idx := strings.Index(fs[3], "/"); idx > 0 {
+				sm.TasksRunning, _ = strconv.ParseInt(fs[3][:idx], 10, 64)
+				sm.TasksTotal, _ = strconv.ParseInt(fs[3][idx+1:], 10, 64)
+			}
+		}
+
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package top
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const busyboxOutput = `Mem: 1893660K used, 6274188K free, 1216K shrd, 52244K buff, 1134184K cached
+CPU:   1% usr   2% sys   0% nic  96% idle   0% io   0% irq   1% sirq
+Load average: 0.04 0.05 0.01 2/291 7
+  PID  PPID USER     STAT   VSZ %VSZ CPU %CPU COMMAND
+    1     0 root     S     1700   0%   3   0% /bin/sh
+    7     1 root     R     1.2g  15%   0  12% top -b -n 1
+`
+
+func TestParseBusybox(t *testing.T) {
+	rows, err := ParseBusybox(busyboxOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %+v", rows)
+	}
+	r := rows[1]
+	if r.PID != 7 || r.USER != "root" || r.S != "R" || r.VIRTBytesN != 1<<30 || r.CPUPercent != 12 || r.COMMAND != "top -b -n 1" {
+		t.Fatalf("unexpected row %+v", r)
+	}
+
+	// without SMP support, there is no CPU column
+	rows, err = ParseBusybox(`  PID  PPID USER     STAT   VSZ %VSZ %CPU COMMAND
+    1     0 root     S<    1700   0%   3% /bin/sh`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].CPUPercent != 3 || rows[0].SParsedStatus != "S (sleeping)" {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+
+	if _, err = ParseBusybox("    1     0 root     S     1700   0%   3   0% /bin/sh"); err == nil {
+		t.Fatal("expected error without header")
+	}
+}
+
+func TestParseBusyboxSummary(t *testing.T) {
+	sm := ParseBusyboxSummary(busyboxOutput)
+	exp := Summary{
+		LoadAvg1Minute:     0.04,
+		LoadAvg5Minute:     0.05,
+		LoadAvg15Minute:    0.01,
+		TasksTotal:         291,
+		TasksRunning:       2,
+		CPUUser:            1,
+		CPUSystem:          2,
+		CPUIdle:            96,
+		CPUSoftIRQ:         1,
+		MemTotalBytesN:     (1893660 + 6274188) * 1024,
+		MemFreeBytesN:      6274188 * 1024,
+		MemUsedBytesN:      1893660 * 1024,
+		MemBuffCacheBytesN: (52244 + 1134184) * 1024,
+	}
+	if sm != exp {
+		t.Fatalf("expected %+v, got %+v", exp, sm)
+	}
+}
+
+func TestBusyboxFlavor(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "busybox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bb := filepath.Join(dir, "busybox")
+	if err = ioutil.WriteFile(bb, nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+	top := filepath.Join(dir, "top")
+	if err = os.Symlink(bb, top); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{Exec: top, Delay: 1500 * time.Millisecond, Iterations: 1, PID: 1, SortBy: SortByMEM}
+	if f := cfg.flavor(); f != FlavorBusybox {
+		t.Fatalf("expected busybox, got %q", f)
+	}
+	if fs := fmt.Sprint(cfg.Flags()); fs != "[-b -n 1 -d 2]" {
+		t.Fatalf("unexpected flags %s", fs)
+	}
+	cfg.ThreadMode = true
+	if err = cfg.createCmd(); err == nil {
+		t.Fatal("expected error in thread mode")
+	}
+
+	cfg = &Config{Exec: DefaultExecPath}
+	if f := cfg.flavor(); f != FlavorProcps {
+		t.Fatalf("expected procps, got %q", f)
+	}
+}
+
+func TestBusyboxStream(t *testing.T) {
+	rd, wr, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		io.WriteString(wr, busyboxOutput)
+		wr.Close()
+	}()
+
+	str := newStream(context.Background(), &Config{Flavor: FlavorBusybox, PID: 7})
+	if err = str.enqueue(rd); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+	if !str.noError() {
+		t.Fatalf("unexpected error %v", str.err)
+	}
+	if len(str.queue) != 1 || str.queue[0].PID != 7 {
+		t.Fatalf("unexpected rows %+v", str.queue)
+	}
+	if sm := str.Summary(); sm.TasksTotal != 291 || sm.CPUIdle != 96 {
+		t.Fatalf("unexpected summary %+v", sm)
+	}
+	if st := str.Stats(); st.RowsParsed != 2 || st.RowsSkipped != 0 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+}
@@ -0,0 +1,85 @@
+package top
+
+import "sync"
+
+// callbackRowsToBuffer is the number of rows or summaries
+// buffered for a slow callback, dropping the oldest.
+const callbackRowsToBuffer = 4096
+
+// summarySubscriber receives summary updates from the stream.
+type summarySubscriber struct {
+	once sync.Once
+	ch   chan Summary
+}
+
+func (sub *summarySubscriber) close() {
+	sub.once.Do(func() { close(sub.ch) })
+}
+
+// OnUpdate calls fn with each new row, in order, until the stream
+// ends or the returned function unregisters it. fn runs in its own
+// goroutine, so it may call the Stream methods; if it falls behind
+// by 4096 rows, the oldest rows are dropped like 'Subscribe'.
+func (str *Stream) OnUpdate(fn func(Row)) (unregister func()) {
+	ch, unsubscribe := str.Subscribe(callbackRowsToBuffer)
+	donec := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-donec:
+				return
+			case r, ok := <-ch:
+				if !ok {
+					return
+				}
+				fn(r)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(donec)
+			unsubscribe()
+		})
+	}
+}
+
+// OnSummary calls fn with each new summary, like 'OnUpdate'.
+func (str *Stream) OnSummary(fn func(Summary)) (unregister func()) {
+	sub := &summarySubscriber{ch: make(chan Summary, callbackRowsToBuffer)}
+	str.submu.Lock()
+	if str.closed {
+		sub.close()
+	} else {
+		str.summarySubs[sub] = struct{}{}
+	}
+	str.submu.Unlock()
+
+	donec := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-donec:
+				return
+			case sm, ok := <-sub.ch:
+				if !ok {
+					return
+				}
+				fn(sm)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(donec)
+			str.submu.Lock()
+			delete(str.summarySubs, sub)
+			str.submu.Unlock()
+			sub.close()
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package top
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStreamCallbacks(t *testing.T) {
+	cfg := &Config{
+		Native: true,
+		Delay:  100 * time.Millisecond,
+		PID:    1,
+	}
+	str, err := cfg.StartStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	rows, summaries := 0, 0
+	unregisterRow := str.OnUpdate(func(row Row) {
+		// callbacks may call the stream
+		str.Latest()
+		mu.Lock()
+		rows++
+		mu.Unlock()
+	})
+	str.OnSummary(func(sm Summary) {
+		mu.Lock()
+		summaries++
+		mu.Unlock()
+	})
+
+	time.Sleep(500 * time.Millisecond)
+	unregisterRow()
+	unregisterRow()
+	time.Sleep(50 * time.Millisecond) // in-flight callback
+	mu.Lock()
+	n := rows
+	mu.Unlock()
+
+	time.Sleep(300 * time.Millisecond)
+	if err = str.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if n == 0 || summaries == 0 {
+		t.Fatalf("expected callbacks, got %d rows and %d summaries", n, summaries)
+	}
+	if rows != n {
+		t.Fatalf("expected no row after unregister, got %d (was %d)", rows, n)
+	}
+}
@@ -0,0 +1,95 @@
+package top
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gyuho/linux-inspect/proc"
+)
+
+// CPUUsage is the percentages of the CPU time spent in each mode
+// over an interval, like '%Cpu(s)' in 'top'.
+type CPUUsage struct {
+	User    float64
+	System  float64
+	Nice    float64
+	Idle    float64
+	Iowait  float64
+	HardIRQ float64
+	SoftIRQ float64
+	Steal   float64
+}
+
+// Busy returns the percentage of the time not idle.
+func (u CPUUsage) Busy() float64 {
+	return round1(100 - u.Idle - u.Iowait)
+}
+
+// cpuUsage returns the usage between the two '/proc/stat' readings,
+// or since boot if prev is zero or later than cur.
+func cpuUsage(cur, prev proc.CPUStat) CPUUsage {
+	if cur.Total() <= prev.Total() {
+		prev = proc.CPUStat{}
+	}
+	elapsed := float64(cur.Total() - prev.Total())
+	if elapsed == 0 {
+		return CPUUsage{}
+	}
+	pct := func(cur, prev uint64) float64 {
+		if cur < prev {
+			return 0
+		}
+		return round1(float64(cur-prev) * 100 / elapsed)
+	}
+	return CPUUsage{
+		User:    pct(cur.User, prev.User),
+		System:  pct(cur.System, prev.System),
+		Nice:    pct(cur.Nice, prev.Nice),
+		Idle:    pct(cur.Idle, prev.Idle),
+		Iowait:  pct(cur.Iowait, prev.Iowait),
+		HardIRQ: pct(cur.Irq, prev.Irq),
+		SoftIRQ: pct(cur.Softirq, prev.Softirq),
+		Steal:   pct(cur.Steal, prev.Steal),
+	}
+}
+
+// CoreUsage returns the usage of each CPU core, keyed by the core
+// number, over the last iteration of the stream. 'top' in batch
+// mode cannot toggle its per-core view, so the cores are sampled
+// from '/proc/stat' on each iteration instead.
+func (str *Stream) CoreUsage() map[int]CPUUsage {
+	str.rmu.RLock()
+	defer str.rmu.RUnlock()
+
+	cm := make(map[int]CPUUsage, len(str.cores))
+	for k, v := range str.cores {
+		cm[k] = v
+	}
+	return cm
+}
+
+// sampleCores updates the usage of each core since the last call.
+// Errors are ignored, keeping the previous usage.
+func (str *Stream) sampleCores() {
+	cs, err := proc.GetCPUStats()
+	if err != nil {
+		return
+	}
+
+	cores := make(map[int]CPUUsage, len(cs))
+	prevs := make(map[int]proc.CPUStat, len(cs))
+	str.rmu.Lock()
+	for _, c := range cs {
+		if !strings.HasPrefix(c.CPU, "cpu") || c.CPU == "cpu" {
+			continue
+		}
+		n, nerr := strconv.Atoi(strings.TrimPrefix(c.CPU, "cpu"))
+		if nerr != nil {
+			continue
+		}
+		cores[n] = cpuUsage(c, str.prevCores[n])
+		prevs[n] = c
+	}
+	str.cores, str.prevCores = cores, prevs
+	str.rmu.Unlock()
+}
@@ -0,0 +1,52 @@
+package top
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gyuho/linux-inspect/proc"
+)
+
+func TestCPUUsage(t *testing.T) {
+	prev := proc.CPUStat{User: 100, System: 50, Idle: 800, Iowait: 50}
+	cur := proc.CPUStat{User: 150, System: 60, Idle: 830, Iowait: 60}
+	u := cpuUsage(cur, prev)
+	exp := CPUUsage{User: 50, System: 10, Idle: 30, Iowait: 10}
+	if u != exp {
+		t.Fatalf("expected %+v, got %+v", exp, u)
+	}
+	if b := u.Busy(); b != 60 {
+		t.Fatalf("expected 60, got %v", b)
+	}
+
+	// since boot
+	if u = cpuUsage(prev, cur); u.Idle != 80 {
+		t.Fatalf("expected idle 80, got %+v", u)
+	}
+	if u = cpuUsage(proc.CPUStat{}, proc.CPUStat{}); u != (CPUUsage{}) {
+		t.Fatalf("expected zero, got %+v", u)
+	}
+}
+
+func TestStreamCoreUsage(t *testing.T) {
+	cfg := &Config{
+		Native:     true,
+		Delay:      100 * time.Millisecond,
+		Iterations: 2,
+		PID:        1,
+	}
+	str, err := cfg.StartStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = str.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	cm := str.CoreUsage()
+	if len(cm) == 0 {
+		t.Fatalf("unexpected cores %+v", cm)
+	}
+	if _, ok := cm[0]; !ok {
+		t.Fatalf("expected core 0, got %+v", cm)
+	}
+}
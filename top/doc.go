@@ -1,2 +1,3 @@
-// Package top wraps Linux 'top' command.
+// Package top wraps Linux 'top' command, or samples '/proc'
+// natively where 'top' is not available (see 'Sampler').
 package top
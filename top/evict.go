@@ -0,0 +1,30 @@
+package top
+
+// Compact drops the rows, the histories and the other state of the
+// PIDs not seen in the last two iterations (the same processes that
+// 'TopN' skips), and returns the number of PIDs dropped. Call it
+// periodically if 'Config.EvictAfter' is 0, so that exited processes
+// do not accumulate in 'Latest' on hosts with many short-lived ones.
+func (str *Stream) Compact() int {
+	str.rmu.Lock()
+	n := str.evict(1)
+	str.rmu.Unlock()
+	return n
+}
+
+// evict drops the PIDs missing from more than 'after' iterations,
+// and returns the number of PIDs dropped.
+// It must be called with rmu held.
+func (str *Stream) evict(after int64) int {
+	n := 0
+	for pid := range str.pid2Row {
+		if str.lastSeen[pid]+after >= str.iteration {
+			continue
+		}
+		delete(str.pid2Row, pid)
+		delete(str.lastSeen, pid)
+		delete(str.histories, pid)
+		n++
+	}
+	return n
+}
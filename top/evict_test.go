@@ -0,0 +1,45 @@
+package top
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStreamEvict(t *testing.T) {
+	str := newStream(context.Background(), &Config{EvictAfter: 2, HistorySize: 1})
+	see := func(pids ...int64) {
+		str.publishSummary(Summary{})
+		str.rmu.Lock()
+		for _, pid := range pids {
+			str.lastSeen[pid] = str.iteration
+			str.pid2Row[pid] = Row{PID: pid}
+			str.record(Row{PID: pid})
+		}
+		str.rmu.Unlock()
+	}
+	see(1, 2, 3)
+	see(1, 2)
+	see(1)
+	if rows := str.Latest(); len(rows) != 3 {
+		t.Fatalf("expected 3 rows in grace period, got %+v", rows)
+	}
+	see(1)
+	rows := str.Latest()
+	if _, ok := rows[3]; ok || len(rows) != 2 {
+		t.Fatalf("expected PID 3 evicted, got %+v", rows)
+	}
+	if h := str.History(3); h != nil {
+		t.Fatalf("expected no history of PID 3, got %+v", h)
+	}
+
+	// PID 2 was last seen 2 iterations ago
+	if n := str.Compact(); n != 1 {
+		t.Fatalf("expected 1 PID compacted, got %d", n)
+	}
+	if rows = str.Latest(); len(rows) != 1 || rows[1].PID != 1 {
+		t.Fatalf("expected only PID 1, got %+v", rows)
+	}
+	if n := str.Compact(); n != 0 {
+		t.Fatalf("expected nothing to compact, got %d", n)
+	}
+}
@@ -35,4 +35,8 @@ type Row struct {
 	TIME string `column:"time"`
 	// COMMAND is command.
 	COMMAND string `column:"command"`
+
+	// OwnerPID is the process ID (thread group ID) of the thread,
+	// only set in thread mode ('Config.ThreadMode') where PID is the thread ID.
+	OwnerPID int64 `column:"owner_pid"`
 }
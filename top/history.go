@@ -0,0 +1,67 @@
+package top
+
+import "time"
+
+// TimedRow is a row with the time it was parsed.
+type TimedRow struct {
+	Time time.Time
+	Row  Row
+}
+
+// history is a ring buffer of the last rows of a PID.
+type history struct {
+	rows []TimedRow
+	next int
+	full bool
+}
+
+func newHistory(size int) *history {
+	return &history{rows: make([]TimedRow, size)}
+}
+
+func (h *history) add(tr TimedRow) {
+	h.rows[h.next] = tr
+	h.next++
+	if h.next == len(h.rows) {
+		h.next = 0
+		h.full = true
+	}
+}
+
+// list returns the rows, oldest first.
+func (h *history) list() []TimedRow {
+	if !h.full {
+		return append([]TimedRow(nil), h.rows[:h.next]...)
+	}
+	rows := make([]TimedRow, 0, len(h.rows))
+	rows = append(rows, h.rows[h.next:]...)
+	return append(rows, h.rows[:h.next]...)
+}
+
+// History returns the last rows of the PID (the TID in thread
+// mode), oldest first, up to 'Config.HistorySize' rows.
+// It returns nil if the history is disabled or the PID was not seen.
+func (str *Stream) History(pid int64) []TimedRow {
+	str.rmu.RLock()
+	defer str.rmu.RUnlock()
+
+	h, ok := str.histories[pid]
+	if !ok {
+		return nil
+	}
+	return h.list()
+}
+
+// record adds the row to the history of its PID.
+// It must be called with rmu held.
+func (str *Stream) record(row Row) {
+	if str.historySize < 1 {
+		return
+	}
+	h, ok := str.histories[row.PID]
+	if !ok {
+		h = newHistory(str.historySize)
+		str.histories[row.PID] = h
+	}
+	h.add(TimedRow{Time: str.clock.Now(), Row: row})
+}
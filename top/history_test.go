@@ -0,0 +1,58 @@
+package top
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	h := newHistory(3)
+	if rows := h.list(); len(rows) != 0 {
+		t.Fatalf("expected no rows, got %+v", rows)
+	}
+	for i := int64(1); i <= 5; i++ {
+		h.add(TimedRow{Row: Row{PID: i}})
+		rows := h.list()
+		exp := i
+		if exp > 3 {
+			exp = 3
+		}
+		if int64(len(rows)) != exp {
+			t.Fatalf("#%d: expected %d rows, got %+v", i, exp, rows)
+		}
+		if rows[len(rows)-1].Row.PID != i || rows[0].Row.PID != i-exp+1 {
+			t.Fatalf("#%d: unexpected order %+v", i, rows)
+		}
+	}
+}
+
+func TestTopStreamHistory(t *testing.T) {
+	cfg := &Config{
+		Native:      true,
+		Delay:       50 * time.Millisecond,
+		Iterations:  5,
+		PID:         1,
+		HistorySize: 3,
+	}
+	str, err := cfg.StartStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = str.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	rows := str.History(1)
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %+v", rows)
+	}
+	for i := 1; i < len(rows); i++ {
+		if !rows[i-1].Time.Before(rows[i].Time) {
+			t.Fatalf("expected ascending time, got %+v", rows)
+		}
+	}
+	fmt.Printf("%+v\n", rows)
+	if rows = str.History(-1); rows != nil {
+		t.Fatalf("expected no history, got %+v", rows)
+	}
+}
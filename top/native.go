@@ -0,0 +1,311 @@
+package top
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gyuho/linux-inspect/pkg/timeutil"
+	"github.com/gyuho/linux-inspect/proc"
+
+	humanize "github.com/dustin/go-humanize"
+)
+
+// Sampler computes 'top' rows natively from '/proc', without the
+// 'top' binary. %CPU is the process time delta over the per-core
+// '/proc/stat' time delta since the previous 'Sample', so it can
+// exceed 100 on multiple cores, like 'top' in Irix mode. Processes
+// seen for the first time are averaged over their lifetime.
+type Sampler struct {
+	// PID limits the rows to the process, if greater than 0.
+	PID int64
+	// PIDs limits the rows to the processes, along with 'PID'.
+	PIDs []int64
+	// ProgramMatchFunc, if not nil, drops the rows
+	// whose command does not match.
+	ProgramMatchFunc func(string) bool
+	// SortBy sorts the rows, %CPU by default.
+	SortBy SortField
+	// ThreadMode samples threads instead of processes,
+	// like 'Config.ThreadMode'.
+	ThreadMode bool
+	// Clock is used for 'Summary.Time'.
+	Clock timeutil.Clock
+
+	pageSize  uint64
+	prevCPU   proc.CPUStat
+	prevTotal uint64 // per-core ticks
+	prevTicks map[int64]uint64
+	users     map[string]string
+	summary   Summary
+}
+
+// NewSampler returns a new Sampler.
+// If pid<1, it samples all processes.
+func NewSampler(pid int64, threadMode bool) *Sampler {
+	return &Sampler{
+		PID:        pid,
+		ThreadMode: threadMode,
+		Clock:      timeutil.RealClock,
+		pageSize:   uint64(os.Getpagesize()),
+		prevTicks:  make(map[int64]uint64),
+		users:      make(map[string]string),
+	}
+}
+
+// nativeSample is a process or thread read from '/proc'.
+type nativeSample struct {
+	ownerPID int64
+	stat     proc.Stat
+}
+
+// Sample reads '/proc' and returns the rows, sorted by 'SortBy'.
+// Processes that exit during the scan are skipped.
+func (s *Sampler) Sample() ([]Row, error) {
+	cs, err := proc.GetCPUStats()
+	if err != nil {
+		return nil, err
+	}
+	if len(cs) < 2 {
+		return nil, fmt.Errorf("no CPU in '/proc/stat' (%+v)", cs)
+	}
+	total := cs[0].Total() / uint64(len(cs)-1)
+
+	mi, err := proc.GetMeminfo()
+	if err != nil {
+		return nil, err
+	}
+
+	pids := mergePIDs(s.PID, s.PIDs)
+	filtered := len(pids) > 0
+	if !filtered {
+		pids, err = proc.ListPIDs()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	samples := make([]nativeSample, 0, len(pids))
+	for _, pid := range pids {
+		if !s.ThreadMode {
+			st, serr := proc.GetStatByPID(pid)
+			if serr != nil || (s.ProgramMatchFunc != nil && !s.ProgramMatchFunc(st.Comm)) {
+				continue
+			}
+			samples = append(samples, nativeSample{stat: st})
+			continue
+		}
+		tids, terr := proc.ListThreads(pid)
+		if terr != nil {
+			continue
+		}
+		for _, tid := range tids {
+			st, serr := proc.GetThreadStatByPID(pid, tid)
+			if serr != nil || (s.ProgramMatchFunc != nil && !s.ProgramMatchFunc(st.Comm)) {
+				continue
+			}
+			samples = append(samples, nativeSample{ownerPID: pid, stat: st})
+		}
+	}
+	if filtered && len(samples) == 0 && s.ProgramMatchFunc == nil {
+		return nil, fmt.Errorf("PIDs %v not found", pids)
+	}
+
+	s.summary = s.summarize(cs[0], mi, samples)
+
+	ticks := make(map[int64]uint64, len(samples))
+	rows := make([]Row, 0, len(samples))
+	for _, sm := range samples {
+		st := sm.stat
+		cur := st.Utime + st.Stime
+		ticks[st.Pid] = cur
+
+		var delta, elapsed uint64
+		if prev, ok := s.prevTicks[st.Pid]; ok && prev <= cur && s.prevTotal < total {
+			delta, elapsed = cur-prev, total-s.prevTotal
+		} else if st.Starttime < total {
+			delta, elapsed = cur, total-st.Starttime
+		}
+
+		row := s.convert(sm, mi.MemTotalBytesN)
+		if elapsed > 0 {
+			row.CPUPercent = round1(float64(delta) * 100 / float64(elapsed))
+		}
+		rows = append(rows, row)
+	}
+	s.prevCPU, s.prevTotal, s.prevTicks = cs[0], total, ticks
+
+	SortRows(rows, s.SortBy)
+	return rows, nil
+}
+
+// Summary returns the summary of the last 'Sample'. The tasks are
+// counted from the sampled processes, and 'Summary.Users' is not set.
+func (s *Sampler) Summary() Summary {
+	return s.summary
+}
+
+// summarize computes the summary, with CPU percentages
+// over the time since the previous sample.
+func (s *Sampler) summarize(cpu proc.CPUStat, mi proc.Meminfo, samples []nativeSample) Summary {
+	sm := Summary{
+		Time: s.Clock.Now().Format("15:04:05"),
+
+		MemTotalBytesN:     mi.MemTotalBytesN,
+		MemFreeBytesN:      mi.MemFreeBytesN,
+		MemBuffCacheBytesN: mi.BuffersBytesN + mi.CachedBytesN + mi.SReclaimableBytesN,
+		MemAvailBytesN:     mi.MemAvailableBytesN,
+
+		SwapTotalBytesN: mi.SwapTotalBytesN,
+		SwapFreeBytesN:  mi.SwapFreeBytesN,
+	}
+	if used := sm.MemFreeBytesN + sm.MemBuffCacheBytesN; used < sm.MemTotalBytesN {
+		sm.MemUsedBytesN = sm.MemTotalBytesN - used
+	}
+	if sm.SwapFreeBytesN < sm.SwapTotalBytesN {
+		sm.SwapUsedBytesN = sm.SwapTotalBytesN - sm.SwapFreeBytesN
+	}
+	if up, err := proc.GetUptime(); err == nil {
+		sm.Uptime = up.UptimeTotalParsedTime
+	}
+	if la, err := proc.GetLoadAvg(); err == nil {
+		sm.LoadAvg1Minute, sm.LoadAvg5Minute, sm.LoadAvg15Minute = la.LoadAvg1Minute, la.LoadAvg5Minute, la.LoadAvg15Minute
+	}
+
+	sm.TasksTotal = int64(len(samples))
+	for _, sample := range samples {
+		switch sample.stat.State {
+		case "R":
+			sm.TasksRunning++
+		case "S", "D", "I":
+			sm.TasksSleeping++
+		case "T", "t":
+			sm.TasksStopped++
+		case "Z":
+			sm.TasksZombie++
+		}
+	}
+
+	u := cpuUsage(cpu, s.prevCPU)
+	sm.CPUUser, sm.CPUSystem, sm.CPUNice, sm.CPUIdle = u.User, u.System, u.Nice, u.Idle
+	sm.CPUIowait, sm.CPUHardIRQ, sm.CPUSoftIRQ, sm.CPUSteal = u.Iowait, u.HardIRQ, u.SoftIRQ, u.Steal
+	return sm
+}
+
+// convert converts the stat to a row, except for %CPU.
+func (s *Sampler) convert(sm nativeSample, memTotal uint64) Row {
+	st := sm.stat
+	row := Row{
+		PID:      st.Pid,
+		USER:     s.lookupUser(sm),
+		PR:       strconv.FormatInt(st.Priority, 10),
+		NI:       strconv.FormatInt(st.Nice, 10),
+		S:        st.State,
+		TIME:     formatTicks(st.Utime + st.Stime),
+		COMMAND:  st.Comm,
+		OwnerPID: sm.ownerPID,
+	}
+	if st.Priority <= -100 {
+		row.PR = "rt"
+	}
+	row.SParsedStatus = parseStatus(row.S)
+
+	row.VIRTBytesN = st.Vsize
+	row.VIRT, row.VIRTParsedBytes = formatKiB(row.VIRTBytesN)
+
+	if st.Rss > 0 {
+		row.RESBytesN = uint64(st.Rss) * s.pageSize
+	}
+	row.RES, row.RESParsedBytes = formatKiB(row.RESBytesN)
+
+	row.SHRBytesN = readSharedPages(st.Pid) * s.pageSize
+	row.SHR, row.SHRParsedBytes = formatKiB(row.SHRBytesN)
+
+	if memTotal > 0 {
+		row.MEMPercent = round1(float64(row.RESBytesN) * 100 / float64(memTotal))
+	}
+	return row
+}
+
+// lookupUser returns the name of the real user of the process,
+// or the UID if it has no name.
+func (s *Sampler) lookupUser(sm nativeSample) string {
+	pid := sm.stat.Pid
+	if sm.ownerPID > 0 {
+		pid = sm.ownerPID
+	}
+	st, err := proc.GetStatusByPID(pid)
+	if err != nil {
+		return ""
+	}
+	fs := strings.Fields(st.Uid)
+	if len(fs) == 0 {
+		return ""
+	}
+	uid := fs[0]
+	if name, ok := s.users[uid]; ok {
+		return name
+	}
+	name := uid
+	if u, uerr := user.LookupId(uid); uerr == nil {
+		name = u.Username
+	}
+	s.users[uid] = name
+	return name
+}
+
+// readSharedPages reads the number of resident shared pages
+// in '/proc/$PID/statm', 0 if the process has exited.
+func readSharedPages(pid int64) uint64 {
+	d, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/statm", pid))
+	if err != nil {
+		return 0
+	}
+	fs := strings.Fields(string(d))
+	if len(fs) < 3 {
+		return 0
+	}
+	v, err := strconv.ParseUint(fs[2], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// formatKiB returns the bytes in KiB, as 'top' prints them,
+// and humanized.
+func formatKiB(bts uint64) (string, string) {
+	return strconv.FormatUint(bts/1024, 10), humanize.Bytes(bts)
+}
+
+// formatTicks formats the clock ticks as 'top' TIME+ ("M:SS.hh").
+func formatTicks(ticks uint64) string {
+	hundredths := ticks * 100 / proc.UserHZ
+	return fmt.Sprintf("%d:%02d.%02d", hundredths/6000, hundredths/100%60, hundredths%100)
+}
+
+func round1(f float64) float64 {
+	return math.Round(f*10) / 10
+}
+
+// GetNative is like 'Get' but samples '/proc' natively twice,
+// 'delay' apart, instead of running 'top'.
+// If pid<1, it reads all processes.
+func GetNative(pid int64, delay time.Duration) ([]Row, error) {
+	cfg := &Config{
+		Native: true,
+		Delay:  delay,
+		PID:    pid,
+	}
+	return cfg.Snapshot()
+}
+
+// errSamplerDone is set when the native stream ends
+// without error (stopped or out of iterations).
+var errSamplerDone = errors.New("native sampler done")
@@ -0,0 +1,183 @@
+package top
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gyuho/linux-inspect/pkg/timeutil"
+)
+
+func TestSampler(t *testing.T) {
+	pid := int64(os.Getpid())
+	s := NewSampler(pid, false)
+	if _, err := s.Sample(); err != nil {
+		t.Fatal(err)
+	}
+
+	// burn some CPU
+	deadline := time.Now().Add(300 * time.Millisecond)
+	for time.Now().Before(deadline) {
+	}
+
+	rows, err := s.Sample()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].PID != pid {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+	row := rows[0]
+	fmt.Printf("%+v\n", row)
+	if row.CPUPercent <= 0 {
+		t.Fatalf("expected %%CPU > 0, got %+v", row)
+	}
+	if row.RESBytesN == 0 || row.VIRTBytesN < row.RESBytesN || row.MEMPercent <= 0 {
+		t.Fatalf("unexpected memory %+v", row)
+	}
+	if row.USER == "" || row.COMMAND == "" || row.SParsedStatus == "" {
+		t.Fatalf("unexpected row %+v", row)
+	}
+}
+
+func TestSamplerThreadMode(t *testing.T) {
+	pid := int64(os.Getpid())
+	rows, err := NewSampler(pid, true).Sample()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) < 2 {
+		t.Fatalf("expected multiple threads, got %+v", rows)
+	}
+	for _, row := range rows {
+		if row.OwnerPID != pid {
+			t.Fatalf("expected owner %d, got %+v", pid, row)
+		}
+	}
+}
+
+func TestFormatTicks(t *testing.T) {
+	tests := []struct {
+		ticks uint64
+		exp   string
+	}{
+		{0, "0:00.00"},
+		{150, "0:01.50"},
+		{6123, "1:01.23"},
+		{360000, "60:00.00"},
+	}
+	for i, tt := range tests {
+		if s := formatTicks(tt.ticks); s != tt.exp {
+			t.Fatalf("#%d: expected %q, got %q", i, tt.exp, s)
+		}
+	}
+}
+
+func TestTopStartNativeStream(t *testing.T) {
+	cfg := &Config{
+		Native:     true,
+		Delay:      100 * time.Millisecond,
+		Iterations: 3,
+	}
+	str, err := cfg.StartStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = str.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err = <-str.ErrChan():
+		t.Fatal(err)
+	default:
+	}
+	if len(str.Latest()) == 0 {
+		t.Fatal("expected rows")
+	}
+	sm := str.Summary()
+	if sm.TasksTotal == 0 || sm.MemTotalBytesN == 0 || sm.Uptime == "" {
+		t.Fatalf("unexpected summary %+v", sm)
+	}
+	if _, ok := <-str.SummaryChan(); !ok {
+		t.Fatal("expected the buffered summary")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cfg.Iterations = 0
+	str, err = cfg.StartStreamContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	select {
+	case err = <-str.ErrChan():
+		if err != context.Canceled {
+			t.Fatalf("expected %v, got %v", context.Canceled, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("native stream did not end on cancel")
+	}
+	if err = str.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTopNativeStreamFilter(t *testing.T) {
+	pid := int64(os.Getpid())
+	cfg := &Config{
+		Native:     true,
+		Delay:      100 * time.Millisecond,
+		Iterations: 2,
+		PIDs:       []int64{1, pid},
+		ProgramMatchFunc: func(command string) bool {
+			return strings.HasPrefix(command, "top.test")
+		},
+	}
+	str, err := cfg.StartStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = str.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	rm := str.Latest()
+	if _, ok := rm[pid]; len(rm) != 1 || !ok {
+		t.Fatalf("expected only %d, got %+v", pid, rm)
+	}
+}
+
+func TestTopNativeStreamClock(t *testing.T) {
+	pid := int64(os.Getpid())
+	start := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := timeutil.NewFakeClock(start)
+	cfg := &Config{
+		Native:      true,
+		Delay:       time.Hour,
+		Iterations:  2,
+		PID:         pid,
+		HistorySize: 2,
+		Clock:       clock,
+	}
+	str, err := cfg.StartStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for clock.Waiters() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	clock.Advance(time.Hour)
+	if err = str.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := str.History(pid)
+	if len(rows) != 2 || !rows[0].Time.Equal(start) || !rows[1].Time.Equal(start.Add(time.Hour)) {
+		t.Fatalf("unexpected history %+v", rows)
+	}
+	if sm := str.Summary(); sm.Time != "01:00:00" {
+		t.Fatalf("expected summary at 01:00:00, got %q", sm.Time)
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 // parses memory bytes in top command,
 // returns bytes in int64, and humanized bytes.
+// The value may have a decimal comma ("1,5g").
 //
 //  KiB = kibibyte = 1024 bytes
 //  MiB = mebibyte = 1024 KiB = 1,048,576 bytes
@@ -22,47 +23,41 @@ import (
 func parseMemoryTxt(s string) (bts uint64, hs string, err error) {
 	s = strings.TrimSpace(s)
 
-	switch {
-	case strings.HasSuffix(s, "m"): // suffix 'm' means megabytes
-		ns := s[:len(s)-1]
-		var mib float64
-		mib, err = strconv.ParseFloat(ns, 64)
-		if err != nil {
-			return 0, "", err
-		}
-		bts = uint64(mib) * 1024 * 1024
-
-	case strings.HasSuffix(s, "g"): // gigabytes
-		ns := s[:len(s)-1]
-		var gib float64
-		gib, err = strconv.ParseFloat(ns, 64)
-		if err != nil {
-			return 0, "", err
-		}
-		bts = uint64(gib) * 1024 * 1024 * 1024
-
-	case strings.HasSuffix(s, "t"): // terabytes
-		ns := s[:len(s)-1]
-		var tib float64
-		tib, err = strconv.ParseFloat(ns, 64)
-		if err != nil {
-			return 0, "", err
-		}
-		bts = uint64(tib) * 1024 * 1024 * 1024 * 1024
-
-	default:
-		var kib float64
-		kib, err = strconv.ParseFloat(s, 64)
-		if err != nil {
-			return 0, "", err
+	unit := uint64(1024) // kibibytes by default
+	if n := len(s); n > 0 {
+		if u, ok := memoryUnits[strings.ToLower(s[n-1:])]; ok {
+			s, unit = s[:n-1], u
 		}
-		bts = uint64(kib) * 1024
 	}
+	var f float64
+	f, err = parseFloat(s)
+	if err != nil {
+		return 0, "", err
+	}
+	bts = uint64(f) * unit
 
 	hs = humanize.Bytes(bts)
 	return
 }
 
+// memoryUnits is the bytes of the suffixes of memory columns,
+// which 'top' prints when the value does not fit its column
+// (e.g. "50.883g").
+var memoryUnits = map[string]uint64{
+	"k": 1 << 10,
+	"m": 1 << 20,
+	"g": 1 << 30,
+	"t": 1 << 40,
+	"p": 1 << 50,
+	"e": 1 << 60,
+}
+
+// parseFloat parses the number, which has a decimal
+// comma (e.g. "1,5") in some locales.
+func parseFloat(s string) (float64, error) {
+	return strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+}
+
 // Headers is the headers in 'top' output.
 var Headers = []string{
 	"PID",
@@ -79,28 +74,64 @@ var Headers = []string{
 	"COMMAND",
 }
 
-type commandOutputRowIdx int
-
-const (
-	command_output_row_idx_pid commandOutputRowIdx = iota
-	command_output_row_idx_user
-	command_output_row_idx_pr
-	command_output_row_idx_ni
-	command_output_row_idx_virt
-	command_output_row_idx_res
-	command_output_row_idx_shr
-	command_output_row_idx_s
-	command_output_row_idx_cpu
-	command_output_row_idx_mem
-	command_output_row_idx_time
-	command_output_row_idx_command
-)
+// layout maps the columns of 'top' rows to 'Headers'.
+type layout struct {
+	fields []string
+	// cols is the column of each header in 'Headers'
+	cols map[string]int
+}
+
+// defaultLayout is the layout of 'top' by default.
+var defaultLayout, _ = newLayout(Headers)
+
+// headerAliases maps the headers of older 'top'
+// and busybox 'top' to 'Headers'.
+var headerAliases = map[string]string{
+	"TIME": "TIME+",
+	"STAT": "S",
+	"VSZ":  "VIRT",
+}
+
+// newLayout returns the layout of the column headers, for 'top'
+// with a custom 'toprc'. Headers not in 'Headers' are ignored,
+// and "PID" is required.
+func newLayout(fields []string) (*layout, error) {
+	lt := &layout{fields: fields, cols: make(map[string]int, len(Headers))}
+	for i, h := range fields {
+		if alias, ok := headerAliases[h]; ok {
+			h = alias
+		}
+		if _, ok := lt.cols[h]; ok {
+			return nil, fmt.Errorf("duplicate field %q in %v", h, fields)
+		}
+		lt.cols[h] = i
+	}
+	if _, ok := lt.cols["PID"]; !ok {
+		return nil, fmt.Errorf("no PID field in %v", fields)
+	}
+	return lt, nil
+}
+
+// split splits the line into the columns. The command can have
+// spaces (e.g. 'top -c'), so that the extra fields are joined
+// into "COMMAND" if it is the last column.
+func (lt *layout) split(line string) ([]string, error) {
+	row := strings.Fields(line)
+	n := len(lt.fields)
+	if len(row) > n && lt.cols["COMMAND"] == n-1 {
+		row = append(row[:n-1], strings.Join(row[n-1:], " "))
+	}
+	if len(row) != n {
+		return nil, fmt.Errorf("unexpected row column number %v (expected %v)", row, lt.fields)
+	}
+	return row, nil
+}
 
 var bytesToSkip = [][]byte{
 	{116, 111, 112, 32, 45},                     // 'top -'
 	{84, 97, 115, 107, 115, 58, 32},             // 'Tasks: '
-	{37, 67, 112, 117, 40, 115, 41, 58, 32},     // '%Cpu(s): '
-	{67, 112, 117, 40, 115, 41, 58, 32},         // 'Cpu(s): '
+	{37, 67, 112, 117, 40, 115, 41, 58},         // '%Cpu(s):' ('%Cpu(s):100.0 us' when busy)
+	{67, 112, 117, 40, 115, 41, 58},             // 'Cpu(s):'
 	{77, 105, 66, 32, 77, 101, 109, 32, 58, 32}, // 'KiB Mem : '
 	{77, 105, 66, 32, 83, 119, 97, 112, 58, 32}, // 'KiB Swap: '
 	{77, 101, 109, 58, 32},                      // 'Mem: '
@@ -114,11 +145,25 @@ func topRowToSkip(data []byte) bool {
 			return true
 		}
 	}
-	return false
+	// other summary lines (e.g. 'Threads:', 'KiB Mem :')
+	var sm Summary
+	return parseSummaryLine(&sm, string(data))
 }
 
 // Parse parses 'top' command output and returns the rows.
 func Parse(s string) ([]Row, error) {
+	return ParseFields(s, Headers)
+}
+
+// ParseFields is like 'Parse' but for 'top' with a custom
+// layout in 'toprc', where 'fields' are the column headers
+// (see 'Config.Fields').
+func ParseFields(s string, fields []string) ([]Row, error) {
+	lt, err := newLayout(fields)
+	if err != nil {
+		return nil, err
+	}
+
 	lines := strings.Split(s, "\n")
 	rows := make([][]string, 0, len(lines))
 	for _, line := range lines {
@@ -130,10 +175,9 @@ func Parse(s string) ([]Row, error) {
 			continue
 		}
 
-		row := strings.Fields(strings.TrimSpace(line))
-		if len(row) != len(Headers) {
-			row = row[0:len(Headers)]
-			//return nil, fmt.Errorf("unexpected row column number %v (expected %v)", row, Headers)
+		row, err := lt.split(line)
+		if err != nil {
+			return nil, err
 		}
 		rows = append(rows, row)
 	}
@@ -145,7 +189,7 @@ func Parse(s string) ([]Row, error) {
 	rc := make(chan result, len(rows))
 	for _, row := range rows {
 		go func(row []string) {
-			tr, err := parseRow(row)
+			tr, err := lt.parse(row)
 			rc <- result{row: tr, err: err}
 		}(row)
 	}
@@ -163,60 +207,90 @@ func Parse(s string) ([]Row, error) {
 	return tcRows, nil
 }
 
-func parseRow(row []string) (Row, error) {
-	trow := Row{
-		USER: strings.TrimSpace(row[command_output_row_idx_user]),
+// parse parses the columns of a row. The columns
+// not in the layout are left empty.
+func (lt *layout) parse(row []string) (Row, error) {
+	col := func(h string) (string, bool) {
+		i, ok := lt.cols[h]
+		if !ok {
+			return "", false
+		}
+		return row[i], true
+	}
+
+	var trow Row
+	if v, ok := col("USER"); ok {
+		trow.USER = strings.TrimSpace(v)
 	}
 
-	pv, err := strconv.ParseInt(row[command_output_row_idx_pid], 10, 64)
+	v, _ := col("PID")
+	pv, err := strconv.ParseInt(v, 10, 64)
 	if err != nil {
 		return Row{}, fmt.Errorf("parse error %v (row %v)", err, row)
 	}
 	trow.PID = pv
 
-	trow.PR = strings.TrimSpace(row[command_output_row_idx_pr])
-	trow.NI = strings.TrimSpace(row[command_output_row_idx_ni])
+	if v, ok := col("PR"); ok {
+		trow.PR = strings.TrimSpace(v)
+	}
+	if v, ok := col("NI"); ok {
+		trow.NI = strings.TrimSpace(v)
+	}
 
-	virt, virtTxt, err := parseMemoryTxt(row[command_output_row_idx_virt])
-	if err != nil {
-		return Row{}, fmt.Errorf("parse error %v (row %v)", err, row)
+	if v, ok := col("VIRT"); ok {
+		virt, virtTxt, err := parseMemoryTxt(v)
+		if err != nil {
+			return Row{}, fmt.Errorf("parse error %v (row %v)", err, row)
+		}
+		trow.VIRT = v
+		trow.VIRTBytesN = virt
+		trow.VIRTParsedBytes = virtTxt
 	}
-	trow.VIRT = row[command_output_row_idx_virt]
-	trow.VIRTBytesN = virt
-	trow.VIRTParsedBytes = virtTxt
 
-	res, resTxt, err := parseMemoryTxt(row[command_output_row_idx_res])
-	if err != nil {
-		return Row{}, fmt.Errorf("parse error %v (row %v)", err, row)
+	if v, ok := col("RES"); ok {
+		res, resTxt, err := parseMemoryTxt(v)
+		if err != nil {
+			return Row{}, fmt.Errorf("parse error %v (row %v)", err, row)
+		}
+		trow.RES = v
+		trow.RESBytesN = res
+		trow.RESParsedBytes = resTxt
 	}
-	trow.RES = row[command_output_row_idx_res]
-	trow.RESBytesN = res
-	trow.RESParsedBytes = resTxt
 
-	shr, shrTxt, err := parseMemoryTxt(row[command_output_row_idx_shr])
-	if err != nil {
-		return Row{}, fmt.Errorf("parse error %v (row %v)", err, row)
+	if v, ok := col("SHR"); ok {
+		shr, shrTxt, err := parseMemoryTxt(v)
+		if err != nil {
+			return Row{}, fmt.Errorf("parse error %v (row %v)", err, row)
+		}
+		trow.SHR = v
+		trow.SHRBytesN = shr
+		trow.SHRParsedBytes = shrTxt
 	}
-	trow.SHR = row[command_output_row_idx_shr]
-	trow.SHRBytesN = shr
-	trow.SHRParsedBytes = shrTxt
 
-	trow.S = row[command_output_row_idx_s]
-	trow.SParsedStatus = parseStatus(row[command_output_row_idx_s])
+	if v, ok := col("S"); ok {
+		trow.S = v
+		trow.SParsedStatus = parseStatus(v)
+	}
 
-	cnum, err := strconv.ParseFloat(row[command_output_row_idx_cpu], 64)
-	if err != nil {
-		return Row{}, fmt.Errorf("parse error %v (row %v)", err, row)
+	if v, ok := col("%CPU"); ok {
+		// busybox prints "0%"
+		cnum, err := parseFloat(strings.TrimSuffix(v, "%"))
+		if err != nil {
+			return Row{}, fmt.Errorf("parse error %v (row %v)", err, row)
+		}
+		trow.CPUPercent = cnum
 	}
-	trow.CPUPercent = cnum
 
-	mnum, err := strconv.ParseFloat(row[command_output_row_idx_mem], 64)
-	if err != nil {
-		return Row{}, fmt.Errorf("parse error %v (row %v)", err, row)
+	if v, ok := col("%MEM"); ok {
+		mnum, err := parseFloat(strings.TrimSuffix(v, "%"))
+		if err != nil {
+			return Row{}, fmt.Errorf("parse error %v (row %v)", err, row)
+		}
+		trow.MEMPercent = mnum
 	}
-	trow.MEMPercent = mnum
 
-	trow.TIME = row[command_output_row_idx_time]
+	trow.TIME, _ = col("TIME+")
+	trow.COMMAND, _ = col("COMMAND")
 
 	return trow, nil
 }
@@ -14,3 +14,59 @@ func TestTop_parseMemoryTxt(t *testing.T) {
 		t.Fatalf("humanized bytes expected '54 GB', got %q", bs)
 	}
 }
+
+func TestTop_parseMemoryTxtVariants(t *testing.T) {
+	tests := []struct {
+		s   string
+		bts uint64
+	}{
+		{"9432", 9432 * 1024},
+		{"2m", 2 << 20},
+		{"1,5g", 1 << 30},
+		{"3t", 3 << 40},
+		{"1P", 1 << 50},
+		{"1e", 1 << 60},
+	}
+	for i, tt := range tests {
+		bts, _, err := parseMemoryTxt(tt.s)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if bts != tt.bts {
+			t.Fatalf("#%d: %q expected %d, got %d", i, tt.s, tt.bts, bts)
+		}
+	}
+	if _, _, err := parseMemoryTxt("1x"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	out := `top - 15:04:05 up 1 day,  2:01,  1 user,  load average: 0.00, 0.01, 0.05
+Threads: 2 total,   1 running,   1 sleeping,   0 stopped,   0 zombie
+KiB Mem :  8167848 total,  1399960 free,  3561104 used,  3206784 buff/cache
+
+  PID  UID %CPU  SWAP     TIME COMMAND
+    1    0  1,5     0  0:04.48 /sbin/init splash
+   42 1000  0.0     0  0:00.01 bash
+`
+	rows, err := ParseFields(out, []string{"PID", "UID", "%CPU", "SWAP", "TIME", "COMMAND"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %+v", rows)
+	}
+	SortRows(rows, SortByPID)
+	r := rows[1]
+	if r.PID != 1 || r.CPUPercent != 1.5 || r.TIME != "0:04.48" || r.COMMAND != "/sbin/init splash" || r.USER != "" {
+		t.Fatalf("unexpected row %+v", r)
+	}
+
+	if _, err = ParseFields(out, []string{"UID", "COMMAND"}); err == nil {
+		t.Fatal("expected error without PID")
+	}
+	if _, err = ParseFields(out, Headers); err == nil {
+		t.Fatal("expected error for the wrong layout")
+	}
+}
@@ -0,0 +1,128 @@
+package top
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Format is the output format of 'Stream.Pipe'.
+type Format int
+
+const (
+	// FormatJSON writes each row as a JSON object per line (NDJSON).
+	FormatJSON Format = iota
+	// FormatCSV writes a header, then each row as a CSV record.
+	FormatCSV
+)
+
+// pipeRowsToBuffer is the subscription buffer of 'Pipe'.
+const pipeRowsToBuffer = 4096
+
+// pipeRow is a row in 'FormatJSON'.
+type pipeRow struct {
+	Time       time.Time `json:"time"`
+	PID        int64     `json:"pid"`
+	OwnerPID   int64     `json:"owner_pid,omitempty"`
+	USER       string    `json:"user"`
+	PR         string    `json:"pr"`
+	NI         string    `json:"ni"`
+	VIRTBytesN uint64    `json:"virt_bytes_n"`
+	RESBytesN  uint64    `json:"res_bytes_n"`
+	SHRBytesN  uint64    `json:"shr_bytes_n"`
+	S          string    `json:"s"`
+	CPUPercent float64   `json:"cpu_percent"`
+	MEMPercent float64   `json:"mem_percent"`
+	TIME       string    `json:"time_plus"`
+	COMMAND    string    `json:"command"`
+}
+
+var pipeCSVHeader = []string{
+	"TIME",
+	"PID",
+	"OWNER-PID",
+	"USER",
+	"PR",
+	"NI",
+	"VIRT-BYTES",
+	"RES-BYTES",
+	"SHR-BYTES",
+	"S",
+	"CPU-PERCENT",
+	"MEM-PERCENT",
+	"TIME+",
+	"COMMAND",
+}
+
+// Pipe writes each new row to w, with the time it was received,
+// until the stream ends (then it returns nil) or a write fails.
+// It blocks, so run it in a goroutine to tee the stream into a
+// file or a log shipper. Like 'Subscribe', it drops the oldest rows
+// rather than blocking the stream if w falls behind by 4096 rows.
+func (str *Stream) Pipe(w io.Writer, format Format) error {
+	var write func(time.Time, Row) error
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		write = func(now time.Time, r Row) error {
+			return enc.Encode(pipeRow{
+				Time:       now,
+				PID:        r.PID,
+				OwnerPID:   r.OwnerPID,
+				USER:       r.USER,
+				PR:         r.PR,
+				NI:         r.NI,
+				VIRTBytesN: r.VIRTBytesN,
+				RESBytesN:  r.RESBytesN,
+				SHRBytesN:  r.SHRBytesN,
+				S:          r.S,
+				CPUPercent: r.CPUPercent,
+				MEMPercent: r.MEMPercent,
+				TIME:       r.TIME,
+				COMMAND:    r.COMMAND,
+			})
+		}
+
+	case FormatCSV:
+		wr := csv.NewWriter(w)
+		if err := wr.Write(pipeCSVHeader); err != nil {
+			return err
+		}
+		wr.Flush()
+		write = func(now time.Time, r Row) error {
+			wr.Write([]string{
+				now.Format(time.RFC3339Nano),
+				fmt.Sprintf("%d", r.PID),
+				fmt.Sprintf("%d", r.OwnerPID),
+				r.USER,
+				r.PR,
+				r.NI,
+				fmt.Sprintf("%d", r.VIRTBytesN),
+				fmt.Sprintf("%d", r.RESBytesN),
+				fmt.Sprintf("%d", r.SHRBytesN),
+				r.S,
+				fmt.Sprintf("%3.2f", r.CPUPercent),
+				fmt.Sprintf("%3.2f", r.MEMPercent),
+				r.TIME,
+				r.COMMAND,
+			})
+			// flush each row, for 'tail -f'
+			wr.Flush()
+			return wr.Error()
+		}
+
+	default:
+		return fmt.Errorf("unknown format %d", format)
+	}
+
+	ch, unsubscribe := str.Subscribe(pipeRowsToBuffer)
+	defer unsubscribe()
+	for r := range ch {
+		if err := write(str.clock.Now(), r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package top
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestStreamPipe(t *testing.T) {
+	pid := int64(os.Getpid())
+	for _, format := range []Format{FormatJSON, FormatCSV} {
+		cfg := &Config{
+			Native:     true,
+			Delay:      100 * time.Millisecond,
+			Iterations: 4,
+			PID:        pid,
+		}
+		str, err := cfg.StartStream()
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := new(bytes.Buffer)
+		donec := make(chan error)
+		go func() {
+			donec <- str.Pipe(buf, format)
+		}()
+		if err = str.Wait(); err != nil {
+			t.Fatal(err)
+		}
+		if err = <-donec; err != nil {
+			t.Fatal(err)
+		}
+
+		switch format {
+		case FormatJSON:
+			scanner := bufio.NewScanner(buf)
+			n := 0
+			for ; scanner.Scan(); n++ {
+				var pr pipeRow
+				if err = json.Unmarshal(scanner.Bytes(), &pr); err != nil {
+					t.Fatal(err)
+				}
+				if pr.PID != pid || pr.Time.IsZero() {
+					t.Fatalf("unexpected row %+v", pr)
+				}
+			}
+			if n == 0 {
+				t.Fatal("expected rows")
+			}
+
+		case FormatCSV:
+			records, err := csv.NewReader(buf).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) < 2 || len(records[0]) != len(pipeCSVHeader) {
+				t.Fatalf("unexpected records %q", records)
+			}
+		}
+	}
+	if err := (&Stream{}).Pipe(new(bytes.Buffer), Format(-1)); err == nil {
+		t.Fatal("expected unknown format error")
+	}
+}
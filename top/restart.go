@@ -0,0 +1,238 @@
+package top
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/kr/pty"
+)
+
+// RestartEvent is sent when 'top' dies and is restarted
+// (see 'Config.AutoRestartBackoff').
+type RestartEvent struct {
+	Time time.Time
+	// Attempt counts the restarts since 'top' died, from 1.
+	Attempt int
+	// Cause is why 'top' died (e.g. "signal: killed").
+	Cause error
+	// Err is the error from restarting 'top', nil if restarted.
+	Err error
+}
+
+// restartEventsToBuffer is the number of unread
+// restart events to keep, dropping the oldest.
+const restartEventsToBuffer = 16
+
+// RestartChan returns a channel that receives an event on each
+// restart attempt of 'top'. It buffers the latest 16 events,
+// and is closed when the stream ends.
+func (str *Stream) RestartChan() <-chan RestartEvent {
+	return str.restartc
+}
+
+// run feeds the outputs of 'top' into the queue, and restarts 'top'
+// when 'SetInterval' asks, or when it dies with 'AutoRestartBackoff'.
+func (str *Stream) run(pt *os.File) {
+	defer str.wg.Done()
+	for {
+		rerr := str.enqueue(pt)
+
+		// 'top' exited or was killed; reap it
+		str.rmu.RLock()
+		cmd := str.cmd
+		str.rmu.RUnlock()
+		werr := cmd.Wait()
+		str.pmu.Lock()
+		pt.Close()
+		str.pmu.Unlock()
+
+		str.rmu.Lock()
+		str.exitErr = werr
+		if rerr == nil || str.stopped() || str.ctx.Err() != nil {
+			// the stream failed, or was stopped
+			str.rmu.Unlock()
+			str.end(rerr)
+			return
+		}
+		requested := str.restartRequested
+		str.restartRequested = false
+		cfg := str.cfg
+		str.rmu.Unlock()
+
+		if !requested && (werr == nil || cfg.AutoRestartBackoff <= 0) {
+			// exited normally, or no restart
+			str.end(rerr)
+			return
+		}
+
+		var err error
+		pt, err = str.restart(cfg, werr, !requested)
+		if err != nil {
+			str.end(err)
+			return
+		}
+		str.rmu.Lock()
+		str.stats.Restarts++
+		str.rmu.Unlock()
+	}
+}
+
+// restart restarts 'top', retrying every 'AutoRestartBackoff'
+// until stopped if auto is true, and installs the new command.
+func (str *Stream) restart(cfg Config, cause error, auto bool) (*os.File, error) {
+	for attempt := 1; ; attempt++ {
+		if auto {
+			select {
+			case <-str.clock.After(cfg.AutoRestartBackoff):
+			case <-str.stopc:
+				return nil, cause
+			case <-str.ctx.Done():
+				return nil, str.ctx.Err()
+			}
+		}
+
+		pt, err := str.startCmd(&cfg)
+		if auto {
+			str.publishRestart(RestartEvent{Time: str.clock.Now(), Attempt: attempt, Cause: cause, Err: err})
+		}
+		if err == nil {
+			return pt, nil
+		}
+		if !auto && cfg.AutoRestartBackoff <= 0 {
+			return nil, err
+		}
+		auto = true
+	}
+}
+
+// startCmd starts 'top', and replaces the stream command.
+func (str *Stream) startCmd(cfg *Config) (*os.File, error) {
+	if err := cfg.createCmd(); err != nil {
+		return nil, err
+	}
+	pt, err := cfg.startTop()
+	if err != nil {
+		return nil, err
+	}
+
+	str.rmu.Lock()
+	str.cmd = cfg.cmd
+	if str.stopped() || str.ctx.Err() != nil {
+		// stopped while restarting; 'enqueue' reads
+		// until 'top' exits, then 'run' ends the stream
+		cfg.cmd.Process.Kill()
+	} else if str.paused {
+		signalCmd(cfg.cmd, true)
+	}
+	str.rmu.Unlock()
+	return pt, nil
+}
+
+// startTop starts 'top' on a pty, or on a pipe in 'BatchMode',
+// and returns the output to read. The output is also copied to
+// 'Config.Writer', if any.
+func (cfg *Config) startTop() (*os.File, error) {
+	if cfg.Writer == nil {
+		return cfg.startTopOutput()
+	}
+	// created before 'top' starts, so that it is not left
+	// running when the pipe fails
+	rd, wr, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cfg.startTopOutput()
+	if err != nil {
+		rd.Close()
+		wr.Close()
+		return nil, err
+	}
+	go teeOutput(out, wr, cfg.Writer)
+	return rd, nil
+}
+
+// teeOutput copies the output of 'top' to the stream pipe and to the
+// Writer, until either the output or the stream pipe is closed. Writer
+// errors are ignored, so that a failing Writer does not end the stream.
+func teeOutput(out, wr *os.File, w io.Writer) {
+	defer out.Close()
+	defer wr.Close()
+	buf := make([]byte, 32*1024)
+	for {
+		n, rerr := out.Read(buf)
+		if n > 0 {
+			if _, werr := wr.Write(buf[:n]); werr != nil {
+				return
+			}
+			w.Write(buf[:n])
+		}
+		if rerr != nil {
+			return
+		}
+	}
+}
+
+// startTopOutput starts 'top' on a pty, or on a pipe in 'BatchMode',
+// and returns its output.
+func (cfg *Config) startTopOutput() (*os.File, error) {
+	if !cfg.BatchMode {
+		return pty.Start(cfg.cmd)
+	}
+	rd, wr, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	cfg.cmd.Stdout, cfg.cmd.Stderr = wr, wr
+	if err = cfg.cmd.Start(); err != nil {
+		rd.Close()
+		wr.Close()
+		return nil, err
+	}
+	// 'top' has its own copy, so that
+	// the read ends with EOF once it exits
+	wr.Close()
+	return rd, nil
+}
+
+// stopped returns true if 'Stop' was called.
+func (str *Stream) stopped() bool {
+	select {
+	case <-str.stopc:
+		return true
+	default:
+		return false
+	}
+}
+
+// end ends the stream with the error.
+func (str *Stream) end(err error) {
+	str.rmu.Lock()
+	if str.err == nil {
+		str.err = err
+	}
+	str.rmu.Unlock()
+	str.rcond.Signal()
+}
+
+// publishRestart sends the event without blocking,
+// dropping the oldest event if the buffer is full.
+func (str *Stream) publishRestart(ev RestartEvent) {
+	str.submu.Lock()
+	if !str.closed {
+		select {
+		case str.restartc <- ev:
+		default:
+			// buffer is full; drop oldest
+			select {
+			case <-str.restartc:
+			default:
+			}
+			select {
+			case str.restartc <- ev:
+			default:
+			}
+		}
+	}
+	str.submu.Unlock()
+}
@@ -0,0 +1,96 @@
+package top
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SortField is the field to sort 'top' rows by.
+type SortField string
+
+const (
+	// SortByCPU sorts by %CPU, the default.
+	SortByCPU SortField = "%CPU"
+	// SortByMEM sorts by %MEM.
+	SortByMEM SortField = "%MEM"
+	// SortByTime sorts by TIME+.
+	SortByTime SortField = "TIME+"
+	// SortByPID sorts by PID.
+	SortByPID SortField = "PID"
+)
+
+func (f SortField) valid() bool {
+	switch f {
+	case "", SortByCPU, SortByMEM, SortByTime, SortByPID:
+		return true
+	}
+	return false
+}
+
+// less returns true if a sorts before b, highest first like 'top'.
+func (f SortField) less(a, b Row) bool {
+	switch f {
+	case SortByMEM:
+		if a.MEMPercent != b.MEMPercent {
+			return a.MEMPercent > b.MEMPercent
+		}
+	case SortByTime:
+		ta, tb := parseTimePlus(a.TIME), parseTimePlus(b.TIME)
+		if ta != tb {
+			return ta > tb
+		}
+	case SortByPID:
+	default:
+		if a.CPUPercent != b.CPUPercent {
+			return a.CPUPercent > b.CPUPercent
+		}
+	}
+	return a.PID > b.PID
+}
+
+// parseTimePlus parses TIME+ ("M:SS.hh") in hundredths of a second.
+// It returns 0 if the value is not in that format.
+func parseTimePlus(s string) int64 {
+	idx := strings.Index(s, ":")
+	if idx < 0 {
+		return 0
+	}
+	m, err := strconv.ParseInt(s[:idx], 10, 64)
+	if err != nil {
+		return 0
+	}
+	sec, err := strconv.ParseFloat(s[idx+1:], 64)
+	if err != nil {
+		return 0
+	}
+	return m*6000 + int64(sec*100+0.5)
+}
+
+// SortRows sorts the rows by the field, highest first like 'top'.
+func SortRows(rows []Row, field SortField) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		return field.less(rows[i], rows[j])
+	})
+}
+
+// TopN returns the n rows highest by 'Config.SortBy', of the
+// processes seen in the last two iterations, so that exited
+// processes are not returned. If n<1, it returns all of them.
+func (str *Stream) TopN(n int) []Row {
+	str.rmu.RLock()
+	rows := make([]Row, 0, len(str.pid2Row))
+	for pid, row := range str.pid2Row {
+		if str.lastSeen[pid]+1 >= str.iteration {
+			rows = append(rows, row)
+		}
+	}
+	field := str.cfg.SortBy
+	str.rmu.RUnlock()
+
+	SortRows(rows, field)
+	if n > 0 && len(rows) > n {
+		rows = rows[:n]
+	}
+	return rows
+}
@@ -0,0 +1,91 @@
+package top
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseTimePlus(t *testing.T) {
+	tests := []struct {
+		s   string
+		exp int64
+	}{
+		{"0:00.00", 0},
+		{"0:01.50", 150},
+		{"1:01.23", 6123},
+		{"60:00.00", 360000},
+		{"123:45", 123*6000 + 4500},
+		{"5h", 0},
+	}
+	for i, tt := range tests {
+		if v := parseTimePlus(tt.s); v != tt.exp {
+			t.Fatalf("#%d: expected %d, got %d", i, tt.exp, v)
+		}
+	}
+}
+
+func TestSortRows(t *testing.T) {
+	rows := []Row{
+		{PID: 1, CPUPercent: 1.0, MEMPercent: 5.0, TIME: "10:00.00"},
+		{PID: 2, CPUPercent: 9.0, MEMPercent: 1.0, TIME: "0:01.00"},
+		{PID: 3, CPUPercent: 1.0, MEMPercent: 2.0, TIME: "2:00.00"},
+	}
+	tests := []struct {
+		field SortField
+		pids  []int64
+	}{
+		{"", []int64{2, 3, 1}},
+		{SortByCPU, []int64{2, 3, 1}},
+		{SortByMEM, []int64{1, 3, 2}},
+		{SortByTime, []int64{1, 3, 2}},
+		{SortByPID, []int64{3, 2, 1}},
+	}
+	for i, tt := range tests {
+		SortRows(rows, tt.field)
+		pids := make([]int64, len(rows))
+		for j, row := range rows {
+			pids[j] = row.PID
+		}
+		if fmt.Sprint(pids) != fmt.Sprint(tt.pids) {
+			t.Fatalf("#%d: expected %v, got %v", i, tt.pids, pids)
+		}
+	}
+}
+
+func TestStreamTopN(t *testing.T) {
+	cfg := &Config{
+		Native:     true,
+		Delay:      100 * time.Millisecond,
+		Iterations: 2,
+		SortBy:     SortByMEM,
+	}
+	str, err := cfg.StartStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = str.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	// an exited process is not returned
+	str.rmu.Lock()
+	str.pid2Row[-1] = Row{PID: -1, MEMPercent: 100}
+	str.rmu.Unlock()
+
+	rows := str.TopN(3)
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %+v", rows)
+	}
+	for i, row := range rows {
+		if row.PID == -1 {
+			t.Fatalf("unexpected exited process %+v", row)
+		}
+		if i > 0 && rows[i-1].MEMPercent < row.MEMPercent {
+			t.Fatalf("expected descending %%MEM, got %+v", rows)
+		}
+	}
+	if all := str.TopN(0); len(all) < len(rows) {
+		t.Fatalf("expected all rows, got %+v", all)
+	}
+}
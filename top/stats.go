@@ -0,0 +1,27 @@
+package top
+
+// Stats is the statistics of the stream since it started.
+type Stats struct {
+	// LinesRead is the number of lines read from 'top'.
+	LinesRead uint64
+	// RowsParsed is the number of rows parsed, before
+	// 'Config.ProgramMatchFunc' drops any.
+	RowsParsed uint64
+	// RowsSkipped is the number of malformed rows
+	// (e.g. wrong number of columns), which are dropped
+	// without ending the stream.
+	RowsSkipped uint64
+	// LastSkipErr is why the last malformed row was skipped.
+	LastSkipErr error
+	// Restarts is the number of times 'top' was restarted,
+	// by 'SetInterval' or 'Config.AutoRestartBackoff'.
+	Restarts uint64
+}
+
+// Stats returns the statistics of the stream.
+func (str *Stream) Stats() Stats {
+	str.rmu.RLock()
+	st := str.stats
+	str.rmu.RUnlock()
+	return st
+}
@@ -0,0 +1,45 @@
+package top
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestStreamStats(t *testing.T) {
+	rd, wr, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		io.WriteString(wr, `top - 15:04:05 up 1 day,  2:01,  1 user,  load average: 0.00, 0.01, 0.05
+Tasks:   2 total,   1 running,   1 sleeping,   0 stopped,   0 zombie
+
+  PID USER      PR  NI    VIRT    RES    SHR S  %CPU %MEM     TIME+ COMMAND
+    1 root      20   0  225868   9432   6724 S   0.0  0.1   0:04.48 systemd
+    2 root      20   0       0      0      0 S   x.y  0.0   0:00.01 kthreadd
+    3 root      20   0       0      0      0 S   0.0  0.0
+`)
+		wr.Close()
+	}()
+
+	str := newStream(context.Background(), &Config{})
+	if err = str.enqueue(rd); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+	if !str.noError() {
+		t.Fatalf("unexpected error %v", str.err)
+	}
+	if len(str.queue) != 1 || str.queue[0].PID != 1 {
+		t.Fatalf("unexpected rows %+v", str.queue)
+	}
+
+	st := str.Stats()
+	if st.LinesRead != 7 || st.RowsParsed != 1 || st.RowsSkipped != 2 || st.Restarts != 0 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+	if st.LastSkipErr == nil {
+		t.Fatal("expected the skip error")
+	}
+}
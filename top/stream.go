@@ -3,20 +3,68 @@ package top
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
 	"sync"
+	"syscall"
+	"time"
 
-	"github.com/kr/pty"
+	"github.com/gyuho/linux-inspect/pkg/timeutil"
+	"github.com/gyuho/linux-inspect/proc"
 )
 
 // Stream provides top command output stream.
 type Stream struct {
-	cmd *exec.Cmd
-
-	pmu sync.Mutex
-	pt  *os.File
+	// cfg is the copy of the 'Config' to restart 'top' with,
+	// cmd is the running 'top'; protected by rmu, and
+	// only replaced by 'run'
+	cfg     Config
+	cmd     *exec.Cmd
+	exitErr error
+
+	// clock is 'Config.Clock', or 'timeutil.RealClock'
+	clock timeutil.Clock
+
+	// restartRequested is set by 'SetInterval', and paused
+	// by 'Pause'; protected by rmu
+	restartRequested bool
+	paused           bool
+	restartc         chan RestartEvent
+
+	// ctx kills 'top' when canceled; donec is closed
+	// once the stream ends, to release the watcher
+	ctx   context.Context
+	donec chan struct{}
+
+	// stopc is closed on 'Stop', to stop the native sampler
+	// ('Config.Native') or the restarts of 'top'
+	stopc    chan struct{}
+	stopOnce sync.Once
+
+	// intervalc wakes up the native sampler
+	// when the interval is changed
+	native    bool
+	interval  time.Duration // protected by rmu
+	intervalc chan struct{}
+
+	// threadMode resolves 'Row.OwnerPID' of each thread row
+	threadMode bool
+
+	// matchFunc drops rows whose command does not match, if not nil
+	matchFunc func(string) bool
+
+	// layout is the columns of the rows ('Config.Fields'),
+	// or from the header of busybox 'top'
+	layout  *layout
+	busybox bool
+	// pids filters the rows of busybox 'top', if not nil
+	pids map[int64]struct{}
+
+	pmu sync.Mutex // lock for pty, or the pipe in 'BatchMode'
 
 	// broadcast updates whenver available available
 	wg      sync.WaitGroup
@@ -24,34 +72,151 @@ type Stream struct {
 	rmu     sync.RWMutex // protect results
 	queue   []Row
 	pid2Row map[int64]Row
+	summary Summary
 	err     error
 	errc    chan error
+	stats   Stats
+
+	// iteration counts the outputs of 'top', and lastSeen
+	// is the last iteration of each PID
+	iteration int64
+	lastSeen  map[int64]int64
+
+	// cores is the usage of each CPU core in the last iteration
+	cores     map[int]CPUUsage
+	prevCores map[int]proc.CPUStat
+
+	// histories keeps the last rows per PID, if historySize > 0
+	historySize int
+	histories   map[int64]*history
+
+	// summary being parsed from the 'top' output
+	pending Summary
 
 	// signal only once at initial, once the first line is ready
 	readymu sync.Mutex
 	ready   bool
 	readyc  chan struct{}
+
+	// fan-out to subscribers; protected by submu
+	submu       sync.Mutex
+	subs        map[*subscriber]struct{}
+	summarySubs map[*summarySubscriber]struct{}
+	summaryc    chan Summary
+	closed      bool
+}
+
+// subscriber receives row updates from the stream.
+type subscriber struct {
+	once sync.Once
+	ch   chan Row
+}
+
+func (sub *subscriber) close() {
+	sub.once.Do(func() { close(sub.ch) })
+}
+
+// StartError is returned from 'StartStream' when 'top' exits
+// or fails before emitting its first row.
+type StartError struct {
+	// Err is the underlying error, nil if 'top' exited cleanly.
+	Err error
+}
+
+func (e *StartError) Error() string {
+	if e.Err == nil {
+		return "'top' exited before emitting any row"
+	}
+	return fmt.Sprintf("'top' failed before emitting any row (%v)", e.Err)
 }
 
 // StartStream starts 'top' command stream.
+// It returns *StartError if 'top' fails before emitting its first row.
 func (cfg *Config) StartStream() (*Stream, error) {
+	return cfg.StartStreamContext(context.Background())
+}
+
+// StartStreamContext is like 'StartStream' but kills the 'top' process
+// once ctx is canceled, either while waiting for the first row (then
+// it returns ctx.Err()) or afterwards (then ctx.Err() is sent to
+// 'ErrChan' and the subscribers are closed). 'Stop' or 'Wait' must
+// still be called to release the process.
+func (cfg *Config) StartStreamContext(ctx context.Context) (*Stream, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if cfg.Native {
+		return cfg.startNativeStream(ctx)
+	}
 	if err := cfg.createCmd(); err != nil {
 		return nil, err
 	}
-	pt, err := pty.Start(cfg.cmd)
+	pt, err := cfg.startTop()
 	if err != nil {
 		return nil, err
 	}
 
+	str := newStream(ctx, cfg)
+	str.cmd = cfg.cmd
+
+	str.wg.Add(1)
+	go str.run(pt)
+	go str.dequeue()
+	go str.watch()
+
+	return str.waitReady()
+}
+
+// startNativeStream starts the stream with 'Sampler'.
+func (cfg *Config) startNativeStream(ctx context.Context) (*Stream, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	str := newStream(ctx, cfg)
+	str.native = true
+	str.interval = cfg.delay()
+	str.intervalc = make(chan struct{}, 1)
+
+	s := NewSampler(cfg.PID, cfg.ThreadMode)
+	s.Clock = str.clock
+	s.PIDs = cfg.PIDs
+	s.ProgramMatchFunc = cfg.ProgramMatchFunc
+	s.SortBy = cfg.SortBy
+
+	str.wg.Add(1)
+	go str.sample(s, cfg.iterations())
+	go str.dequeue()
+
+	return str.waitReady()
+}
+
+func newStream(ctx context.Context, cfg *Config) *Stream {
 	str := &Stream{
-		cmd: cfg.cmd,
+		cfg:   *cfg,
+		clock: cfg.clock(),
+
+		threadMode: cfg.ThreadMode,
+		matchFunc:  cfg.ProgramMatchFunc,
+		layout:     cfg.layout(),
+		busybox:    cfg.flavor() == FlavorBusybox,
+		pids:       cfg.pidFilter(),
+
+		restartc: make(chan RestartEvent, restartEventsToBuffer),
+
+		ctx:   ctx,
+		donec: make(chan struct{}),
+		stopc: make(chan struct{}),
 
 		pmu: sync.Mutex{},
-		pt:  pt,
 
 		wg:  sync.WaitGroup{},
 		rmu: sync.RWMutex{},
 
+		lastSeen: make(map[int64]int64, 500),
+
+		historySize: cfg.HistorySize,
+		histories:   make(map[int64]*history),
+
 		// pre-allocate
 		queue:   make([]Row, 0, 500),
 		pid2Row: make(map[int64]Row, 500),
@@ -60,15 +225,45 @@ func (cfg *Config) StartStream() (*Stream, error) {
 
 		ready:  false,
 		readyc: make(chan struct{}, 1),
+
+		subs:        make(map[*subscriber]struct{}),
+		summarySubs: make(map[*summarySubscriber]struct{}),
+		summaryc:    make(chan Summary, 1),
 	}
 	str.rcond = sync.NewCond(&str.rmu)
+	return str
+}
 
-	str.wg.Add(1)
-	go str.enqueue()
-	go str.dequeue()
+// waitReady waits for the first row.
+func (str *Stream) waitReady() (*Stream, error) {
+	var err error
+	// 'top' may fail before emitting any row
+	select {
+	case <-str.readyc:
+		return str, nil
+	case err = <-str.errc:
+		str.close(true)
+		if cerr := str.ctx.Err(); cerr != nil {
+			return nil, cerr
+		}
+		return nil, err
+	case <-str.ctx.Done():
+		str.close(true)
+		return nil, str.ctx.Err()
+	}
+}
 
-	<-str.readyc
-	return str, nil
+// watch kills the 'top' process when the context is canceled,
+// which unblocks the pty read in 'enqueue'.
+func (str *Stream) watch() {
+	select {
+	case <-str.ctx.Done():
+		str.rmu.RLock()
+		cmd := str.cmd
+		str.rmu.RUnlock()
+		cmd.Process.Kill()
+	case <-str.donec:
+	}
 }
 
 // Stop kills the 'top' process and waits for it to exit.
@@ -81,7 +276,8 @@ func (str *Stream) Wait() error {
 	return str.close(false)
 }
 
-// ErrChan returns the error from stream.
+// ErrChan returns the error that ended the stream. Malformed
+// rows do not end the stream; they are counted in 'Stats'.
 func (str *Stream) ErrChan() <-chan error {
 	return str.errc
 }
@@ -97,6 +293,135 @@ func (str *Stream) Latest() map[int64]Row {
 	return cm
 }
 
+// Summary returns the latest summary area (load average, tasks,
+// CPU and memory).
+func (str *Stream) Summary() Summary {
+	str.rmu.RLock()
+	sm := str.summary
+	str.rmu.RUnlock()
+	return sm
+}
+
+// SummaryChan returns a channel that receives each new summary.
+// It buffers only the latest summary, and is closed when the
+// stream ends.
+func (str *Stream) SummaryChan() <-chan Summary {
+	return str.summaryc
+}
+
+// Subscribe returns a channel that receives every new row from the stream,
+// and a function to unsubscribe. It is safe to subscribe from multiple
+// goroutines. The channel is closed on unsubscribe or when the stream ends.
+//
+// Each subscriber has its own buffer of 'size' rows (at least 1). Slow
+// subscribers never block the parser or other subscribers: when the buffer
+// is full, the oldest row is dropped to make room for the newest.
+// Use Latest to get the full current snapshot.
+func (str *Stream) Subscribe(size int) (<-chan Row, func()) {
+	if size < 1 {
+		size = 1
+	}
+	sub := &subscriber{ch: make(chan Row, size)}
+
+	str.submu.Lock()
+	if str.closed {
+		sub.close()
+	} else {
+		str.subs[sub] = struct{}{}
+	}
+	str.submu.Unlock()
+
+	unsubscribe := func() {
+		str.submu.Lock()
+		delete(str.subs, sub)
+		str.submu.Unlock()
+		sub.close()
+	}
+	return sub.ch, unsubscribe
+}
+
+// publish sends a row to all subscribers without blocking,
+// dropping the oldest buffered row of slow subscribers.
+func (str *Stream) publish(row Row) {
+	str.submu.Lock()
+	for sub := range str.subs {
+		select {
+		case sub.ch <- row:
+			continue
+		default:
+		}
+		// buffer is full; drop oldest
+		select {
+		case <-sub.ch:
+		default:
+		}
+		select {
+		case sub.ch <- row:
+		default:
+		}
+	}
+	str.submu.Unlock()
+}
+
+// publishSummary updates the latest summary and sends it without
+// blocking, replacing the buffered one if not yet received.
+func (str *Stream) publishSummary(sm Summary) {
+	str.sampleCores()
+
+	str.rmu.Lock()
+	str.summary = sm
+	str.iteration++
+	if str.cfg.EvictAfter > 0 {
+		str.evict(int64(str.cfg.EvictAfter))
+	}
+	str.rmu.Unlock()
+
+	str.submu.Lock()
+	if !str.closed {
+		select {
+		case <-str.summaryc:
+		default:
+		}
+		str.summaryc <- sm
+	}
+	for sub := range str.summarySubs {
+		select {
+		case sub.ch <- sm:
+			continue
+		default:
+		}
+		// buffer is full; drop oldest
+		select {
+		case <-sub.ch:
+		default:
+		}
+		select {
+		case sub.ch <- sm:
+		default:
+		}
+	}
+	str.submu.Unlock()
+}
+
+// closeSubscribers closes all subscriber channels once the stream ends.
+func (str *Stream) closeSubscribers() {
+	str.submu.Lock()
+	for sub := range str.subs {
+		sub.close()
+	}
+	str.subs = make(map[*subscriber]struct{})
+	for sub := range str.summarySubs {
+		sub.close()
+	}
+	str.summarySubs = make(map[*summarySubscriber]struct{})
+	if !str.closed && str.summaryc != nil {
+		close(str.summaryc)
+		close(str.restartc)
+	}
+	str.closed = true
+	str.submu.Unlock()
+}
+
 func (str *Stream) noError() (noErr bool) {
 	str.rmu.RLock()
 	noErr = str.err == nil
@@ -104,44 +429,174 @@ func (str *Stream) noError() (noErr bool) {
 	return
 }
 
-// feed new top results into the queue
-func (str *Stream) enqueue() {
+// sample feeds the native sampler results into the queue,
+// every interval, until stopped or out of iterations.
+func (str *Stream) sample(s *Sampler, iterations int) {
 	defer str.wg.Done()
-	reader := bufio.NewReader(str.pt)
+
+	var err error
+	for i := 0; iterations == 0 || i < iterations; i++ {
+		if i > 0 {
+			str.wait()
+		}
+		select {
+		case <-str.stopc:
+			err = errSamplerDone
+		case <-str.ctx.Done():
+			err = str.ctx.Err()
+		default:
+		}
+		if err != nil {
+			break
+		}
+
+		var rows []Row
+		rows, err = s.Sample()
+		if err != nil {
+			break
+		}
+		str.publishSummary(s.Summary())
+
+		str.rmu.Lock()
+		str.stats.RowsParsed += uint64(len(rows))
+		for _, r := range rows {
+			str.lastSeen[r.PID] = str.iteration
+		}
+		str.queue = append(str.queue, rows...)
+		if len(str.queue) > 0 {
+			str.rcond.Signal()
+		}
+		str.rmu.Unlock()
+	}
+	if err == nil {
+		err = errSamplerDone
+	}
+
+	str.rmu.Lock()
+	str.err = err
+	str.rmu.Unlock()
+	// we got error; signal!
+	str.rcond.Signal()
+}
+
+// wait waits for the interval of the native sampler, and waits
+// again when 'SetInterval' changes it, or while paused.
+func (str *Stream) wait() {
+	for {
+		str.rmu.RLock()
+		interval, paused := str.interval, str.paused
+		str.rmu.RUnlock()
+
+		var timec <-chan time.Time
+		if !paused {
+			timec = str.clock.After(interval)
+		}
+		select {
+		case <-timec:
+			return
+		case <-str.intervalc:
+		case <-str.stopc:
+			return
+		case <-str.ctx.Done():
+			return
+		}
+	}
+}
+
+// feed new top results into the queue, until the pty read fails
+// (then it returns the error, for 'run' to restart 'top' or end
+// the stream) or the stream fails
+func (str *Stream) enqueue(pt *os.File) error {
+	reader := bufio.NewReader(pt)
 	for str.noError() {
 		// lock for pty
 		str.pmu.Lock()
 		data, _, lerr := reader.ReadLine()
 		str.pmu.Unlock()
 
+		if cerr := str.ctx.Err(); cerr != nil {
+			// 'top' was killed on cancel; report why
+			str.rmu.Lock()
+			str.err = cerr
+			str.rmu.Unlock()
+			break
+		}
+		if lerr != nil {
+			return lerr
+		}
+		str.rmu.Lock()
+		str.stats.LinesRead++
+		str.rmu.Unlock()
+
 		data = bytes.TrimSpace(data)
+		line := string(data)
+		if str.busybox {
+			if strings.HasPrefix(line, "Mem:") {
+				str.pending = Summary{}
+			}
+			if parseBusyboxSummaryLine(&str.pending, line) {
+				continue
+			}
+		} else {
+			if strings.HasPrefix(line, "top -") {
+				str.pending = Summary{}
+			}
+			if parseSummaryLine(&str.pending, line) {
+				continue
+			}
+		}
+		if strings.HasPrefix(line, "PID ") {
+			if str.busybox {
+				// the columns depend on how busybox was built
+				if lt, err := newLayout(strings.Fields(line)); err == nil {
+					str.rmu.Lock()
+					str.layout = lt
+					str.rmu.Unlock()
+				}
+			}
+			// end of the summary area
+			str.publishSummary(str.pending)
+			continue
+		}
 		if topRowToSkip(data) {
 			continue
 		}
-		line := string(data)
 
 		// lock for results
 		str.rmu.Lock()
 
-		str.err = lerr
 		if line == "" {
 			str.rmu.Unlock()
 			continue
 		}
 
-		row := strings.Fields(line)
-		if len(row) != len(Headers) {
+		// a malformed row should not end the stream
+		row, rerr := str.layout.split(line)
+		var r Row
+		if rerr == nil {
+			r, rerr = str.layout.parse(row)
+		}
+		if rerr != nil {
+			str.stats.RowsSkipped++
+			str.stats.LastSkipErr = rerr
 			str.rmu.Unlock()
 			continue
 		}
-
-		r, rerr := parseRow(row)
-		if rerr != nil {
-			str.err = rerr
+		str.stats.RowsParsed++
+		if str.matchFunc != nil && !str.matchFunc(r.COMMAND) {
 			str.rmu.Unlock()
 			continue
 		}
+		if _, ok := str.pids[r.PID]; str.pids != nil && !ok {
+			str.rmu.Unlock()
+			continue
+		}
+		if str.threadMode {
+			// thread may have exited; leave it unresolved
+			r.OwnerPID, _ = GetOwnerPID(r.PID)
+		}
 
+		str.lastSeen[r.PID] = str.iteration
 		str.queue = append(str.queue, r)
 		if len(str.queue) == 1 {
 			// we have a new output; signal!
@@ -152,6 +607,7 @@ func (str *Stream) enqueue() {
 
 	// we got error; signal!
 	str.rcond.Signal()
+	return nil
 }
 
 // dequeue polls from 'top' process.
@@ -173,6 +629,8 @@ func (str *Stream) dequeue() {
 		str.queue = str.queue[1:]
 
 		str.pid2Row[row.PID] = row
+		str.record(row)
+		str.publish(row)
 
 		toc := false
 		str.readymu.Lock()
@@ -188,28 +646,131 @@ func (str *Stream) dequeue() {
 	if expectedErr(str.err) {
 		str.err = nil
 	}
+	str.readymu.Lock()
+	ready := str.ready
+	str.readymu.Unlock()
+	if !ready {
+		// unblock 'StartStream' even with an expected error (e.g. EOF)
+		str.err = &StartError{Err: str.err}
+	}
 	if str.err != nil {
 		str.errc <- str.err
 	}
 	str.rmu.Unlock()
+
+	str.closeSubscribers()
+	close(str.donec)
 }
 
-func (str *Stream) close(kill bool) (err error) {
-	if str.cmd == nil {
-		return str.err
+// SetInterval changes the delay between updates without ending the
+// stream. 'top' in batch mode does not accept interactive commands,
+// so it is restarted with the new delay (and a new 'Iterations'
+// count); the rows, summary and subscribers are kept.
+func (str *Stream) SetInterval(d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("invalid interval %v", d)
 	}
-	if kill {
-		str.cmd.Process.Kill()
+	if str.native {
+		str.rmu.Lock()
+		str.interval = d
+		str.rmu.Unlock()
+		select {
+		case str.intervalc <- struct{}{}:
+		default:
+		}
+		return nil
+	}
+
+	select {
+	case <-str.stopc:
+		return fmt.Errorf("stream already stopped")
+	case <-str.donec:
+		return fmt.Errorf("stream already ended")
+	default:
+	}
+
+	str.rmu.Lock()
+	str.cfg.Delay, str.cfg.IntervalSecond = d, 0
+	str.restartRequested = true
+	cmd := str.cmd
+	str.rmu.Unlock()
+
+	// 'run' restarts 'top' once the pty read fails
+	cmd.Process.Kill()
+	return nil
+}
+
+// Pause stops 'top' with SIGSTOP (or the native sampler) until
+// 'Resume', so no new rows are parsed. The stream, its rows and
+// subscribers are kept; 'Stop' still works while paused.
+func (str *Stream) Pause() error {
+	return str.setPaused(true)
+}
+
+// Resume continues 'top' with SIGCONT (or the native sampler)
+// after 'Pause'.
+func (str *Stream) Resume() error {
+	return str.setPaused(false)
+}
+
+// Paused returns true if the stream is paused.
+func (str *Stream) Paused() bool {
+	str.rmu.RLock()
+	defer str.rmu.RUnlock()
+	return str.paused
+}
+
+func (str *Stream) setPaused(paused bool) error {
+	str.rmu.Lock()
+	defer str.rmu.Unlock()
+	if str.paused == paused {
+		return nil
 	}
+	if str.native {
+		str.paused = paused
+		select {
+		case str.intervalc <- struct{}{}:
+		default:
+		}
+		return nil
+	}
+	if err := signalCmd(str.cmd, paused); err != nil {
+		return err
+	}
+	str.paused = paused
+	return nil
+}
 
-	err = str.cmd.Wait()
+// signalCmd sends SIGSTOP to 'top' if stop is true, SIGCONT otherwise.
+func signalCmd(cmd *exec.Cmd, stop bool) error {
+	if stop {
+		return cmd.Process.Signal(syscall.SIGSTOP)
+	}
+	return cmd.Process.Signal(syscall.SIGCONT)
+}
 
-	str.pmu.Lock()
-	str.pt.Close() // close file
-	str.pmu.Unlock()
+func (str *Stream) close(kill bool) (err error) {
+	if kill {
+		str.stopOnce.Do(func() { close(str.stopc) })
+	}
+	if str.native {
+		str.wg.Wait()
+		<-str.donec // all rows dequeued
+		return nil
+	}
 
+	if kill {
+		str.rmu.RLock()
+		cmd := str.cmd
+		str.rmu.RUnlock()
+		cmd.Process.Kill()
+	}
 	str.wg.Wait()
 
+	str.rmu.RLock()
+	err = str.exitErr
+	str.rmu.RUnlock()
+
 	if err != nil {
 		if !kill && strings.Contains(err.Error(), "exit status") {
 			err = nil // non-zero exit code
@@ -217,12 +778,11 @@ func (str *Stream) close(kill bool) (err error) {
 			err = nil
 		}
 	}
-	str.cmd = nil
 	return err
 }
 
 func expectedErr(err error) bool {
-	if err == nil {
+	if err == nil || err == errSamplerDone || err == io.EOF {
 		return true
 	}
 	es := err.Error()
@@ -1,10 +1,17 @@
 package top
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/gyuho/linux-inspect/pkg/timeutil"
 )
 
 func TestTopStartTopStream(t *testing.T) {
@@ -37,4 +44,414 @@ func TestTopStartTopStream(t *testing.T) {
 		fmt.Printf("%+v\n", row)
 	}
 	fmt.Println("total", len(rm), "processes")
+
+	sm := str.Summary()
+	fmt.Printf("summary: %+v\n", sm)
+	if sm.TasksTotal == 0 || sm.MemTotalBytesN == 0 {
+		t.Fatalf("unexpected summary %+v", sm)
+	}
+}
+
+func TestTopStreamBatchMode(t *testing.T) {
+	pid := int64(os.Getpid())
+	for _, iterations := range []int{2, 0} {
+		cfg := &Config{
+			Exec:       DefaultExecPath,
+			Delay:      200 * time.Millisecond,
+			Iterations: iterations,
+			PID:        pid,
+			BatchMode:  true,
+		}
+		str, err := cfg.StartStream()
+		if err != nil {
+			t.Skip(err)
+		}
+		if iterations > 0 {
+			// 'top' exits, and the read ends with EOF
+			err = str.Wait()
+		} else {
+			time.Sleep(500 * time.Millisecond)
+			err = str.Stop()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case err = <-str.ErrChan():
+			t.Fatalf("iterations %d: %v", iterations, err)
+		default:
+		}
+		if row, ok := str.Latest()[pid]; !ok || row.PID != pid {
+			t.Fatalf("iterations %d: unexpected rows %+v", iterations, str.Latest())
+		}
+	}
+}
+
+// lockedBuffer is a bytes.Buffer safe for concurrent use.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestTopStreamBatchModeWriter(t *testing.T) {
+	pid := int64(os.Getpid())
+	w := new(lockedBuffer)
+	cfg := &Config{
+		Exec:       DefaultExecPath,
+		Delay:      200 * time.Millisecond,
+		Iterations: 2,
+		PID:        pid,
+		BatchMode:  true,
+		Writer:     w,
+	}
+	str, err := cfg.StartStream()
+	if err != nil {
+		t.Skip(err)
+	}
+	if err = str.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := str.Latest()[pid]; !ok {
+		t.Fatalf("unexpected rows %+v", str.Latest())
+	}
+	if out := w.String(); !strings.Contains(out, "PID") || !strings.Contains(out, fmt.Sprint(pid)) {
+		t.Fatalf("expected 'top' output in Writer, got %q", out)
+	}
+}
+
+func TestTopStreamSubscribe(t *testing.T) {
+	cfg := &Config{
+		Exec:           DefaultExecPath,
+		IntervalSecond: 1,
+	}
+	str, err := cfg.StartStream()
+	if err != nil {
+		t.Skip(err)
+	}
+
+	fast, unsubFast := str.Subscribe(1000)
+	defer unsubFast()
+	slow, unsubSlow := str.Subscribe(1)
+
+	time.Sleep(2 * time.Second)
+
+	// slow subscriber never reads; it must not block the other
+	select {
+	case row := <-fast:
+		fmt.Printf("fast subscriber: %+v\n", row)
+	case <-time.After(3 * time.Second):
+		t.Fatal("fast subscriber got no row")
+	}
+	unsubSlow()
+	if _, ok := <-slow; ok {
+		// at most one buffered row remains
+		if _, ok = <-slow; ok {
+			t.Fatal("slow subscriber buffer exceeded its size")
+		}
+	}
+
+	if err = str.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTopStreamPublishDropOldest(t *testing.T) {
+	str := &Stream{subs: make(map[*subscriber]struct{})}
+	ch, unsub := str.Subscribe(2)
+	for i := int64(1); i <= 5; i++ {
+		str.publish(Row{PID: i})
+	}
+	if r := <-ch; r.PID != 4 {
+		t.Fatalf("expected PID 4, got %d", r.PID)
+	}
+	if r := <-ch; r.PID != 5 {
+		t.Fatalf("expected PID 5, got %d", r.PID)
+	}
+	unsub()
+	if _, ok := <-ch; ok {
+		t.Fatal("expected closed channel")
+	}
+
+	str.closeSubscribers()
+	ch, _ = str.Subscribe(1)
+	if _, ok := <-ch; ok {
+		t.Fatal("expected closed channel after stream end")
+	}
+}
+
+func TestTopStartStreamGarbage(t *testing.T) {
+	f, err := ioutil.TempFile("", "fake-top")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(f.Name())
+	f.WriteString("#!/bin/sh\necho 'top: failed tty get'\necho garbage\nexit 1\n")
+	f.Close()
+	if err = os.Chmod(f.Name(), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{Exec: f.Name(), IntervalSecond: 1}
+	donec := make(chan error, 1)
+	go func() {
+		_, serr := cfg.StartStream()
+		donec <- serr
+	}()
+	select {
+	case err = <-donec:
+		if _, ok := err.(*StartError); !ok {
+			t.Fatalf("expected *StartError, got %v", err)
+		}
+		fmt.Println("StartStream:", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("StartStream took too long")
+	}
+}
+
+func TestTopStartStreamContextStartup(t *testing.T) {
+	f, err := ioutil.TempFile("", "fake-top")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(f.Name())
+	// never prints a parseable row
+	f.WriteString("#!/bin/sh\nexec sleep 30\n")
+	f.Close()
+	if err = os.Chmod(f.Name(), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{Exec: f.Name(), IntervalSecond: 1}
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	donec := make(chan error, 1)
+	go func() {
+		_, serr := cfg.StartStreamContext(ctx)
+		donec <- serr
+	}()
+	select {
+	case err = <-donec:
+		if err != context.DeadlineExceeded {
+			t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("StartStreamContext took too long")
+	}
+}
+
+func TestTopStartStreamContextCancel(t *testing.T) {
+	cfg := &Config{
+		Exec:           DefaultExecPath,
+		IntervalSecond: 1,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	str, err := cfg.StartStreamContext(ctx)
+	if err != nil {
+		t.Skip(err)
+	}
+	ch, unsub := str.Subscribe(1)
+	defer unsub()
+
+	cancel()
+	select {
+	case err = <-str.ErrChan():
+		if err != context.Canceled {
+			t.Fatalf("expected %v, got %v", context.Canceled, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not end on cancel")
+	}
+	for range ch {
+	}
+	str.Stop()
+}
+
+func TestTopStreamSetInterval(t *testing.T) {
+	for _, native := range []bool{false, true} {
+		cfg := &Config{
+			Exec:   DefaultExecPath,
+			Delay:  time.Second,
+			PID:    int64(os.Getpid()),
+			Native: native,
+		}
+		str, err := cfg.StartStream()
+		if err != nil {
+			t.Skip(err)
+		}
+		ch, unsub := str.Subscribe(100)
+		<-ch // first iteration
+
+		if err = str.SetInterval(200 * time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(2 * time.Second)
+		unsub()
+
+		n := 0
+		for range ch {
+			n++
+		}
+		if n < 5 {
+			t.Fatalf("native %v: expected rows every 200ms, got %d rows", native, n)
+		}
+		select {
+		case err = <-str.ErrChan():
+			t.Fatalf("native %v: %v", native, err)
+		default:
+		}
+		if err = str.Stop(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTopStreamAutoRestart(t *testing.T) {
+	cfg := &Config{
+		Exec:               DefaultExecPath,
+		Delay:              200 * time.Millisecond,
+		PID:                int64(os.Getpid()),
+		AutoRestartBackoff: 100 * time.Millisecond,
+	}
+	str, err := cfg.StartStream()
+	if err != nil {
+		t.Skip(err)
+	}
+
+	// simulate 'top' being OOM killed
+	str.rmu.RLock()
+	str.cmd.Process.Kill()
+	str.rmu.RUnlock()
+
+	select {
+	case ev := <-str.RestartChan():
+		if ev.Attempt != 1 || ev.Err != nil || ev.Cause == nil {
+			t.Fatalf("unexpected restart event %+v", ev)
+		}
+		fmt.Printf("restarted: %+v\n", ev)
+	case <-time.After(5 * time.Second):
+		t.Fatal("'top' was not restarted")
+	}
+
+	ch, unsub := str.Subscribe(1)
+	defer unsub()
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no row after restart")
+	}
+	select {
+	case err = <-str.ErrChan():
+		t.Fatal(err)
+	default:
+	}
+	if st := str.Stats(); st.Restarts != 1 || st.RowsParsed < 2 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+	if err = str.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-str.RestartChan(); ok {
+		t.Fatal("expected closed restart channel")
+	}
+}
+
+func TestTopStreamAutoRestartClock(t *testing.T) {
+	clock := timeutil.NewFakeClock(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	cfg := &Config{
+		Exec:               DefaultExecPath,
+		Delay:              200 * time.Millisecond,
+		PID:                int64(os.Getpid()),
+		AutoRestartBackoff: time.Hour,
+		Clock:              clock,
+	}
+	str, err := cfg.StartStream()
+	if err != nil {
+		t.Skip(err)
+	}
+	defer str.Stop()
+
+	str.rmu.RLock()
+	str.cmd.Process.Kill()
+	str.rmu.RUnlock()
+
+	// 'top' is restarted only once the backoff elapses on the clock
+	for clock.Waiters() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case ev := <-str.RestartChan():
+		t.Fatalf("restarted before the backoff %+v", ev)
+	default:
+	}
+	clock.Advance(time.Hour)
+
+	select {
+	case ev := <-str.RestartChan():
+		if ev.Attempt != 1 || ev.Err != nil || !ev.Time.Equal(clock.Now()) {
+			t.Fatalf("unexpected restart event %+v", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("'top' was not restarted")
+	}
+}
+
+func TestTopStreamPause(t *testing.T) {
+	for _, native := range []bool{false, true} {
+		cfg := &Config{
+			Exec:   DefaultExecPath,
+			Delay:  100 * time.Millisecond,
+			PID:    int64(os.Getpid()),
+			Native: native,
+		}
+		str, err := cfg.StartStream()
+		if err != nil {
+			t.Skip(err)
+		}
+		if err = str.Pause(); err != nil {
+			t.Fatal(err)
+		}
+		if !str.Paused() {
+			t.Fatalf("native %v: expected paused", native)
+		}
+
+		// rows already in the pty may still arrive
+		time.Sleep(300 * time.Millisecond)
+		ch, unsub := str.Subscribe(100)
+		select {
+		case row := <-ch:
+			t.Fatalf("native %v: unexpected row while paused %+v", native, row)
+		case <-time.After(time.Second):
+		}
+
+		if err = str.Resume(); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-ch:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("native %v: no row after resume", native)
+		}
+		unsub()
+
+		// stop while paused
+		if err = str.Pause(); err != nil {
+			t.Fatal(err)
+		}
+		if err = str.Stop(); err != nil {
+			t.Fatal(err)
+		}
+	}
 }
@@ -0,0 +1,254 @@
+package top
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Summary is the summary area above the rows in 'top' output.
+type Summary struct {
+	// Time is the current time (e.g. "15:04:05").
+	Time string
+	// Uptime is the system uptime (e.g. "3 days,  2:01").
+	Uptime string
+	// Users is the number of logged in users.
+	Users int64
+
+	LoadAvg1Minute  float64
+	LoadAvg5Minute  float64
+	LoadAvg15Minute float64
+
+	// Tasks are the processes, or threads in thread mode.
+	TasksTotal    int64
+	TasksRunning  int64
+	TasksSleeping int64
+	TasksStopped  int64
+	TasksZombie   int64
+
+	// CPU percentages of all CPUs ('%Cpu(s)').
+	CPUUser    float64
+	CPUSystem  float64
+	CPUNice    float64
+	CPUIdle    float64
+	CPUIowait  float64
+	CPUHardIRQ float64
+	CPUSoftIRQ float64
+	CPUSteal   float64
+
+	MemTotalBytesN     uint64
+	MemFreeBytesN      uint64
+	MemUsedBytesN      uint64
+	MemBuffCacheBytesN uint64
+	// MemAvailBytesN is "avail Mem" in the swap line.
+	MemAvailBytesN uint64
+
+	SwapTotalBytesN uint64
+	SwapFreeBytesN  uint64
+	SwapUsedBytesN  uint64
+}
+
+// ParseSummary parses the summary area of 'top' command output.
+// If the output has multiple iterations, it returns the last one.
+func ParseSummary(s string) Summary {
+	var sm Summary
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "top -") {
+			sm = Summary{}
+		}
+		parseSummaryLine(&sm, line)
+	}
+	return sm
+}
+
+// parseSummaryLine parses the summary line into 'sm',
+// and returns false if the line is not in the summary area.
+// Malformed values are left zero.
+func parseSummaryLine(sm *Summary, line string) bool {
+	switch {
+	case strings.HasPrefix(line, "top -"):
+		parseSummaryUptime(sm, strings.TrimSpace(strings.TrimPrefix(line, "top -")))
+
+	case strings.HasPrefix(line, "Tasks:"), strings.HasPrefix(line, "Threads:"):
+		for k, v := range summaryPairs(line) {
+			n, _ := strconv.ParseInt(v, 10, 64)
+			switch k {
+			case "total":
+				sm.TasksTotal = n
+			case "running":
+				sm.TasksRunning = n
+			case "sleeping":
+				sm.TasksSleeping = n
+			case "stopped":
+				sm.TasksStopped = n
+			case "zombie":
+				sm.TasksZombie = n
+			}
+		}
+
+	case strings.HasPrefix(line, "%Cpu(s):"), strings.HasPrefix(line, "Cpu(s):"):
+		for k, v := range summaryPairs(line) {
+			f, _ := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64)
+			switch strings.TrimPrefix(k, "%") {
+			case "us":
+				sm.CPUUser = f
+			case "sy":
+				sm.CPUSystem = f
+			case "ni":
+				sm.CPUNice = f
+			case "id":
+				sm.CPUIdle = f
+			case "wa":
+				sm.CPUIowait = f
+			case "hi":
+				sm.CPUHardIRQ = f
+			case "si":
+				sm.CPUSoftIRQ = f
+			case "st":
+				sm.CPUSteal = f
+			}
+		}
+
+	case isSummaryMemLine(line, "Mem"):
+		unit := summaryUnit(line)
+		for k, v := range summaryPairs(line) {
+			n := summaryBytes(v, unit)
+			switch k {
+			case "total":
+				sm.MemTotalBytesN = n
+			case "free":
+				sm.MemFreeBytesN = n
+			case "used":
+				sm.MemUsedBytesN = n
+			case "buff/cache", "buffers":
+				sm.MemBuffCacheBytesN = n
+			}
+		}
+
+	case isSummaryMemLine(line, "Swap"):
+		unit := summaryUnit(line)
+		for k, v := range summaryPairs(line) {
+			n := summaryBytes(v, unit)
+			switch k {
+			case "total":
+				sm.SwapTotalBytesN = n
+			case "free":
+				sm.SwapFreeBytesN = n
+			case "used":
+				sm.SwapUsedBytesN = n
+			case "avail Mem":
+				sm.MemAvailBytesN = n
+			case "cached":
+				sm.MemBuffCacheBytesN = n
+			}
+		}
+
+	default:
+		return false
+	}
+	return true
+}
+
+// parseSummaryUptime parses
+// "15:04:05 up 3 days,  2:01,  1 user,  load average: 0.00, 0.01, 0.05".
+func parseSummaryUptime(sm *Summary, s string) {
+	if idx := strings.Index(s, "load average:"); idx >= 0 {
+		fs := strings.Split(s[idx+len("load average:"):], ",")
+		lds := []*float64{&sm.LoadAvg1Minute, &sm.LoadAvg5Minute, &sm.LoadAvg15Minute}
+		for i := 0; i < len(fs) && i < len(lds); i++ {
+			*lds[i], _ = strconv.ParseFloat(strings.TrimSpace(fs[i]), 64)
+		}
+		s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s[:idx]), ","))
+	}
+
+	fs := strings.Fields(s)
+	if len(fs) > 0 {
+		sm.Time = fs[0]
+		s = strings.TrimSpace(strings.TrimPrefix(s, fs[0]))
+	}
+	s = strings.TrimSpace(strings.TrimPrefix(s, "up"))
+
+	// "3 days,  2:01,  1 user"
+	parts := strings.Split(s, ",")
+	if last := strings.Fields(parts[len(parts)-1]); len(last) == 2 && strings.HasPrefix(last[1], "user") {
+		sm.Users, _ = strconv.ParseInt(last[0], 10, 64)
+		parts = parts[:len(parts)-1]
+	}
+	sm.Uptime = strings.TrimSpace(strings.Join(parts, ","))
+}
+
+// isSummaryMemLine returns true if the line is
+// "KiB Mem :", "MiB Mem :", "Mem:" and so on.
+func isSummaryMemLine(line, name string) bool {
+	idx := strings.Index(line, ":")
+	if idx < 0 {
+		return false
+	}
+	fs := strings.Fields(line[:idx])
+	switch len(fs) {
+	case 1:
+		return fs[0] == name
+	case 2:
+		return strings.HasSuffix(fs[0], "iB") && fs[1] == name
+	}
+	return false
+}
+
+// summaryUnit returns the bytes of the unit in the memory line
+// (e.g. 1024 for "KiB Mem :").
+func summaryUnit(line string) uint64 {
+	unit := uint64(1024)
+	switch {
+	case strings.HasPrefix(line, "MiB"):
+		unit <<= 10
+	case strings.HasPrefix(line, "GiB"):
+		unit <<= 20
+	case strings.HasPrefix(line, "TiB"):
+		unit <<= 30
+	case strings.HasPrefix(line, "PiB"):
+		unit <<= 40
+	case strings.HasPrefix(line, "EiB"):
+		unit <<= 50
+	}
+	return unit
+}
+
+// summaryBytes converts the value in the unit to bytes.
+// Older 'top' prints "8167848k" in a "Mem:" line.
+func summaryBytes(v string, unit uint64) uint64 {
+	if strings.HasSuffix(v, "k") {
+		v, unit = v[:len(v)-1], 1024
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0
+	}
+	return uint64(f * float64(unit))
+}
+
+// summaryPairs parses "Tasks: 123 total,   1 running" into
+// "total": "123", "running": "1". The swap line ends with
+// "0 used.  6788624 avail Mem".
+func summaryPairs(line string) map[string]string {
+	idx := strings.Index(line, ":")
+	if idx < 0 {
+		return nil
+	}
+	line = strings.Replace(line[idx+1:], ". ", ", ", -1)
+
+	pairs := make(map[string]string)
+	for _, p := range strings.Split(line, ",") {
+		fs := strings.Fields(p)
+		if len(fs) == 1 {
+			// older 'top' prints "1.0%us"
+			if idx := strings.Index(fs[0], "%"); idx > 0 {
+				fs = []string{fs[0][:idx], fs[0][idx+1:]}
+			}
+		}
+		if len(fs) < 2 {
+			continue
+		}
+		pairs[strings.Join(fs[1:], " ")] = fs[0]
+	}
+	return pairs
+}
@@ -0,0 +1,73 @@
+package top
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSummary(t *testing.T) {
+	mib := func(f float64) uint64 { return uint64(f * 1024 * 1024) }
+	tests := []struct {
+		txt string
+		exp Summary
+	}{
+		{
+			txt: `top - 15:04:05 up 3 days,  2:01,  1 user,  load average: 0.00, 0.01, 0.05
+Tasks: 123 total,   1 running, 122 sleeping,   0 stopped,   0 zombie
+%Cpu(s):  1.0 us,  0.5 sy,  0.0 ni, 98.4 id,  0.1 wa,  0.0 hi,  0.0 si,  0.0 st
+KiB Mem :  8167848 total,  5049640 free,  1059856 used,  2058352 buff/cache
+KiB Swap:  2097148 total,  2097148 free,        0 used.  6788624 avail Mem
+
+  PID USER      PR  NI    VIRT    RES    SHR S  %CPU %MEM     TIME+ COMMAND
+    1 root      20   0  225796   9452   6744 S   0.0  0.1   0:01.89 systemd
+`,
+			exp: Summary{
+				Time: "15:04:05", Uptime: "3 days,  2:01", Users: 1,
+				LoadAvg1Minute: 0.00, LoadAvg5Minute: 0.01, LoadAvg15Minute: 0.05,
+				TasksTotal: 123, TasksRunning: 1, TasksSleeping: 122,
+				CPUUser: 1.0, CPUSystem: 0.5, CPUIdle: 98.4, CPUIowait: 0.1,
+				MemTotalBytesN: 8167848 * 1024, MemFreeBytesN: 5049640 * 1024, MemUsedBytesN: 1059856 * 1024, MemBuffCacheBytesN: 2058352 * 1024,
+				MemAvailBytesN:  6788624 * 1024,
+				SwapTotalBytesN: 2097148 * 1024, SwapFreeBytesN: 2097148 * 1024,
+			},
+		},
+		{
+			txt: `top - 09:00:00 up 10 min,  0 users,  load average: 1.50, 0.75, 0.25
+Threads: 300 total,   2 running, 297 sleeping,   0 stopped,   1 zombie
+%Cpu(s):100.0 us,  0.0 sy,  0.0 ni,  0.0 id,  0.0 wa,  0.0 hi,  0.0 si,  0.0 st
+MiB Mem :   7976.5 total,   4931.3 free,   1035.0 used,   2010.2 buff/cache
+MiB Swap:      0.0 total,      0.0 free,      0.0 used.   6629.5 avail Mem
+`,
+			exp: Summary{
+				Time: "09:00:00", Uptime: "10 min",
+				LoadAvg1Minute: 1.5, LoadAvg5Minute: 0.75, LoadAvg15Minute: 0.25,
+				TasksTotal: 300, TasksRunning: 2, TasksSleeping: 297, TasksZombie: 1,
+				CPUUser:        100.0,
+				MemTotalBytesN: mib(7976.5), MemFreeBytesN: mib(4931.3), MemUsedBytesN: mib(1035.0), MemBuffCacheBytesN: mib(2010.2),
+				MemAvailBytesN: mib(6629.5),
+			},
+		},
+		{
+			txt: `top - 12:00:00 up  2:01,  2 users,  load average: 0.10, 0.20, 0.30
+Tasks:  90 total,   1 running,  89 sleeping,   0 stopped,   0 zombie
+Cpu(s):  2.0%us,  1.0%sy,  0.0%ni, 97.0%id,  0.0%wa,  0.0%hi,  0.0%si,  0.0%st
+Mem:   8167848k total,  5049640k used,  3118208k free,   102400k buffers
+Swap:  2097148k total,        0k used,  2097148k free,  1048576k cached
+`,
+			exp: Summary{
+				Time: "12:00:00", Uptime: "2:01", Users: 2,
+				LoadAvg1Minute: 0.1, LoadAvg5Minute: 0.2, LoadAvg15Minute: 0.3,
+				TasksTotal: 90, TasksRunning: 1, TasksSleeping: 89,
+				CPUUser: 2.0, CPUSystem: 1.0, CPUIdle: 97.0,
+				MemTotalBytesN: 8167848 * 1024, MemUsedBytesN: 5049640 * 1024, MemFreeBytesN: 3118208 * 1024, MemBuffCacheBytesN: 1048576 * 1024,
+				SwapTotalBytesN: 2097148 * 1024, SwapFreeBytesN: 2097148 * 1024,
+			},
+		},
+	}
+	for i, tt := range tests {
+		sm := ParseSummary(tt.txt)
+		if !reflect.DeepEqual(sm, tt.exp) {
+			t.Fatalf("#%d: expected\n%+v\ngot\n%+v", i, tt.exp, sm)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package top
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// GetOwnerPID returns the process ID (thread group ID) of the thread,
+// from 'Tgid' in '/proc/$TID/status'.
+func GetOwnerPID(tid int64) (int64, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/status", tid))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		txt := scanner.Text()
+		if !strings.HasPrefix(txt, "Tgid:") {
+			continue
+		}
+		return strconv.ParseInt(strings.TrimSpace(txt[len("Tgid:"):]), 10, 64)
+	}
+	if err = scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("'Tgid' not found for %d", tid)
+}
+
+// GroupByOwner groups thread rows (in 'Config.ThreadMode') by the
+// owning process ID. Rows without 'OwnerPID' are grouped by their PID.
+func GroupByOwner(rows map[int64]Row) map[int64][]Row {
+	gm := make(map[int64][]Row)
+	for _, row := range rows {
+		owner := row.OwnerPID
+		if owner == 0 {
+			owner = row.PID
+		}
+		gm[owner] = append(gm[owner], row)
+	}
+	return gm
+}
@@ -0,0 +1,56 @@
+package top
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestGetOwnerPID(t *testing.T) {
+	pid := int64(os.Getpid())
+	owner, err := GetOwnerPID(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner != pid {
+		t.Fatalf("expected owner %d, got %d", pid, owner)
+	}
+}
+
+func TestGroupByOwner(t *testing.T) {
+	gm := GroupByOwner(map[int64]Row{
+		10: {PID: 10, OwnerPID: 10},
+		11: {PID: 11, OwnerPID: 10},
+		20: {PID: 20},
+	})
+	if len(gm[10]) != 2 || len(gm[20]) != 1 {
+		t.Fatalf("unexpected groups %+v", gm)
+	}
+}
+
+func TestTopStreamThreadMode(t *testing.T) {
+	pid := int64(os.Getpid())
+	cfg := &Config{
+		Exec:           DefaultExecPath,
+		IntervalSecond: 1,
+		PID:            pid,
+		ThreadMode:     true,
+	}
+	str, err := cfg.StartStream()
+	if err != nil {
+		t.Skip(err)
+	}
+	time.Sleep(2 * time.Second)
+	if err = str.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	rm := str.Latest()
+	for tid, row := range rm {
+		if row.OwnerPID != 0 && row.OwnerPID != pid {
+			t.Fatalf("thread %d expected owner %d, got %d", tid, pid, row.OwnerPID)
+		}
+	}
+	fmt.Println("threads:", len(rm), "processes:", len(GroupByOwner(rm)))
+}
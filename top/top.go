@@ -4,9 +4,13 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
+	"strings"
+	"time"
 
 	"github.com/83567599/linux-inspect/pkg/fileutil"
+	"github.com/gyuho/linux-inspect/pkg/timeutil"
 )
 
 // DefaultExecPath is the default 'top' command path.
@@ -18,15 +22,11 @@ type Config struct {
 	// Defaults to '/usr/bin/top'.
 	Exec string
 
-	// MAKE THIS TRUE BY DEFAULT
-	// OTHERWISE PARSER HAS TO DEAL WITH HIGHLIGHTED TEXTS
-	//
-	// BatchMode is true to start 'top' in batch mode, which could be useful
-	// for sending output from 'top' to other programs or to a file.
-	// In this mode, 'top' will not accept input and runs until the interations
-	// limit ('-n' flag) or until killed.
-	// It's '-b' flag.
-	// BatchMode bool
+	// BatchMode reads the stream from a plain pipe instead of a pty,
+	// for environments without '/dev/ptmx' (e.g. locked-down
+	// containers). 'top' always runs in batch mode ('-b' flag),
+	// since the parser cannot deal with highlighted texts.
+	BatchMode bool
 
 	// Limit limits the iteration of 'top' commands to run before exit.
 	// If 1, 'top' prints out the current processes and exits.
@@ -38,11 +38,84 @@ type Config struct {
 	// It's '-d' flag.
 	IntervalSecond float64
 
+	// Iterations is the number of iterations before 'top' exits,
+	// overriding 'Limit' if non-zero. 0 runs until killed (streaming),
+	// and 1 is for one-shot capture.
+	// It's '-n' flag.
+	Iterations int
+
+	// Delay is the delay between updates, overriding
+	// 'IntervalSecond' if non-zero.
+	// It's '-d' flag.
+	Delay time.Duration
+
 	// PID specifies the PID to monitor.
 	// It's '-p' flag.
 	PID int64
 
-	// Writer stores 'top' command outputs.
+	// PIDs specifies more PIDs to monitor, along with 'PID'.
+	// 'top' accepts at most 20 PIDs.
+	// It's '-p' flag.
+	PIDs []int64
+
+	// SortBy is the field to sort by, %CPU by default.
+	// 'Stream.TopN' returns the rows highest by it.
+	// It's '-o' flag.
+	SortBy SortField
+
+	// ProgramMatchFunc, if not nil, drops the rows whose
+	// 'Row.COMMAND' does not match, before they reach
+	// 'Stream.Latest' and the subscribers.
+	ProgramMatchFunc func(string) bool
+
+	// ThreadMode shows individual threads instead of processes.
+	// Then, 'Row.PID' is the thread ID (TID), 'Row.COMMAND' is the
+	// thread name, and 'Row.OwnerPID' is the owning process ID.
+	// 'Stream.Latest' is keyed by TID; use 'GroupByOwner' to group
+	// threads back to processes.
+	// It's '-H' flag.
+	ThreadMode bool
+
+	// HistorySize is the number of the last rows to keep per PID
+	// in the stream, with their timestamps (see 'Stream.History').
+	// 0 disables the history.
+	HistorySize int
+
+	// EvictAfter, if greater than 0, drops the rows and the history
+	// of a PID from the stream once it is missing from this many
+	// iterations (e.g. the process exited), so that 'Stream.Latest'
+	// does not grow forever. 0 keeps them until 'Stream.Compact'.
+	EvictAfter int
+
+	// AutoRestartBackoff, if greater than 0, restarts 'top' this long
+	// after it dies (e.g. OOM killed), and retries every this long
+	// until it starts, keeping the stream alive. Each attempt is sent
+	// to 'Stream.RestartChan'. 'top' exiting with status 0 (e.g. out
+	// of 'Iterations') still ends the stream.
+	AutoRestartBackoff time.Duration
+
+	// Fields is the column headers of the rows in order, for 'top'
+	// with a custom layout in 'toprc' (e.g. "PID USER %CPU COMMAND").
+	// The columns not in 'Headers' (e.g. "SWAP") are ignored, and
+	// "PID" is required. Defaults to 'Headers'.
+	Fields []string
+
+	// Flavor is the implementation of 'top', detected
+	// from 'Exec' by default (see 'FlavorBusybox').
+	Flavor Flavor
+
+	// Native samples '/proc' with 'Sampler' instead of running
+	// 'top', for systems without procps. 'Exec' and 'Writer'
+	// are ignored.
+	Native bool
+
+	// Clock is used for the history and restart timestamps, the
+	// native sampling intervals and the restart backoff.
+	// Defaults to 'timeutil.RealClock'.
+	Clock timeutil.Clock
+
+	// Writer stores 'top' command outputs. In a stream,
+	// the output is copied to Writer as it is read.
 	Writer io.Writer
 
 	cmd *exec.Cmd
@@ -50,6 +123,10 @@ type Config struct {
 
 // Flags returns the 'top' command flags.
 func (cfg *Config) Flags() (fs []string) {
+	if cfg.flavor() == FlavorBusybox {
+		return cfg.busyboxFlags()
+	}
+
 	// start 'top' in batch mode, which could be useful
 	// for sending output from 'top' to other programs or to a file.
 	// In this mode, 'top' will not accept input and runs until the interations
@@ -59,16 +136,36 @@ func (cfg *Config) Flags() (fs []string) {
 	// OTHERWISE PARSER HAS TO DEAL WITH HIGHLIGHTED TEXTS
 	fs = append(fs, "-b")
 
-	if cfg.Limit > 0 { // if 1, command just exists after one output
-		fs = append(fs, "-n", fmt.Sprintf("%d", cfg.Limit))
+	iterations := cfg.Limit
+	if cfg.Iterations > 0 {
+		iterations = cfg.Iterations
+	}
+	if iterations > 0 { // if 1, command just exists after one output
+		fs = append(fs, "-n", fmt.Sprintf("%d", iterations))
+	}
+
+	interval := cfg.IntervalSecond
+	if cfg.Delay > 0 {
+		interval = cfg.Delay.Seconds()
+	}
+	if interval > 0 {
+		fs = append(fs, "-d", fmt.Sprintf("%.2f", interval))
 	}
 
-	if cfg.IntervalSecond > 0 {
-		fs = append(fs, "-d", fmt.Sprintf("%.2f", cfg.IntervalSecond))
+	if pids := cfg.pids(); len(pids) > 0 {
+		ss := make([]string, len(pids))
+		for i, pid := range pids {
+			ss[i] = fmt.Sprintf("%d", pid)
+		}
+		fs = append(fs, "-p", strings.Join(ss, ","))
 	}
 
-	if cfg.PID > 0 {
-		fs = append(fs, "-p", fmt.Sprintf("%d", cfg.PID))
+	if cfg.ThreadMode {
+		fs = append(fs, "-H")
+	}
+
+	if cfg.SortBy != "" {
+		fs = append(fs, "-o", string(cfg.SortBy))
 	}
 
 	return
@@ -82,9 +179,30 @@ func (cfg *Config) createCmd() error {
 	if !fileutil.Exist(cfg.Exec) {
 		return fmt.Errorf("%q does not exist", cfg.Exec)
 	}
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	switch cfg.flavor() {
+	case FlavorProcps:
+		if pids := cfg.pids(); len(pids) > maxPIDs {
+			return fmt.Errorf("'top' accepts at most %d PIDs (got %d)", maxPIDs, len(pids))
+		}
+	case FlavorBusybox:
+		if cfg.ThreadMode {
+			return fmt.Errorf("busybox 'top' has no thread mode")
+		}
+		if len(cfg.Fields) > 0 {
+			return fmt.Errorf("busybox 'top' has no custom fields")
+		}
+	default:
+		return fmt.Errorf("unknown flavor %q", cfg.Flavor)
+	}
 	flags := cfg.Flags()
 
 	c := exec.Command(cfg.Exec, flags...)
+	// 'C' locale for "1.5" rather than "1,5", and no terminal
+	// escape sequences; the last value wins in 'exec.Cmd.Env'
+	c.Env = append(os.Environ(), "LC_ALL=C", "TERM=dumb")
 	c.Stdout = cfg.Writer
 	c.Stderr = cfg.Writer
 
@@ -92,29 +210,171 @@ func (cfg *Config) createCmd() error {
 	return nil
 }
 
+// maxPIDs is the maximum number of PIDs 'top -p' accepts.
+const maxPIDs = 20
+
+// pids returns 'PID' and 'PIDs'.
+func (cfg *Config) pids() []int64 {
+	return mergePIDs(cfg.PID, cfg.PIDs)
+}
+
+func mergePIDs(pid int64, more []int64) (pids []int64) {
+	if pid > 0 {
+		pids = append(pids, pid)
+	}
+	for _, p := range more {
+		if p > 0 && p != pid {
+			pids = append(pids, p)
+		}
+	}
+	return pids
+}
+
+// delay returns the delay between updates, 1 second by default.
+func (cfg *Config) delay() time.Duration {
+	if cfg.Delay > 0 {
+		return cfg.Delay
+	}
+	if cfg.IntervalSecond > 0 {
+		return time.Duration(cfg.IntervalSecond * float64(time.Second))
+	}
+	return time.Second
+}
+
+func (cfg *Config) clock() timeutil.Clock {
+	if cfg.Clock != nil {
+		return cfg.Clock
+	}
+	return timeutil.RealClock
+}
+
+// layout returns the layout of 'Fields', 'Headers' by default.
+// Invalid 'Fields' are rejected by 'validate'.
+func (cfg *Config) layout() *layout {
+	if len(cfg.Fields) == 0 {
+		return defaultLayout
+	}
+	lt, err := newLayout(cfg.Fields)
+	if err != nil {
+		return defaultLayout
+	}
+	return lt
+}
+
+// iterations returns the number of iterations, 0 to run until stopped.
+func (cfg *Config) iterations() int {
+	if cfg.Iterations > 0 {
+		return cfg.Iterations
+	}
+	return cfg.Limit
+}
+
+func (cfg *Config) validate() error {
+	if cfg.Iterations < 0 || cfg.Limit < 0 {
+		return fmt.Errorf("invalid iterations %d (limit %d)", cfg.Iterations, cfg.Limit)
+	}
+	if !cfg.SortBy.valid() {
+		return fmt.Errorf("unknown sort field %q", cfg.SortBy)
+	}
+	if cfg.AutoRestartBackoff < 0 {
+		return fmt.Errorf("invalid auto restart backoff %v", cfg.AutoRestartBackoff)
+	}
+	if cfg.HistorySize < 0 {
+		return fmt.Errorf("invalid history size %d", cfg.HistorySize)
+	}
+	if cfg.EvictAfter < 0 {
+		return fmt.Errorf("invalid evict after %d", cfg.EvictAfter)
+	}
+	if len(cfg.Fields) > 0 {
+		if _, err := newLayout(cfg.Fields); err != nil {
+			return err
+		}
+	}
+	if cfg.Delay < 0 || cfg.IntervalSecond < 0 {
+		return fmt.Errorf("invalid delay %v (interval %.2f seconds)", cfg.Delay, cfg.IntervalSecond)
+	}
+	return nil
+}
+
 // Get returns all entries in 'top' command.
 // If pid<1, it reads all processes in 'top' command.
 // This is one-time command.
 func Get(topPath string, pid int64) ([]Row, error) {
-	buf := new(bytes.Buffer)
 	cfg := &Config{
-		Exec:           topPath,
-		Limit:          1,
-		IntervalSecond: 1,
-		PID:            pid,
-		Writer:         buf,
-		cmd:            nil,
+		Exec:  topPath,
+		Delay: time.Second,
+		PID:   pid,
 	}
-	if cfg.Exec == "" {
-		cfg.Exec = topPath
+	return cfg.Snapshot()
+}
+
+// Snapshot runs 'top' once ('-n 1'), or the native sampler if
+// 'Native', and returns the rows, without managing a stream.
+// 'PIDs', 'ThreadMode', 'SortBy' and 'ProgramMatchFunc' apply as in
+// the stream. The native sampler samples twice, 'Delay' apart, for
+// %CPU; 'Iterations' is ignored.
+func (cfg *Config) Snapshot() ([]Row, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("Config is nil")
 	}
-	if err := cfg.createCmd(); err != nil {
+	if cfg.Native {
+		if err := cfg.validate(); err != nil {
+			return nil, err
+		}
+		s := NewSampler(cfg.PID, cfg.ThreadMode)
+		s.Clock = cfg.clock()
+		s.PIDs = cfg.PIDs
+		s.ProgramMatchFunc = cfg.ProgramMatchFunc
+		s.SortBy = cfg.SortBy
+		if _, err := s.Sample(); err != nil {
+			return nil, err
+		}
+		<-cfg.clock().After(cfg.delay())
+		return s.Sample()
+	}
+
+	c := *cfg
+	c.Limit, c.Iterations = 0, 1
+	buf := new(bytes.Buffer)
+	c.Writer = buf
+	if cfg.Writer != nil {
+		c.Writer = io.MultiWriter(buf, cfg.Writer)
+	}
+	if err := c.createCmd(); err != nil {
 		return nil, err
 	}
 
 	// run starts the 'top' command and waits for it to complete.
-	if err := cfg.cmd.Run(); err != nil {
+	if err := c.cmd.Run(); err != nil {
 		return nil, err
 	}
-	return Parse(buf.String())
+	var rows []Row
+	var err error
+	if c.flavor() == FlavorBusybox {
+		rows, err = ParseBusybox(buf.String())
+	} else {
+		rows, err = ParseFields(buf.String(), cfg.layout().fields)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pids := c.pidFilter()
+	filtered := rows[:0]
+	for _, r := range rows {
+		if cfg.ProgramMatchFunc != nil && !cfg.ProgramMatchFunc(r.COMMAND) {
+			continue
+		}
+		if _, ok := pids[r.PID]; pids != nil && !ok {
+			continue
+		}
+		if cfg.ThreadMode {
+			// thread may have exited; leave it unresolved
+			r.OwnerPID, _ = GetOwnerPID(r.PID)
+		}
+		filtered = append(filtered, r)
+	}
+	// 'Parse' does not keep the order of 'top'
+	SortRows(filtered, cfg.SortBy)
+	return filtered, nil
 }
@@ -2,6 +2,8 @@ package top
 
 import (
 	"fmt"
+	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -17,3 +19,71 @@ func TestGet(t *testing.T) {
 	}
 	fmt.Printf("found %d entrines in %v", len(rows), time.Since(now))
 }
+
+func TestConfigFlags(t *testing.T) {
+	tests := []struct {
+		cfg   Config
+		flags []string
+	}{
+		{Config{}, []string{"-b"}},
+		{Config{Limit: 2, IntervalSecond: 0.5}, []string{"-b", "-n", "2", "-d", "0.50"}},
+		{Config{Limit: 2, Iterations: 1, IntervalSecond: 0.5, Delay: 3 * time.Second}, []string{"-b", "-n", "1", "-d", "3.00"}},
+		{Config{Delay: 250 * time.Millisecond, PID: 7}, []string{"-b", "-d", "0.25", "-p", "7"}},
+		{Config{PID: 7, PIDs: []int64{7, 8, 9}}, []string{"-b", "-p", "7,8,9"}},
+		{Config{PIDs: []int64{8, 9}}, []string{"-b", "-p", "8,9"}},
+		{Config{ThreadMode: true, SortBy: SortByMEM}, []string{"-b", "-H", "-o", "%MEM"}},
+	}
+	for i, tt := range tests {
+		fs := tt.cfg.Flags()
+		if fmt.Sprint(fs) != fmt.Sprint(tt.flags) {
+			t.Fatalf("#%d: expected %v, got %v", i, tt.flags, fs)
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	for i, cfg := range []Config{
+		{Iterations: -1},
+		{Limit: -1},
+		{Delay: -time.Second},
+		{IntervalSecond: -1},
+		{SortBy: "RES"},
+	} {
+		if err := cfg.validate(); err == nil {
+			t.Fatalf("#%d: expected error for %+v", i, cfg)
+		}
+	}
+	cfg := Config{Iterations: 1, Delay: time.Second}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigSnapshot(t *testing.T) {
+	pid := int64(os.Getpid())
+	for _, native := range []bool{false, true} {
+		cfg := &Config{
+			Exec:   DefaultExecPath,
+			Delay:  100 * time.Millisecond,
+			PIDs:   []int64{1, pid},
+			SortBy: SortByPID,
+			Native: native,
+		}
+		rows, err := cfg.Snapshot()
+		if err != nil {
+			t.Skip(err)
+		}
+		if len(rows) != 2 || rows[0].PID != pid || rows[1].PID != 1 {
+			t.Fatalf("native %v: unexpected rows %+v", native, rows)
+		}
+
+		cfg.ProgramMatchFunc = func(command string) bool { return strings.HasPrefix(command, "top.test") }
+		rows, err = cfg.Snapshot()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 1 || rows[0].PID != pid {
+			t.Fatalf("native %v: unexpected rows %+v", native, rows)
+		}
+	}
+}